@@ -17,6 +17,7 @@ type asteroidsGame struct {
 	events event.Manager
 
 	debugLayer sdlkit.Layer
+	background *sdlkit.CachedLayer
 
 	starField *starField
 	player    *player
@@ -25,11 +26,12 @@ type asteroidsGame struct {
 func newAsteroids(stage *sdlkit.Stage) (sdlkit.Scene, error) {
 	game := &asteroidsGame{
 		stage:     stage,
-		starField: newStarField(40),
+		starField: newStarField(40, stage.Size()),
 		player:    newPlayer(stage.FWidth()/2, stage.FHeight()/2),
 	}
 
-	game.events.RegisterHandler(stage)
+	game.background = sdlkit.NewCachedLayer(stage.Canvas(), stage.Width(), stage.Height(), game.starField)
+	game.events.RegisterHandler(stage, game, game.background)
 	game.debugLayer.Append(display.NewFpsDisplay(stage.Time(), 10, 10))
 
 	return game, nil
@@ -37,8 +39,11 @@ func newAsteroids(stage *sdlkit.Stage) (sdlkit.Scene, error) {
 
 func (game *asteroidsGame) SceneName() string { return "asteroids" }
 
-func (game *asteroidsGame) Activate() error {
-	return game.starField.Render(game.stage.Renderer(), game.stage.Size())
+func (game *asteroidsGame) HandleWindowSizeChangedEvent(_ *sdl.WindowEvent) error {
+	size := game.stage.Size()
+	game.starField.Generate(size)
+	game.background.MarkDirty()
+	return game.background.Resize(size.W, size.H)
 }
 
 func (game *asteroidsGame) Process() error {
@@ -47,9 +52,13 @@ func (game *asteroidsGame) Process() error {
 
 func (game *asteroidsGame) Update(_ float64) {}
 
+func (game *asteroidsGame) Destroy() error {
+	return game.background.Destroy()
+}
+
 func (game *asteroidsGame) Render(r *sdl.Renderer) error {
 	return sdlkit.Render(r,
-		game.starField,
+		game.background,
 		game.player,
 		game.debugLayer,
 	)
@@ -6,52 +6,54 @@ import (
 	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
 )
 
-type starField struct {
-	texture *sdl.Texture
+type star struct {
+	x, y, size int32
+	alpha      uint8
+}
 
+type starField struct {
 	density int32
-	size    sdl.Rect
+	stars   []star
 }
 
-func newStarField(density int32) *starField {
-	return &starField{
-		density: density,
-	}
+func newStarField(density int32, size sdl.Rect) *starField {
+	sf := &starField{density: density}
+	sf.Generate(size)
+	return sf
 }
 
-func (sf *starField) Render2(ren *sdl.Renderer, size sdl.Rect) error {
-	canvas := sdlkit.NewCanvas(ren)
-	tx, err := canvas.CreateTexture(sdl.PIXELFORMAT_RGBA8888, 0, size.W, size.H)
-	if err != nil {
-		return err
-	}
-
-	canvas.BlendMode(sdl.BLENDMODE_BLEND)
+// Generate randomly places stars within the provided size.
+func (sf *starField) Generate(size sdl.Rect) {
 	rng := sdlkit.RNG()
+	sf.stars = sf.stars[:0]
 
 	var x, y int32 = 0, 0
 	for y = 0; y < size.H; y += sf.density {
 		for x = 0; x < size.W; {
 			x += rng.Int31n(sf.density)
-			canvas.BeginFillRGBA(255, 255, 255, uint8(55+rng.Intn(200)))
-			canvas.DrawPixel(x, y+rng.Int31n(sf.density*2)-sf.density, rng.Int31n(4))
+			sf.stars = append(sf.stars, star{
+				x:     x,
+				y:     y + rng.Int31n(sf.density*2) - sf.density,
+				size:  rng.Int31n(4),
+				alpha: uint8(55 + rng.Intn(200)),
+			})
 		}
 	}
+}
 
-	if err = canvas.Done(); err != nil {
-		return err
-	}
+func (sf *starField) Draw(canvas *sdlkit.Canvas) {
+	// the alpha of the stars is only applied when blending
+	mode := canvas.DrawBlendMode()
+	canvas.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
 
-	sf.texture = tx
-	sf.size.W = size.W
-	sf.size.H = size.H
-	return nil
+	for _, s := range sf.stars {
+		canvas.BeginFillRGBA(255, 255, 255, s.alpha)
+		canvas.DrawPixel(s.x, s.y, s.size)
+	}
+	canvas.EndFill()
+	canvas.SetDrawBlendMode(mode)
 }
 
 func (sf *starField) Render(ren *sdl.Renderer) error {
-	if sf.texture == nil {
-		return nil
-	}
-
-	return ren.Copy(sf.texture, nil, &sf.size)
+	return sdlkit.RenderDrawable(ren, sf)
 }
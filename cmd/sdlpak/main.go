@@ -0,0 +1,172 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command sdlpak builds an asset archive from a directory, which can be
+// loaded using package pak.
+//
+//	sdlpak [-o assets.pak] [-manifest manifest.yaml] [-base base.pak] dir
+//
+// When -manifest is set, the SHA-256 checksums of all files within dir are
+// added to the manifest within the archive, so they are verified when it is
+// used by an AssetsLoader. When -base is set, only files which are new or
+// differ from the base archive are added, which results in a patch archive
+// that is layered on top of the base archive.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/go-pogo/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/pak"
+)
+
+func main() {
+	out := flag.String("o", "assets.pak", "archive to create")
+	manifest := flag.String("manifest", "", "manifest within dir to add the checksums to")
+	base := flag.String("base", "", "base archive to create a patch for")
+	flag.Usage = func() {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "usage: sdlpak [flags] dir\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	log.SetFlags(0)
+	n, err := build(*out, flag.Arg(0), *manifest, *base)
+	if err != nil {
+		log.Fatalf("sdlpak: %+v", err)
+	}
+	log.Printf("sdlpak: added %d files to %s", n, *out)
+}
+
+func build(out, dir, manifest, base string) (int, error) {
+	src := os.DirFS(dir)
+
+	var baseFS fs.FS
+	if base != "" {
+		a, err := pak.Open(base)
+		if err != nil {
+			return 0, err
+		}
+		defer a.Close()
+		baseFS = a
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+
+	w := pak.NewWriter(f)
+	err = w.AddFS(src, func(name string) bool {
+		if name == manifest {
+			return true
+		}
+		return baseFS != nil && !changed(baseFS, src, name)
+	})
+	if err == nil && manifest != "" {
+		err = addManifest(w, src, manifest)
+	}
+
+	errors.Append(&err, w.Close())
+	errors.Append(&err, f.Close())
+	if err != nil {
+		_ = os.Remove(out)
+		return 0, err
+	}
+	return w.Len(), nil
+}
+
+// changed indicates if the file with name differs from the same file within
+// base.
+func changed(base, src fs.FS, name string) bool {
+	want, err := pak.Checksum(base, name)
+	if err != nil {
+		return true
+	}
+
+	sum, err := pak.Checksum(src, name)
+	return err != nil || sum != want
+}
+
+// addManifest adds the manifest to the archive, with the checksums of all
+// other files within src.
+func addManifest(w *pak.Writer, src fs.FS, manifest string) error {
+	data, err := fs.ReadFile(src, manifest)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	sums, err := pak.Checksums(src, func(name string) bool { return name == manifest })
+	if err != nil {
+		return err
+	}
+
+	data, err = setChecksums(data, sums)
+	if err != nil {
+		return errors.Wrapf(err, "unable to add checksums to `%s`", manifest)
+	}
+	return w.Add(manifest, data)
+}
+
+// setChecksums replaces the checksums within the YAML or JSON manifest data,
+// while keeping the rest of the document as is. The result is always YAML.
+func setChecksums(data []byte, sums map[string]string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{
+			Kind:    yaml.DocumentNode,
+			Content: []*yaml.Node{{Kind: yaml.MappingNode}},
+		}
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, errors.New("manifest is not a mapping")
+	}
+	// JSON documents are parsed in flow style, which is unreadable when it
+	// contains many checksums
+	root.Style = 0
+
+	names := make([]string, 0, len(sums))
+	for name := range sums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	value := &yaml.Node{Kind: yaml.MappingNode}
+	for _, name := range names {
+		value.Content = append(value.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: name},
+			&yaml.Node{Kind: yaml.ScalarNode, Value: sums[name]},
+		)
+	}
+
+	for i := 0; i < len(root.Content); i += 2 {
+		if root.Content[i].Value == "checksums" {
+			root.Content[i+1] = value
+			return yaml.Marshal(&doc)
+		}
+	}
+
+	root.Content = append(root.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: "checksums"},
+		value,
+	)
+	return yaml.Marshal(&doc)
+}
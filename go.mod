@@ -7,5 +7,5 @@ require (
 	github.com/go-pogo/errors v0.5.0
 	github.com/stretchr/testify v1.6.1
 	github.com/veandco/go-sdl2 v0.4.5
-	gopkg.in/yaml.v3 v3.0.0-20200615113413-eeeca48fe776 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200615113413-eeeca48fe776
 )
\ No newline at end of file
@@ -0,0 +1,308 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sdlkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/go-pogo/errors"
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom"
+)
+
+type AnimationDirection uint8
+
+const (
+	AnimateForward AnimationDirection = iota
+	AnimateReverse
+	AnimatePingPong
+	AnimatePingPongReverse
+)
+
+// AnimationTag describes an animation as a range of frames within a
+// SpriteSheet.
+type AnimationTag struct {
+	Name      string
+	From, To  int // indexes of the first and last frame
+	Direction AnimationDirection
+	// Repeat is the number of times the animation is played, 0 means forever.
+	Repeat int
+}
+
+// SpriteSlice is a named area of a SpriteSheet's frames, eg. a hitbox or
+// pivot point.
+type SpriteSlice struct {
+	Name string
+	Keys []SpriteSliceKey
+}
+
+// SpriteSliceKey describes a SpriteSlice starting from Frame, until the next
+// key.
+type SpriteSliceKey struct {
+	Frame  int
+	Bounds sdl.Rect
+	// Center is the center area of a nine-slice, relative to Bounds.
+	Center    sdl.Rect
+	HasCenter bool
+	// Pivot is the pivot point relative to Bounds.
+	Pivot    sdl.Point
+	HasPivot bool
+}
+
+// Insets returns the nine-slice Insets of the key when it has a Center.
+func (k SpriteSliceKey) Insets() (Insets, bool) {
+	if !k.HasCenter {
+		return Insets{}, false
+	}
+
+	return Insets{
+		Left:   k.Center.X,
+		Top:    k.Center.Y,
+		Right:  k.Bounds.W - k.Center.X - k.Center.W,
+		Bottom: k.Bounds.H - k.Center.Y - k.Center.H,
+	}, true
+}
+
+// SpriteSheet is a TextureAtlas with animation and slice definitions, eg. as
+// exported by Aseprite.
+type SpriteSheet struct {
+	Atlas *TextureAtlas
+	// Durations contains the duration of each frame in Atlas.
+	Durations []time.Duration
+	Tags      []AnimationTag
+	Slices    []SpriteSlice
+}
+
+// Destroy destroys the Atlas of the SpriteSheet.
+func (ss *SpriteSheet) Destroy() error {
+	unwatchAsset(ss)
+	return ss.Atlas.Destroy()
+}
+
+func (ss *SpriteSheet) Tag(name string) (AnimationTag, bool) {
+	for _, t := range ss.Tags {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return AnimationTag{}, false
+}
+
+// SliceKey returns the key of the SpriteSlice with name which applies to
+// frame.
+func (ss *SpriteSheet) SliceKey(name string, frame int) (SpriteSliceKey, bool) {
+	for _, s := range ss.Slices {
+		if s.Name != name {
+			continue
+		}
+
+		var res SpriteSliceKey
+		var found bool
+		for _, k := range s.Keys {
+			if k.Frame <= frame && (!found || k.Frame >= res.Frame) {
+				res, found = k, true
+			}
+		}
+		return res, found
+	}
+	return SpriteSliceKey{}, false
+}
+
+// Pivot returns the pivot point of the SpriteSlice with name for frame,
+// relative to the top left corner of the frame.
+func (ss *SpriteSheet) Pivot(name string, frame int) (sdl.Point, bool) {
+	k, ok := ss.SliceKey(name, frame)
+	if !ok || !k.HasPivot {
+		return sdl.Point{}, false
+	}
+
+	return sdl.Point{X: k.Bounds.X + k.Pivot.X, Y: k.Bounds.Y + k.Pivot.Y}, true
+}
+
+type asepriteRect struct {
+	X int32 `json:"x"`
+	Y int32 `json:"y"`
+	W int32 `json:"w"`
+	H int32 `json:"h"`
+}
+
+func (r asepriteRect) rect() sdl.Rect { return sdl.Rect{X: r.X, Y: r.Y, W: r.W, H: r.H} }
+
+// asepriteFrame is a frame of an Aseprite or TexturePacker JSON file, which
+// share the same format.
+type asepriteFrame struct {
+	Filename string       `json:"filename"`
+	Frame    asepriteRect `json:"frame"`
+	Duration int          `json:"duration"`
+
+	Rotated          bool         `json:"rotated"`
+	Trimmed          bool         `json:"trimmed"`
+	SpriteSourceSize asepriteRect `json:"spriteSourceSize"`
+	SourceSize       asepriteRect `json:"sourceSize"`
+	// Pivot is relative to SourceSize, with values between 0 and 1.
+	Pivot *struct {
+		X float64 `json:"x"`
+		Y float64 `json:"y"`
+	} `json:"pivot"`
+}
+
+// clip returns a TextureClip of the frame, without a texture. Frame contains
+// the unrotated size of a frame which is rotated clockwise within the
+// texture.
+func (f asepriteFrame) clip() TextureClip {
+	clip := TextureClip{Location: f.Frame.rect()}
+	if f.Rotated {
+		clip.Location.W, clip.Location.H = f.Frame.H, f.Frame.W
+		clip.Rotation = 90
+	}
+	if f.Trimmed {
+		clip.Trim = sdl.Rect{
+			X: f.SpriteSourceSize.X,
+			Y: f.SpriteSourceSize.Y,
+			W: f.SourceSize.W,
+			H: f.SourceSize.H,
+		}
+	}
+	if f.Pivot != nil {
+		w, h := clip.Size()
+		clip.Pivot = geom.Point{X: f.Pivot.X * w, Y: f.Pivot.Y * h}
+		clip.HasPivot = true
+	}
+	return clip
+}
+
+// asepriteFrames decodes the frames of both the hash and array variants,
+// while preserving their order.
+type asepriteFrames []asepriteFrame
+
+func (f *asepriteFrames) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) != 0 && data[0] == '[' {
+		return json.Unmarshal(data, (*[]asepriteFrame)(f))
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		var frame asepriteFrame
+		if err = dec.Decode(&frame); err != nil {
+			return err
+		}
+
+		frame.Filename, _ = tok.(string)
+		*f = append(*f, frame)
+	}
+	_, err := dec.Token()
+	return err
+}
+
+type asepriteFile struct {
+	Frames asepriteFrames `json:"frames"`
+	Meta   struct {
+		Image     string `json:"image"`
+		FrameTags []struct {
+			Name      string `json:"name"`
+			From      int    `json:"from"`
+			To        int    `json:"to"`
+			Direction string `json:"direction"`
+			Repeat    string `json:"repeat"`
+		} `json:"frameTags"`
+		Slices []struct {
+			Name string `json:"name"`
+			Keys []struct {
+				Frame  int           `json:"frame"`
+				Bounds asepriteRect  `json:"bounds"`
+				Center *asepriteRect `json:"center"`
+				Pivot  *struct {
+					X int32 `json:"x"`
+					Y int32 `json:"y"`
+				} `json:"pivot"`
+			} `json:"keys"`
+		} `json:"slices"`
+	} `json:"meta"`
+}
+
+func parseAsepriteJson(data []byte) (asepriteFile, error) {
+	var x asepriteFile
+	err := json.Unmarshal(data, &x)
+	return x, errors.Trace(err)
+}
+
+// newSpriteSheet adds all frames of the Aseprite file to the TextureAtlas, in
+// order and with their filename as name, and converts its tags and slices.
+func newSpriteSheet(x asepriteFile, atlas *TextureAtlas) (*SpriteSheet, error) {
+	ss := &SpriteSheet{
+		Atlas:     atlas,
+		Durations: make([]time.Duration, 0, len(x.Frames)),
+		Tags:      make([]AnimationTag, 0, len(x.Meta.FrameTags)),
+		Slices:    make([]SpriteSlice, 0, len(x.Meta.Slices)),
+	}
+
+	for _, f := range x.Frames {
+		atlas.AddClip(f.Filename, f.clip())
+		ss.Durations = append(ss.Durations, time.Duration(f.Duration)*time.Millisecond)
+	}
+
+	for _, t := range x.Meta.FrameTags {
+		if t.From < 0 || t.To >= len(x.Frames) || t.From > t.To {
+			return nil, errors.Newf("sdlkit: invalid frame range %d-%d of tag `%s`", t.From, t.To, t.Name)
+		}
+
+		tag := AnimationTag{Name: t.Name, From: t.From, To: t.To}
+		switch t.Direction {
+		case "", "forward":
+			tag.Direction = AnimateForward
+		case "reverse":
+			tag.Direction = AnimateReverse
+		case "pingpong":
+			tag.Direction = AnimatePingPong
+		case "pingpong_reverse":
+			tag.Direction = AnimatePingPongReverse
+		default:
+			return nil, errors.Newf("sdlkit: unknown direction `%s` of tag `%s`", t.Direction, t.Name)
+		}
+		if t.Repeat != "" {
+			n, err := strconv.Atoi(t.Repeat)
+			if err != nil {
+				return nil, errors.Newf("sdlkit: invalid repeat `%s` of tag `%s`", t.Repeat, t.Name)
+			}
+			tag.Repeat = n
+		}
+
+		ss.Tags = append(ss.Tags, tag)
+	}
+
+	for _, s := range x.Meta.Slices {
+		slice := SpriteSlice{
+			Name: s.Name,
+			Keys: make([]SpriteSliceKey, 0, len(s.Keys)),
+		}
+		for _, k := range s.Keys {
+			key := SpriteSliceKey{Frame: k.Frame, Bounds: k.Bounds.rect()}
+			if k.Center != nil {
+				key.Center, key.HasCenter = k.Center.rect(), true
+			}
+			if k.Pivot != nil {
+				key.Pivot, key.HasPivot = sdl.Point{X: k.Pivot.X, Y: k.Pivot.Y}, true
+			}
+			slice.Keys = append(slice.Keys, key)
+		}
+
+		ss.Slices = append(ss.Slices, slice)
+	}
+
+	return ss, nil
+}
@@ -0,0 +1,135 @@
+package sdlkit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom"
+)
+
+const asepriteHash = `{ "frames": {
+  "hero 2.aseprite": { "frame": { "x": 32, "y": 0, "w": 16, "h": 24 }, "rotated": false, "trimmed": false, "duration": 150 },
+  "hero 0.aseprite": { "frame": { "x": 0, "y": 0, "w": 16, "h": 24 }, "rotated": false, "trimmed": false, "duration": 100 },
+  "hero 1.aseprite": { "frame": { "x": 16, "y": 0, "w": 16, "h": 24 }, "rotated": false, "trimmed": false, "duration": 100 }
+ },
+ "meta": {
+  "app": "http://www.aseprite.org/",
+  "image": "hero.png",
+  "size": { "w": 48, "h": 24 },
+  "frameTags": [
+   { "name": "idle", "from": 0, "to": 0, "direction": "forward" },
+   { "name": "walk", "from": 1, "to": 2, "direction": "pingpong" },
+   { "name": "die", "from": 0, "to": 2, "direction": "reverse", "repeat": "1" }
+  ],
+  "slices": [
+   { "name": "feet", "color": "#0000ffff", "keys": [
+     { "frame": 0, "bounds": {"x": 4, "y": 20, "w": 8, "h": 4 }, "pivot": {"x": 4, "y": 4 } },
+     { "frame": 2, "bounds": {"x": 5, "y": 20, "w": 8, "h": 4 }, "pivot": {"x": 4, "y": 4 } }
+   ]},
+   { "name": "panel", "color": "#ff0000ff", "keys": [
+     { "frame": 0, "bounds": {"x": 0, "y": 0, "w": 16, "h": 24 }, "center": {"x": 2, "y": 3, "w": 10, "h": 16 } }
+   ]}
+  ]
+ }
+}`
+
+const asepriteArray = `{ "frames": [
+  { "filename": "hero 2.aseprite", "frame": { "x": 32, "y": 0, "w": 16, "h": 24 }, "duration": 150 },
+  { "filename": "hero 0.aseprite", "frame": { "x": 0, "y": 0, "w": 16, "h": 24 }, "duration": 100 },
+  { "filename": "hero 1.aseprite", "frame": { "x": 16, "y": 0, "w": 16, "h": 24 }, "duration": 100 }
+ ],
+ "meta": { "image": "hero.png" }
+}`
+
+func TestParseAsepriteJson_Frames(t *testing.T) {
+	for name, data := range map[string]string{"hash": asepriteHash, "array": asepriteArray} {
+		t.Run(name, func(t *testing.T) {
+			x, err := parseAsepriteJson([]byte(data))
+			assert.NoError(t, err)
+			assert.Equal(t, "hero.png", x.Meta.Image)
+
+			ss, err := newSpriteSheet(x, NewTextureAtlas(nil, nil))
+			assert.NoError(t, err)
+			assert.Equal(t, 3, ss.Atlas.Len())
+			assert.Equal(t, []time.Duration{
+				150 * time.Millisecond,
+				100 * time.Millisecond,
+				100 * time.Millisecond,
+			}, ss.Durations)
+
+			// frames keep the order of the file
+			clip, err := ss.Atlas.GetFromIndex(0)
+			assert.NoError(t, err)
+			assert.Equal(t, sdl.Rect{X: 32, W: 16, H: 24}, clip.Location)
+
+			clip, err = ss.Atlas.GetFromName("hero 1.aseprite")
+			assert.NoError(t, err)
+			assert.Equal(t, sdl.Rect{X: 16, W: 16, H: 24}, clip.Location)
+		})
+	}
+}
+
+const texturePackerHash = `{ "frames": {
+  "coin.png": { "frame": { "x": 2, "y": 2, "w": 12, "h": 14 }, "rotated": false, "trimmed": true,
+    "spriteSourceSize": { "x": 2, "y": 1, "w": 12, "h": 14 }, "sourceSize": { "w": 16, "h": 16 },
+    "pivot": { "x": 0.5, "y": 1 } },
+  "gem.png": { "frame": { "x": 16, "y": 2, "w": 10, "h": 20 }, "rotated": true, "trimmed": false,
+    "spriteSourceSize": { "x": 0, "y": 0, "w": 10, "h": 20 }, "sourceSize": { "w": 10, "h": 20 } }
+ },
+ "meta": { "app": "https://www.codeandweb.com/texturepacker", "image": "items.png" }
+}`
+
+func TestAsepriteFrame_clip(t *testing.T) {
+	x, err := parseAsepriteJson([]byte(texturePackerHash))
+	assert.NoError(t, err)
+	assert.Len(t, x.Frames, 2)
+
+	assert.Equal(t, TextureClip{
+		Location: sdl.Rect{X: 2, Y: 2, W: 12, H: 14},
+		Trim:     sdl.Rect{X: 2, Y: 1, W: 16, H: 16},
+		Pivot:    geom.Point{X: 8, Y: 16},
+		HasPivot: true,
+	}, x.Frames[0].clip())
+
+	assert.Equal(t, TextureClip{
+		Location: sdl.Rect{X: 16, Y: 2, W: 20, H: 10},
+		Rotation: 90,
+	}, x.Frames[1].clip())
+}
+
+func TestNewSpriteSheet(t *testing.T) {
+	x, err := parseAsepriteJson([]byte(asepriteHash))
+	assert.NoError(t, err)
+
+	ss, err := newSpriteSheet(x, NewTextureAtlas(nil, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, []AnimationTag{
+		{Name: "idle", From: 0, To: 0, Direction: AnimateForward},
+		{Name: "walk", From: 1, To: 2, Direction: AnimatePingPong},
+		{Name: "die", From: 0, To: 2, Direction: AnimateReverse, Repeat: 1},
+	}, ss.Tags)
+
+	pt, ok := ss.Pivot("feet", 1)
+	assert.True(t, ok)
+	assert.Equal(t, sdl.Point{X: 8, Y: 24}, pt)
+
+	pt, ok = ss.Pivot("feet", 2)
+	assert.True(t, ok)
+	assert.Equal(t, sdl.Point{X: 9, Y: 24}, pt)
+
+	_, ok = ss.Pivot("panel", 0)
+	assert.False(t, ok)
+
+	key, ok := ss.SliceKey("panel", 1)
+	assert.True(t, ok)
+	in, ok := key.Insets()
+	assert.True(t, ok)
+	assert.Equal(t, Insets{Left: 2, Top: 3, Right: 4, Bottom: 5}, in)
+
+	x.Meta.FrameTags[0].Direction = "sideways"
+	_, err = newSpriteSheet(x, NewTextureAtlas(nil, nil))
+	assert.Error(t, err)
+}
@@ -16,8 +16,13 @@ import (
 )
 
 type AssetsLoader struct {
-	fs  fs.ReadFileFS
-	ren *sdl.Renderer
+	fs    fs.ReadFileFS
+	ren   *sdl.Renderer
+	watch *assetsWatcher
+
+	manifest *AssetManifest
+	manager  *AssetManager
+	bundles  map[string]*AssetBundle
 
 	// Surfaces SurfacesMap
 	Textures TexturesMap
@@ -76,10 +81,19 @@ func (l *AssetsLoader) Texture(file string) (*sdl.Texture, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+
+	l.addTexture(file, tx)
+	return tx, nil
+}
+
+// addTexture adds the texture, which is loaded from file, to the Textures map
+// and watches it for changes.
+func (l *AssetsLoader) addTexture(file string, tx *sdl.Texture) {
 	if l.Textures != nil {
 		l.Textures[file] = tx
 	}
-	return tx, nil
+
+	l.watchTexture(file, tx)
 }
 
 func (l *AssetsLoader) TextureClip(file string) (tc TextureClip, err error) {
@@ -106,6 +120,23 @@ func (l *AssetsLoader) TextureAtlasXml(file string) (*TextureAtlas, error) {
 		return nil, errors.Trace(err)
 	}
 
+	image, locs, err := parseTextureAtlasXml(data)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := l.TextureAtlas(path.Join(path.Dir(file), image), locs)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	l.watchTextureAtlasXml(file, a)
+	return a, nil
+}
+
+// parseTextureAtlasXml returns the image path and the locations of the
+// SubTextures of a TextureAtlas XML file.
+func parseTextureAtlasXml(data []byte) (string, map[string]sdl.Rect, error) {
 	var x struct {
 		File string `xml:"imagePath,attr"`
 		Subs []struct {
@@ -117,17 +148,100 @@ func (l *AssetsLoader) TextureAtlasXml(file string) (*TextureAtlas, error) {
 		} `xml:"SubTexture"`
 	}
 
-	if err = xml.Unmarshal(data, &x); err != nil {
-		return nil, errors.Trace(err)
+	if err := xml.Unmarshal(data, &x); err != nil {
+		return "", nil, errors.Trace(err)
 	}
 
 	locs := make(map[string]sdl.Rect, len(x.Subs))
 	for _, sub := range x.Subs {
 		locs[sub.Name] = sdl.Rect{X: sub.X, Y: sub.Y, W: sub.W, H: sub.H}
 	}
+	return x.File, locs, nil
+}
 
-	a, err := l.TextureAtlas(path.Join(path.Dir(file), x.File), locs)
-	return a, errors.Trace(err)
+// TexturePackerAtlas loads a TextureAtlas exported by TexturePacker as JSON,
+// in either the hash or array variant. Its frames are added in order, with
+// their filename as name. The trim, rotation and pivot of the frames are kept
+// in their TextureClips.
+func (l *AssetsLoader) TexturePackerAtlas(file string) (*TextureAtlas, error) {
+	data, err := l.Read(file)
+	if err != nil {
+		return nil, err
+	}
+
+	x, err := parseAsepriteJson(data)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := l.TextureAtlas(path.Join(path.Dir(file), x.Meta.Image), nil)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	l.watchTexturePackerAtlas(file, newTexturePackerAtlas(x, a))
+	return a, nil
+}
+
+// newTexturePackerAtlas adds all frames of the TexturePacker file to the
+// TextureAtlas, in order and with their filename as name.
+func newTexturePackerAtlas(x asepriteFile, atlas *TextureAtlas) *TextureAtlas {
+	for _, f := range x.Frames {
+		atlas.AddClip(f.Filename, f.clip())
+	}
+	return atlas
+}
+
+// LibGdxAtlas loads a TextureAtlas from a libGDX .atlas file, in either the
+// legacy or current format. The trim and rotation of the regions are kept in
+// their TextureClips, their splits are added as Insets. Atlases with multiple
+// pages are not supported.
+func (l *AssetsLoader) LibGdxAtlas(file string) (*TextureAtlas, error) {
+	data, err := l.Read(file)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := parseLibGdxAtlasPage(file, data)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := l.TextureAtlas(path.Join(path.Dir(file), page.File), nil)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	l.watchLibGdxAtlas(file, newLibGdxAtlas(page, a))
+	return a, nil
+}
+
+// AsepriteSheet loads a sprite sheet exported by Aseprite as JSON, in either
+// the hash or array variant. Its frames are added to the TextureAtlas in
+// order, so the frame ranges of its tags match the atlas' indexes.
+func (l *AssetsLoader) AsepriteSheet(file string) (*SpriteSheet, error) {
+	data, err := l.Read(file)
+	if err != nil {
+		return nil, err
+	}
+
+	x, err := parseAsepriteJson(data)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := l.TextureAtlas(path.Join(path.Dir(file), x.Meta.Image), nil)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	ss, err := newSpriteSheet(x, a)
+	if err != nil {
+		return nil, err
+	}
+
+	l.watchAsepriteSheet(file, ss)
+	return ss, nil
 }
 
 func (l *AssetsLoader) UniformTextureAtlas(file string, w, h int32, total uint8) (*TextureAtlas, error) {
@@ -140,22 +254,130 @@ func (l *AssetsLoader) UniformTextureAtlas(file string, w, h int32, total uint8)
 	return a, errors.Trace(err)
 }
 
-func (l *AssetsLoader) Font(file string, size int, index uint) (font *sdlttf.Font, err error) {
-	src, err := l.ReadRW(file)
+// GridTextureAtlas loads the texture with file and creates a TextureAtlas
+// with the cells of grid. See NewGridTextureAtlas for details.
+func (l *AssetsLoader) GridTextureAtlas(file string, grid AtlasGrid) (*TextureAtlas, error) {
+	tx, err := l.Texture(file)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 
-	if index < 0 {
-		font, err = sdlttf.OpenFontRW(src, 1, size)
-	} else {
-		font, err = sdlttf.OpenFontIndexRW(src, 1, size, int(index))
+	a, err := NewGridTextureAtlas(tx, grid)
+	return a, errors.Trace(err)
+}
+
+func (l *AssetsLoader) Font(file string, size int, index uint) (*sdlttf.Font, error) {
+	data, err := l.Read(file)
+	if err != nil {
+		return nil, err
 	}
+
+	return l.openFont(file, data, size, index)
+}
+
+// openFont opens the font from data, which is read from file, and adds it to
+// the Fonts map.
+func (l *AssetsLoader) openFont(file string, data []byte, size int, index uint) (*sdlttf.Font, error) {
+	font, err := openFontRW(data, size, index)
 	if err != nil {
-		return nil, errors.Trace(err)
+		return nil, err
 	}
 	if l.Fonts != nil {
 		(*l.Fonts)[file] = font
 	}
 	return font, nil
 }
+
+// ownFont reads and opens the font file without adding it to the Fonts map,
+// so the caller owns the font and is the only one to close it.
+func (l *AssetsLoader) ownFont(file string, size int, index uint) (*sdlttf.Font, error) {
+	data, err := l.Read(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return openFontRW(data, size, index)
+}
+
+// openFontRW opens the font with size and index from data.
+func openFontRW(data []byte, size int, index uint) (font *sdlttf.Font, err error) {
+	src, err := sdl.RWFromMem(data)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	font, err = sdlttf.OpenFontIndexRW(src, 1, size, int(index))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return font, nil
+}
+
+// TrueTypeFont loads the font file and wraps it in a TrueTypeFont, which
+// renders and caches its glyphs using the AssetsLoader's sdl.Renderer.
+func (l *AssetsLoader) TrueTypeFont(file string, size int) (*TrueTypeFont, error) {
+	font, err := l.Font(file, size, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	f := NewTrueTypeFont(l.ren, font)
+	l.watchTrueTypeFont(file, size, f)
+	return f, nil
+}
+
+// BitmapFont loads an AngelCode BMFont .fnt file, in either the text or XML
+// format, and its page textures. Page files are relative to the .fnt file.
+func (l *AssetsLoader) BitmapFont(file string) (*BitmapFont, error) {
+	data, err := l.Read(file)
+	if err != nil {
+		return nil, err
+	}
+
+	desc, err := parseBMFont(data)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := bmFontPageFiles(file, desc)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]*TextureAtlas, len(files))
+	for i, pf := range files {
+		tx, err := l.Texture(pf)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+
+		pages[i] = NewTextureAtlas(tx, nil)
+	}
+
+	f, err := newBitmapFont(desc, pages)
+	if err != nil {
+		return nil, err
+	}
+
+	l.watchBitmapFont(file, f)
+	return f, nil
+}
+
+// bmFontPageFiles returns the paths of the page files of the BMFont, which is
+// read from file, ordered by page id.
+func bmFontPageFiles(file string, desc bmFont) ([]string, error) {
+	files := make([]string, len(desc.Pages))
+	for _, p := range desc.Pages {
+		if p.ID < 0 || p.ID >= len(files) {
+			return nil, errors.Newf("sdlkit: invalid page id %d in `%s`", p.ID, file)
+		}
+
+		files[p.ID] = path.Join(path.Dir(file), p.File)
+	}
+	for i, f := range files {
+		if f == "" {
+			return nil, errors.Newf("sdlkit: missing page %d in `%s`", i, file)
+		}
+	}
+	return files, nil
+}
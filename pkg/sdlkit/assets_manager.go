@@ -0,0 +1,597 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sdlkit
+
+import (
+	"path"
+	"sync"
+
+	"github.com/go-pogo/errors"
+	"github.com/veandco/go-sdl2/sdl"
+	sdlttf "github.com/veandco/go-sdl2/ttf"
+)
+
+type assetKind uint8
+
+const (
+	textureAsset assetKind = iota
+	fontAsset
+	trueTypeFontAsset
+	bitmapFontAsset
+	atlasXmlAsset
+	gridAtlasAsset
+	uniformAtlasAsset
+	asepriteAsset
+)
+
+// assetKey identifies a cached asset. Assets which are loaded from the same
+// file, but with different parameters, are cached separately.
+type assetKey struct {
+	kind   assetKind
+	file   string
+	params [3]int
+	grid   AtlasGrid
+}
+
+type asset struct {
+	key   assetKey
+	value interface{}
+	refs  int
+	// destroy frees the resources which are owned by the asset itself.
+	destroy func() error
+	// deps are the assets this asset is built upon, which are released when
+	// the asset is destroyed.
+	deps []*asset
+}
+
+// AssetManager loads assets using an AssetsLoader and caches them by path.
+// Each load of an asset increases its reference count, which is decreased by
+// releasing it. Once the last reference is released, the asset is destroyed.
+// Atlases and fonts hold a reference to their textures, so a texture shared
+// by multiple atlases is kept alive until all of them are released.
+type AssetManager struct {
+	loader *AssetsLoader
+	mutex  sync.Mutex
+	assets map[assetKey]*asset
+	values map[interface{}]*asset
+	groups map[string]*AssetGroup
+}
+
+func NewAssetManager(loader *AssetsLoader) *AssetManager {
+	return &AssetManager{
+		loader: loader,
+		assets: make(map[assetKey]*asset, 16),
+		values: make(map[interface{}]*asset, 16),
+		groups: make(map[string]*AssetGroup, 2),
+	}
+}
+
+// Loader returns the AssetsLoader which is used to load the assets.
+func (m *AssetManager) Loader() *AssetsLoader { return m.loader }
+
+// Len returns the amount of loaded assets.
+func (m *AssetManager) Len() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return len(m.assets)
+}
+
+// RefCount returns the amount of references to asset v, or 0 when v is not
+// loaded by the AssetManager.
+func (m *AssetManager) RefCount(v interface{}) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if a, ok := m.values[assetValue(v)]; ok {
+		return a.refs
+	}
+	return 0
+}
+
+// acquire returns the cached asset with key and increases its reference
+// count. When the asset is not cached, it is loaded using load.
+func (m *AssetManager) acquire(key assetKey, load func(a *asset) error) (*asset, error) {
+	if a, ok := m.assets[key]; ok {
+		a.refs++
+		return a, nil
+	}
+
+	a := &asset{key: key}
+	if err := load(a); err != nil {
+		for _, dep := range a.deps {
+			errors.Append(&err, m.release(dep))
+		}
+		return nil, err
+	}
+
+	a.refs = 1
+	m.assets[key] = a
+	m.values[a.value] = a
+	return a, nil
+}
+
+// release decreases the reference count of asset a and destroys it, and
+// releases its dependencies, when there are no references left.
+func (m *AssetManager) release(a *asset) error {
+	a.refs--
+	if a.refs > 0 {
+		return nil
+	}
+
+	delete(m.assets, a.key)
+	delete(m.values, a.value)
+
+	var err error
+	if a.destroy != nil {
+		errors.Append(&err, a.destroy())
+	}
+	for _, dep := range a.deps {
+		errors.Append(&err, m.release(dep))
+	}
+	return err
+}
+
+// assetValue returns the value an asset is registered with.
+func assetValue(v interface{}) interface{} {
+	if tc, ok := v.(TextureClip); ok {
+		return tc.Texture
+	}
+	return v
+}
+
+// Release releases a single reference to asset v, which must be loaded by the
+// AssetManager. A TextureClip releases its texture.
+func (m *AssetManager) Release(v interface{}) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.releaseValue(v)
+}
+
+func (m *AssetManager) releaseValue(v interface{}) error {
+	a, ok := m.values[assetValue(v)]
+	if !ok {
+		return errors.Newf("sdlkit.AssetManager: cannot release unknown asset %T", v)
+	}
+	return m.release(a)
+}
+
+// Group returns the AssetGroup with name. It is created when it does not
+// exist.
+func (m *AssetManager) Group(name string) *AssetGroup {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	g, ok := m.groups[name]
+	if !ok {
+		g = &AssetGroup{m: m, name: name}
+		m.groups[name] = g
+	}
+	return g
+}
+
+// ReleaseGroup releases all references which are held by the AssetGroup with
+// name.
+func (m *AssetManager) ReleaseGroup(name string) error {
+	m.mutex.Lock()
+	g, ok := m.groups[name]
+	m.mutex.Unlock()
+
+	if !ok {
+		return nil
+	}
+	return g.Release()
+}
+
+// Destroy destroys all loaded assets, regardless of their reference count.
+func (m *AssetManager) Destroy() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var err error
+	for _, a := range m.assets {
+		if a.destroy != nil {
+			errors.Append(&err, a.destroy())
+		}
+	}
+
+	m.assets = make(map[assetKey]*asset, 16)
+	m.values = make(map[interface{}]*asset, 16)
+	for _, g := range m.groups {
+		g.values = nil
+	}
+	return err
+}
+
+func (m *AssetManager) texture(file string) (*asset, error) {
+	return m.acquire(assetKey{kind: textureAsset, file: file}, func(a *asset) error {
+		data, err := m.loader.Read(file)
+		if err != nil {
+			return err
+		}
+
+		tx, err := LoadTextureFromMem(m.loader.ren, data)
+		if err != nil {
+			return errors.Trace(err)
+		}
+
+		m.loader.watchTexture(file, tx)
+		a.value = tx
+		a.destroy = func() error {
+			m.loader.unwatch(tx)
+			return tx.Destroy()
+		}
+		return nil
+	})
+}
+
+// unwatch returns a destroy function which stops the AssetsLoader from
+// reloading v.
+func (m *AssetManager) unwatch(v interface{}) func() error {
+	return func() error {
+		m.loader.unwatch(v)
+		return nil
+	}
+}
+
+// dependTexture loads the texture with file as a dependency of asset a.
+func (m *AssetManager) dependTexture(a *asset, file string) (*sdl.Texture, error) {
+	dep, err := m.texture(file)
+	if err != nil {
+		return nil, err
+	}
+
+	a.deps = append(a.deps, dep)
+	return dep.value.(*sdl.Texture), nil
+}
+
+func (m *AssetManager) Texture(file string) (*sdl.Texture, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	a, err := m.texture(file)
+	if err != nil {
+		return nil, err
+	}
+	return a.value.(*sdl.Texture), nil
+}
+
+// TextureClip loads the texture with file and returns a TextureClip of its
+// full size. The clip is released by releasing either the TextureClip or its
+// texture.
+func (m *AssetManager) TextureClip(file string) (tc TextureClip, err error) {
+	tc.Texture, err = m.Texture(file)
+	if err != nil {
+		return tc, err
+	}
+
+	_, _, tc.Location.W, tc.Location.H, err = tc.Texture.Query()
+	if err != nil {
+		errors.Append(&err, m.Release(tc.Texture))
+	}
+	return tc, errors.Trace(err)
+}
+
+// TextureAtlasXml loads a TextureAtlas XML file. Its texture is shared with
+// all other assets which use the same image file.
+func (m *AssetManager) TextureAtlasXml(file string) (*TextureAtlas, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	a, err := m.acquire(assetKey{kind: atlasXmlAsset, file: file}, func(a *asset) error {
+		data, err := m.loader.Read(file)
+		if err != nil {
+			return err
+		}
+
+		image, locs, err := parseTextureAtlasXml(data)
+		if err != nil {
+			return err
+		}
+
+		tx, err := m.dependTexture(a, path.Join(path.Dir(file), image))
+		if err != nil {
+			return err
+		}
+
+		atlas := NewTextureAtlas(tx, locs)
+		m.loader.watchTextureAtlasXml(file, atlas)
+		a.value = atlas
+		a.destroy = m.unwatch(atlas)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a.value.(*TextureAtlas), nil
+}
+
+func (m *AssetManager) UniformTextureAtlas(file string, w, h int32, total uint8) (*TextureAtlas, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := assetKey{
+		kind: uniformAtlasAsset,
+		file: file,
+		grid: AtlasGrid{CellW: w, CellH: h, Total: int(total)},
+	}
+	a, err := m.acquire(key, func(a *asset) error {
+		tx, err := m.dependTexture(a, file)
+		if err != nil {
+			return err
+		}
+
+		a.value, err = NewUniformTextureAtlas(tx, w, h, total)
+		return errors.Trace(err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a.value.(*TextureAtlas), nil
+}
+
+// GridTextureAtlas loads a TextureAtlas with the cells of grid. Atlases with
+// different grids share the same texture.
+func (m *AssetManager) GridTextureAtlas(file string, grid AtlasGrid) (*TextureAtlas, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := assetKey{kind: gridAtlasAsset, file: file, grid: grid}
+	a, err := m.acquire(key, func(a *asset) error {
+		tx, err := m.dependTexture(a, file)
+		if err != nil {
+			return err
+		}
+
+		a.value, err = NewGridTextureAtlas(tx, grid)
+		return errors.Trace(err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a.value.(*TextureAtlas), nil
+}
+
+// AsepriteSheet loads a sprite sheet exported by Aseprite as JSON. See
+// AssetsLoader.AsepriteSheet for details.
+func (m *AssetManager) AsepriteSheet(file string) (*SpriteSheet, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	a, err := m.acquire(assetKey{kind: asepriteAsset, file: file}, func(a *asset) error {
+		data, err := m.loader.Read(file)
+		if err != nil {
+			return err
+		}
+
+		x, err := parseAsepriteJson(data)
+		if err != nil {
+			return err
+		}
+
+		tx, err := m.dependTexture(a, path.Join(path.Dir(file), x.Meta.Image))
+		if err != nil {
+			return err
+		}
+
+		ss, err := newSpriteSheet(x, NewTextureAtlas(tx, nil))
+		if err != nil {
+			return err
+		}
+
+		m.loader.watchAsepriteSheet(file, ss)
+		a.value = ss
+		a.destroy = m.unwatch(ss)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a.value.(*SpriteSheet), nil
+}
+
+func (m *AssetManager) font(file string, size int, index uint) (*asset, error) {
+	key := assetKey{
+		kind:   fontAsset,
+		file:   file,
+		params: [3]int{size, int(index)},
+	}
+	return m.acquire(key, func(a *asset) error {
+		// the font is not added to the loader's Fonts map, which would
+		// close it a second time
+		font, err := m.loader.ownFont(file, size, index)
+		if err != nil {
+			return err
+		}
+
+		a.value = font
+		a.destroy = func() error {
+			font.Close()
+			return nil
+		}
+		return nil
+	})
+}
+
+// Font loads the font with file, size and index. Fonts loaded with different
+// sizes or indexes are cached separately.
+func (m *AssetManager) Font(file string, size int, index uint) (*sdlttf.Font, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	a, err := m.font(file, size, index)
+	if err != nil {
+		return nil, err
+	}
+	return a.value.(*sdlttf.Font), nil
+}
+
+// TrueTypeFont loads the font with file and size and wraps it in a
+// TrueTypeFont. Its glyph pages are destroyed, and its font closed, once the
+// last reference is released. The TrueTypeFont owns its font, so it can be
+// replaced when the file is reloaded.
+func (m *AssetManager) TrueTypeFont(file string, size int) (*TrueTypeFont, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := assetKey{kind: trueTypeFontAsset, file: file, params: [3]int{size}}
+	a, err := m.acquire(key, func(a *asset) error {
+		font, err := m.loader.ownFont(file, size, 0)
+		if err != nil {
+			return err
+		}
+
+		f := NewTrueTypeFont(m.loader.ren, font)
+		m.loader.watchTrueTypeFont(file, size, f)
+		a.value = f
+		a.destroy = func() error {
+			err := f.Destroy()
+			f.font.Close()
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a.value.(*TrueTypeFont), nil
+}
+
+// BitmapFont loads an AngelCode BMFont .fnt file and its page textures. See
+// AssetsLoader.BitmapFont for details.
+func (m *AssetManager) BitmapFont(file string) (*BitmapFont, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	a, err := m.acquire(assetKey{kind: bitmapFontAsset, file: file}, func(a *asset) error {
+		data, err := m.loader.Read(file)
+		if err != nil {
+			return err
+		}
+
+		desc, err := parseBMFont(data)
+		if err != nil {
+			return err
+		}
+
+		files, err := bmFontPageFiles(file, desc)
+		if err != nil {
+			return err
+		}
+
+		pages := make([]*TextureAtlas, len(files))
+		for i, pf := range files {
+			tx, err := m.dependTexture(a, pf)
+			if err != nil {
+				return err
+			}
+
+			pages[i] = NewTextureAtlas(tx, nil)
+		}
+
+		f, err := newBitmapFont(desc, pages)
+		if err != nil {
+			return err
+		}
+
+		m.loader.watchBitmapFont(file, f)
+		a.value = f
+		a.destroy = m.unwatch(f)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a.value.(*BitmapFont), nil
+}
+
+// AssetGroup loads assets using its AssetManager and keeps track of the
+// references it holds, so they can be released at once. A typical use is a
+// group per Scene, which is released when the Scene is deactivated.
+type AssetGroup struct {
+	m      *AssetManager
+	name   string
+	values []interface{}
+}
+
+func (g *AssetGroup) Name() string { return g.name }
+
+// Len returns the amount of references held by the AssetGroup.
+func (g *AssetGroup) Len() int {
+	g.m.mutex.Lock()
+	defer g.m.mutex.Unlock()
+	return len(g.values)
+}
+
+// add keeps track of the reference to v when it is successfully loaded.
+func (g *AssetGroup) add(v interface{}, err error) error {
+	if err != nil {
+		return err
+	}
+
+	g.m.mutex.Lock()
+	g.values = append(g.values, v)
+	g.m.mutex.Unlock()
+	return nil
+}
+
+// Release releases all references held by the AssetGroup. The group can be
+// reused afterwards.
+func (g *AssetGroup) Release() error {
+	g.m.mutex.Lock()
+	defer g.m.mutex.Unlock()
+
+	var err error
+	for i := len(g.values) - 1; i >= 0; i-- {
+		errors.Append(&err, g.m.releaseValue(g.values[i]))
+	}
+
+	g.values = nil
+	return err
+}
+
+func (g *AssetGroup) Texture(file string) (*sdl.Texture, error) {
+	tx, err := g.m.Texture(file)
+	return tx, g.add(tx, err)
+}
+
+func (g *AssetGroup) TextureClip(file string) (TextureClip, error) {
+	tc, err := g.m.TextureClip(file)
+	return tc, g.add(tc, err)
+}
+
+func (g *AssetGroup) TextureAtlasXml(file string) (*TextureAtlas, error) {
+	a, err := g.m.TextureAtlasXml(file)
+	return a, g.add(a, err)
+}
+
+func (g *AssetGroup) UniformTextureAtlas(file string, w, h int32, total uint8) (*TextureAtlas, error) {
+	a, err := g.m.UniformTextureAtlas(file, w, h, total)
+	return a, g.add(a, err)
+}
+
+func (g *AssetGroup) GridTextureAtlas(file string, grid AtlasGrid) (*TextureAtlas, error) {
+	a, err := g.m.GridTextureAtlas(file, grid)
+	return a, g.add(a, err)
+}
+
+func (g *AssetGroup) AsepriteSheet(file string) (*SpriteSheet, error) {
+	ss, err := g.m.AsepriteSheet(file)
+	return ss, g.add(ss, err)
+}
+
+func (g *AssetGroup) Font(file string, size int, index uint) (*sdlttf.Font, error) {
+	f, err := g.m.Font(file, size, index)
+	return f, g.add(f, err)
+}
+
+func (g *AssetGroup) TrueTypeFont(file string, size int) (*TrueTypeFont, error) {
+	f, err := g.m.TrueTypeFont(file, size)
+	return f, g.add(f, err)
+}
+
+func (g *AssetGroup) BitmapFont(file string) (*BitmapFont, error) {
+	f, err := g.m.BitmapFont(file)
+	return f, g.add(f, err)
+}
@@ -0,0 +1,124 @@
+package sdlkit
+
+import (
+	"testing"
+
+	"github.com/go-pogo/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeAsset struct {
+	file      string
+	destroyed int
+}
+
+func fakeLoad(fake *fakeAsset, deps ...*asset) func(a *asset) error {
+	return func(a *asset) error {
+		a.value = fake
+		a.deps = deps
+		a.destroy = func() error {
+			fake.destroyed++
+			return nil
+		}
+		return nil
+	}
+}
+
+func TestAssetManager_acquire(t *testing.T) {
+	m := NewAssetManager(nil)
+	fake := &fakeAsset{file: "foo.png"}
+	key := assetKey{kind: textureAsset, file: fake.file}
+
+	a1, err := m.acquire(key, fakeLoad(fake))
+	assert.NoError(t, err)
+
+	a2, err := m.acquire(key, func(a *asset) error {
+		t.Fatal("asset should be loaded from cache")
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Same(t, a1, a2)
+	assert.Equal(t, 2, m.RefCount(fake))
+	assert.Equal(t, 1, m.Len())
+
+	assert.NoError(t, m.Release(fake))
+	assert.Equal(t, 0, fake.destroyed)
+
+	assert.NoError(t, m.Release(fake))
+	assert.Equal(t, 1, fake.destroyed)
+	assert.Equal(t, 0, m.RefCount(fake))
+	assert.Equal(t, 0, m.Len())
+
+	assert.Error(t, m.Release(fake))
+}
+
+func TestAssetManager_acquire_deps(t *testing.T) {
+	m := NewAssetManager(nil)
+	tx := &fakeAsset{file: "sheet.png"}
+	txKey := assetKey{kind: textureAsset, file: tx.file}
+
+	load := func(fake *fakeAsset) func(a *asset) error {
+		return func(a *asset) error {
+			dep, err := m.acquire(txKey, fakeLoad(tx))
+			if err != nil {
+				return err
+			}
+			return fakeLoad(fake, dep)(a)
+		}
+	}
+
+	atlas1 := &fakeAsset{file: "sheet.xml"}
+	atlas2 := &fakeAsset{file: "sheet.json"}
+	_, _ = m.acquire(assetKey{kind: atlasXmlAsset, file: atlas1.file}, load(atlas1))
+	_, _ = m.acquire(assetKey{kind: asepriteAsset, file: atlas2.file}, load(atlas2))
+	assert.Equal(t, 2, m.RefCount(tx))
+
+	assert.NoError(t, m.Release(atlas1))
+	assert.Equal(t, 1, atlas1.destroyed)
+	assert.Equal(t, 0, tx.destroyed)
+
+	assert.NoError(t, m.Release(atlas2))
+	assert.Equal(t, 1, tx.destroyed)
+	assert.Equal(t, 0, m.Len())
+
+	t.Run("failed load", func(t *testing.T) {
+		tx.destroyed = 0
+		_, err := m.acquire(assetKey{kind: bitmapFontAsset, file: "font.fnt"}, func(a *asset) error {
+			dep, _ := m.acquire(txKey, fakeLoad(tx))
+			a.deps = append(a.deps, dep)
+			return errors.New("missing page")
+		})
+
+		assert.Error(t, err)
+		assert.Equal(t, 1, tx.destroyed)
+		assert.Equal(t, 0, m.Len())
+	})
+}
+
+func TestAssetGroup_Release(t *testing.T) {
+	m := NewAssetManager(nil)
+	shared := &fakeAsset{file: "shared.png"}
+	level := &fakeAsset{file: "level.png"}
+
+	menu := m.Group("menu")
+	game := m.Group("game")
+	assert.Same(t, game, m.Group("game"))
+
+	for _, g := range []*AssetGroup{menu, game} {
+		a, _ := m.acquire(assetKey{file: shared.file}, fakeLoad(shared))
+		assert.NoError(t, g.add(a.value, nil))
+	}
+	a, _ := m.acquire(assetKey{file: level.file}, fakeLoad(level))
+	assert.NoError(t, game.add(a.value, nil))
+	assert.Equal(t, 2, game.Len())
+
+	assert.NoError(t, m.ReleaseGroup("game"))
+	assert.Equal(t, 0, game.Len())
+	assert.Equal(t, 1, level.destroyed)
+	assert.Equal(t, 0, shared.destroyed)
+	assert.Equal(t, 1, m.RefCount(shared))
+
+	assert.NoError(t, menu.Release())
+	assert.Equal(t, 1, shared.destroyed)
+	assert.Equal(t, 0, m.Len())
+}
@@ -0,0 +1,477 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sdlkit
+
+import (
+	"io/fs"
+	"sort"
+
+	"github.com/go-pogo/errors"
+	"github.com/veandco/go-sdl2/sdl"
+	"gopkg.in/yaml.v3"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/pak"
+)
+
+// Types of atlases within an AssetManifest.
+const (
+	AtlasXml      = "xml"
+	AtlasUniform  = "uniform"
+	AtlasAseprite = "aseprite"
+)
+
+// Types of fonts within an AssetManifest.
+const (
+	FontTrueType = "ttf"
+	FontBitmap   = "bitmap"
+)
+
+// AssetManifest declares assets under logical ids and groups them into
+// bundles, which can be loaded and unloaded by name using an AssetsLoader.
+// Ids are unique across all kinds of assets.
+//
+//	textures:
+//	  ground: assets/ground.png
+//	atlases:
+//	  objects: {type: xml, file: assets/onlyObjects_default.xml}
+//	  terrain: {type: uniform, file: assets/terrainTiles_retina.png, width: 128, height: 128, total: 40}
+//	fonts:
+//	  hud: {type: ttf, file: fonts/hud.ttf, sizes: [12, 24]}
+//	files:
+//	  world: tanks.tmx
+//	bundles:
+//	  game: [ground, objects, terrain, hud, world]
+//
+// Checksums contains the hex encoded SHA-256 checksums of files, which are
+// verified by Check. They are added by the sdlpak tool when it builds an
+// archive. When the manifest is read with AssetsLoader.Manifest from a
+// pak.LayeredFS, each layer is verified against its own manifest file.
+type AssetManifest struct {
+	Textures map[string]string             `yaml:"textures"`
+	Atlases  map[string]AtlasManifestEntry `yaml:"atlases"`
+	Fonts    map[string]FontManifestEntry  `yaml:"fonts"`
+	// Files are read as is, eg. to load a Tiled map or shader.
+	Files     map[string]string   `yaml:"files"`
+	Bundles   map[string][]string `yaml:"bundles"`
+	Checksums map[string]string   `yaml:"checksums"`
+
+	// source is the file the manifest is read from using
+	// AssetsLoader.Manifest, if any.
+	source string
+}
+
+type AtlasManifestEntry struct {
+	// Type is either AtlasXml, AtlasUniform or AtlasAseprite.
+	Type string `yaml:"type"`
+	File string `yaml:"file"`
+	// Width and Height are the size of a cell of an AtlasUniform.
+	Width  int32 `yaml:"width"`
+	Height int32 `yaml:"height"`
+	// Margin and Spacing are the space around and between the cells of an
+	// AtlasUniform.
+	Margin  int32 `yaml:"margin"`
+	Spacing int32 `yaml:"spacing"`
+	// Total is the amount of cells of an AtlasUniform. When 0, all cells
+	// which fit within its texture are used.
+	Total int `yaml:"total"`
+}
+
+func (a AtlasManifestEntry) grid() AtlasGrid {
+	return AtlasGrid{
+		CellW:   a.Width,
+		CellH:   a.Height,
+		Margin:  a.Margin,
+		Spacing: a.Spacing,
+		Total:   a.Total,
+	}
+}
+
+type FontManifestEntry struct {
+	// Type is either FontTrueType or FontBitmap. It defaults to FontTrueType.
+	Type string `yaml:"type"`
+	File string `yaml:"file"`
+	// Sizes are the sizes a FontTrueType is loaded with.
+	Sizes []int `yaml:"sizes"`
+}
+
+// ParseAssetManifest parses an AssetManifest from either YAML or JSON data.
+func ParseAssetManifest(data []byte) (*AssetManifest, error) {
+	var m AssetManifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &m, nil
+}
+
+// file returns the file of the asset with id, if it is declared.
+func (m *AssetManifest) file(id string) (string, bool) {
+	if file, ok := m.Textures[id]; ok {
+		return file, true
+	}
+	if a, ok := m.Atlases[id]; ok {
+		return a.File, true
+	}
+	if f, ok := m.Fonts[id]; ok {
+		return f.File, true
+	}
+	file, ok := m.Files[id]
+	return file, ok
+}
+
+// Check checks if all assets are valid and their files exist within fsys,
+// if the bundles only contain declared ids, and if the files match their
+// Checksums. The returned error contains all found problems.
+func (m *AssetManifest) Check(fsys fs.FS) error {
+	var err error
+	ids := make(map[string]struct{})
+	declare := func(id, file string) {
+		if _, ok := ids[id]; ok {
+			errors.Append(&err, errors.Newf("sdlkit: asset id `%s` is declared more than once", id))
+		}
+		ids[id] = struct{}{}
+
+		if file == "" {
+			errors.Append(&err, errors.Newf("sdlkit: asset `%s` has no file", id))
+		} else if _, statErr := fs.Stat(fsys, file); statErr != nil {
+			errors.Append(&err, errors.Wrapf(statErr, "sdlkit: file of asset `%s` does not exist", id))
+		}
+	}
+
+	for _, id := range sortedKeys(m.Textures) {
+		declare(id, m.Textures[id])
+	}
+	for _, id := range sortedKeys(m.Atlases) {
+		a := m.Atlases[id]
+		declare(id, a.File)
+
+		switch a.Type {
+		case AtlasXml, AtlasAseprite:
+		case AtlasUniform:
+			if a.Width <= 0 || a.Height <= 0 {
+				errors.Append(&err, errors.Newf("sdlkit: atlas `%s` needs a cell width and height", id))
+			}
+			if a.Margin < 0 || a.Spacing < 0 || a.Total < 0 {
+				errors.Append(&err, errors.Newf("sdlkit: atlas `%s` cannot have a negative margin, spacing or total", id))
+			}
+		default:
+			errors.Append(&err, errors.Newf("sdlkit: atlas `%s` has unknown type `%s`", id, a.Type))
+		}
+	}
+	for _, id := range sortedKeys(m.Fonts) {
+		f := m.Fonts[id]
+		declare(id, f.File)
+
+		switch f.Type {
+		case "", FontTrueType:
+			if len(f.Sizes) == 0 {
+				errors.Append(&err, errors.Newf("sdlkit: font `%s` needs at least one size", id))
+			}
+		case FontBitmap:
+		default:
+			errors.Append(&err, errors.Newf("sdlkit: font `%s` has unknown type `%s`", id, f.Type))
+		}
+	}
+	for _, id := range sortedKeys(m.Files) {
+		declare(id, m.Files[id])
+	}
+
+	for _, name := range sortedKeys(m.Bundles) {
+		for _, id := range m.Bundles[name] {
+			if _, ok := ids[id]; !ok {
+				errors.Append(&err, errors.Newf("sdlkit: bundle `%s` contains undeclared asset `%s`", name, id))
+			}
+		}
+	}
+	errors.Append(&err, m.verify(fsys))
+	return err
+}
+
+// verify verifies the files within fsys against the Checksums. Each layer of
+// a pak.LayeredFS is verified against the checksums within its own version
+// of the manifest file, so layers which override files, eg. mods, do not fail
+// the verification of the layers below them.
+func (m *AssetManifest) verify(fsys fs.FS) error {
+	if l, ok := fsys.(*pak.LayeredFS); ok && m.source != "" {
+		return l.VerifyLayers(m.source, func(data []byte) (map[string]string, error) {
+			lm, err := ParseAssetManifest(data)
+			if err != nil {
+				return nil, err
+			}
+			return lm.Checksums, nil
+		})
+	}
+	if len(m.Checksums) == 0 {
+		return nil
+	}
+	return pak.Verify(fsys, m.Checksums)
+}
+
+// sortedKeys returns the keys of map m, which must have string keys, in
+// order, so errors are reported consistently.
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]string:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]AtlasManifestEntry:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]FontManifestEntry:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string][]string:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// UseManifest checks the AssetManifest against the AssetsLoader's fs, after
+// which its bundles can be loaded using LoadBundle.
+func (l *AssetsLoader) UseManifest(m *AssetManifest) error {
+	if err := m.Check(l.fs); err != nil {
+		return err
+	}
+
+	l.manifest = m
+	return nil
+}
+
+// Manifest reads and parses the AssetManifest file and uses it with
+// UseManifest.
+func (l *AssetsLoader) Manifest(file string) (*AssetManifest, error) {
+	data, err := l.Read(file)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := ParseAssetManifest(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "sdlkit: unable to parse manifest `%s`", file)
+	}
+
+	m.source = file
+	if err = l.UseManifest(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// assetManager returns the AssetManager which is used to load the bundles of
+// the manifest. Assets which are shared between bundles are loaded once, and
+// are destroyed when the last bundle which uses them is unloaded.
+func (l *AssetsLoader) assetManager() *AssetManager {
+	if l.manager == nil {
+		l.manager = NewAssetManager(l)
+	}
+	return l.manager
+}
+
+// LoadBundle loads all assets of the bundle with name, as declared in the
+// manifest. A bundle which is already loaded is returned as is.
+func (l *AssetsLoader) LoadBundle(name string) (*AssetBundle, error) {
+	if b, ok := l.bundles[name]; ok {
+		return b, nil
+	}
+	if l.manifest == nil {
+		return nil, errors.New("sdlkit: no manifest to load bundles from")
+	}
+
+	ids, ok := l.manifest.Bundles[name]
+	if !ok {
+		return nil, errors.Newf("sdlkit: unknown bundle `%s`", name)
+	}
+
+	b := &AssetBundle{
+		name:   name,
+		group:  l.assetManager().Group("bundle:" + name),
+		values: make(map[bundleKey]interface{}, len(ids)),
+	}
+	for _, id := range ids {
+		if err := b.load(l, id); err != nil {
+			file, _ := l.manifest.file(id)
+			errors.Append(&err, b.group.Release())
+			return nil, errors.Wrapf(err, "sdlkit: unable to load `%s` (%s) of bundle `%s`", id, file, name)
+		}
+	}
+
+	if l.bundles == nil {
+		l.bundles = make(map[string]*AssetBundle)
+	}
+	l.bundles[name] = b
+	return b, nil
+}
+
+// Bundle returns the loaded bundle with name.
+func (l *AssetsLoader) Bundle(name string) (*AssetBundle, bool) {
+	b, ok := l.bundles[name]
+	return b, ok
+}
+
+// UnloadBundle releases all assets of the bundle with name. Assets which are
+// not used by any other loaded bundle are destroyed.
+func (l *AssetsLoader) UnloadBundle(name string) error {
+	b, ok := l.bundles[name]
+	if !ok {
+		return nil
+	}
+
+	delete(l.bundles, name)
+	return b.group.Release()
+}
+
+type bundleKey struct {
+	id   string
+	size int
+}
+
+// AssetBundle contains the loaded assets of a bundle, which are retrieved by
+// their id.
+type AssetBundle struct {
+	name   string
+	group  *AssetGroup
+	values map[bundleKey]interface{}
+}
+
+func (b *AssetBundle) Name() string { return b.name }
+
+func (b *AssetBundle) load(l *AssetsLoader, id string) error {
+	m := l.manifest
+	if file, ok := m.Textures[id]; ok {
+		tx, err := b.group.Texture(file)
+		b.values[bundleKey{id: id}] = tx
+		return err
+	}
+	if a, ok := m.Atlases[id]; ok {
+		var v interface{}
+		var err error
+		switch a.Type {
+		case AtlasXml:
+			v, err = b.group.TextureAtlasXml(a.File)
+		case AtlasUniform:
+			v, err = b.group.GridTextureAtlas(a.File, a.grid())
+		case AtlasAseprite:
+			v, err = b.group.AsepriteSheet(a.File)
+		}
+
+		b.values[bundleKey{id: id}] = v
+		return err
+	}
+	if f, ok := m.Fonts[id]; ok {
+		if f.Type == FontBitmap {
+			bf, err := b.group.BitmapFont(f.File)
+			b.values[bundleKey{id: id}] = bf
+			return err
+		}
+
+		for _, size := range f.Sizes {
+			ttf, err := b.group.TrueTypeFont(f.File, size)
+			if err != nil {
+				return err
+			}
+			b.values[bundleKey{id: id, size: size}] = ttf
+		}
+		return nil
+	}
+	if file, ok := m.Files[id]; ok {
+		data, err := l.Read(file)
+		b.values[bundleKey{id: id}] = data
+		return err
+	}
+	return errors.Newf("sdlkit: undeclared asset `%s`", id)
+}
+
+func (b *AssetBundle) get(key bundleKey) (interface{}, error) {
+	v, ok := b.values[key]
+	if !ok {
+		return nil, errors.Newf("sdlkit: unknown asset `%s` in bundle `%s`", key.id, b.name)
+	}
+	return v, nil
+}
+
+func (b *AssetBundle) Texture(id string) (*sdl.Texture, error) {
+	v, err := b.get(bundleKey{id: id})
+	if err != nil {
+		return nil, err
+	}
+	if tx, ok := v.(*sdl.Texture); ok {
+		return tx, nil
+	}
+	return nil, errors.Newf("sdlkit: asset `%s` is not a texture", id)
+}
+
+// TextureFile loads the texture with file as part of the bundle, eg. the image
+// of a tileset. A texture which is already loaded, eg. by an atlas of the
+// bundle, is reused. The texture is released when the bundle is unloaded.
+func (b *AssetBundle) TextureFile(file string) (*sdl.Texture, error) {
+	return b.group.Texture(file)
+}
+
+// TextureAtlas returns the atlas with id. The TextureAtlas of an
+// AtlasAseprite is the atlas of its SpriteSheet.
+func (b *AssetBundle) TextureAtlas(id string) (*TextureAtlas, error) {
+	v, err := b.get(bundleKey{id: id})
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := v.(type) {
+	case *TextureAtlas:
+		return v, nil
+	case *SpriteSheet:
+		return v.Atlas, nil
+	}
+	return nil, errors.Newf("sdlkit: asset `%s` is not a texture atlas", id)
+}
+
+func (b *AssetBundle) SpriteSheet(id string) (*SpriteSheet, error) {
+	v, err := b.get(bundleKey{id: id})
+	if err != nil {
+		return nil, err
+	}
+	if ss, ok := v.(*SpriteSheet); ok {
+		return ss, nil
+	}
+	return nil, errors.Newf("sdlkit: asset `%s` is not a sprite sheet", id)
+}
+
+// TrueTypeFont returns the font with id, loaded with one of the sizes which
+// are declared in the manifest.
+func (b *AssetBundle) TrueTypeFont(id string, size int) (*TrueTypeFont, error) {
+	v, err := b.get(bundleKey{id: id, size: size})
+	if err != nil {
+		return nil, errors.Newf("sdlkit: unknown font `%s` with size %d in bundle `%s`", id, size, b.name)
+	}
+	return v.(*TrueTypeFont), nil
+}
+
+func (b *AssetBundle) BitmapFont(id string) (*BitmapFont, error) {
+	v, err := b.get(bundleKey{id: id})
+	if err != nil {
+		return nil, err
+	}
+	if bf, ok := v.(*BitmapFont); ok {
+		return bf, nil
+	}
+	return nil, errors.Newf("sdlkit: asset `%s` is not a bitmap font", id)
+}
+
+// File returns the contents of the file with id.
+func (b *AssetBundle) File(id string) ([]byte, error) {
+	v, err := b.get(bundleKey{id: id})
+	if err != nil {
+		return nil, err
+	}
+	if data, ok := v.([]byte); ok {
+		return data, nil
+	}
+	return nil, errors.Newf("sdlkit: asset `%s` is not a file", id)
+}
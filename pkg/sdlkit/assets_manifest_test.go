@@ -0,0 +1,111 @@
+package sdlkit
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAssetManifest(t *testing.T) {
+	want := &AssetManifest{
+		Textures: map[string]string{"ground": "ground.png"},
+		Atlases: map[string]AtlasManifestEntry{
+			"terrain": {Type: AtlasUniform, File: "terrain.png", Width: 128, Height: 128, Total: 40},
+		},
+		Fonts: map[string]FontManifestEntry{
+			"hud": {File: "hud.ttf", Sizes: []int{12, 24}},
+		},
+		Bundles: map[string][]string{"game": {"ground", "terrain", "hud"}},
+	}
+
+	tests := map[string]string{
+		"yaml": `
+textures:
+  ground: ground.png
+atlases:
+  terrain: {type: uniform, file: terrain.png, width: 128, height: 128, total: 40}
+fonts:
+  hud:
+    file: hud.ttf
+    sizes: [12, 24]
+bundles:
+  game: [ground, terrain, hud]
+`,
+		"json": `{
+  "textures": {"ground": "ground.png"},
+  "atlases": {"terrain": {"type": "uniform", "file": "terrain.png", "width": 128, "height": 128, "total": 40}},
+  "fonts": {"hud": {"file": "hud.ttf", "sizes": [12, 24]}},
+  "bundles": {"game": ["ground", "terrain", "hud"]}
+}`,
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			have, err := ParseAssetManifest([]byte(data))
+			assert.NoError(t, err)
+			assert.Equal(t, want, have)
+		})
+	}
+}
+
+func TestAssetManifest_Check(t *testing.T) {
+	fsys := fstest.MapFS{
+		"ground.png":  {},
+		"terrain.png": {},
+		"hud.ttf":     {},
+	}
+
+	m := &AssetManifest{
+		Textures: map[string]string{"ground": "ground.png"},
+		Atlases: map[string]AtlasManifestEntry{
+			"terrain": {Type: AtlasUniform, File: "terrain.png", Width: 128, Height: 128, Total: 40},
+		},
+		Fonts: map[string]FontManifestEntry{
+			"hud": {File: "hud.ttf", Sizes: []int{12}},
+		},
+		Bundles: map[string][]string{"game": {"ground", "terrain", "hud"}},
+	}
+	assert.NoError(t, m.Check(fsys))
+
+	t.Run("checksums", func(t *testing.T) {
+		fsys := fstest.MapFS{"ground.png": {Data: []byte("hello")}}
+		m := &AssetManifest{
+			Textures: map[string]string{"ground": "ground.png"},
+			Checksums: map[string]string{
+				"ground.png": "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+			},
+		}
+		assert.NoError(t, m.Check(fsys))
+
+		fsys["ground.png"].Data = []byte("modified")
+		assert.Error(t, m.Check(fsys))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		m := &AssetManifest{
+			Textures: map[string]string{"ground": "missing.png"},
+			Atlases: map[string]AtlasManifestEntry{
+				"ground":  {Type: AtlasXml, File: "terrain.png"},
+				"terrain": {Type: AtlasUniform, File: "terrain.png", Total: -1},
+				"objects": {Type: "png", File: "terrain.png"},
+			},
+			Fonts: map[string]FontManifestEntry{
+				"hud": {File: "hud.ttf"},
+			},
+			Bundles: map[string][]string{"game": {"ground", "player"}},
+		}
+
+		err := m.Check(fsys)
+		assert.Error(t, err)
+
+		msg := err.Error()
+		assert.Contains(t, msg, "file of asset `ground` does not exist")
+		assert.Contains(t, msg, "asset id `ground` is declared more than once")
+		assert.Contains(t, msg, "atlas `terrain` needs a cell width and height")
+		assert.Contains(t, msg, "atlas `terrain` cannot have a negative margin, spacing or total")
+		assert.Contains(t, msg, "atlas `objects` has unknown type `png`")
+		assert.Contains(t, msg, "font `hud` needs at least one size")
+		assert.Contains(t, msg, "bundle `game` contains undeclared asset `player`")
+	})
+}
@@ -0,0 +1,535 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sdlkit
+
+import (
+	"io/fs"
+	"path"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-pogo/errors"
+	sdlimg "github.com/veandco/go-sdl2/img"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// PreloadProgress is the progress of a PreloadQueue.
+type PreloadProgress struct {
+	// Loaded is the amount of assets which are loaded, either successfully or
+	// with an error.
+	Loaded int
+	Total  int
+	// Bytes is the amount of bytes which are read from the AssetsLoader's fs.
+	Bytes int64
+	// TotalBytes is the size of the queued files. It grows while the files
+	// are decoded, as files which are referenced by them, eg. the image of a
+	// TextureAtlasXml, are only known once they are read.
+	TotalBytes int64
+}
+
+// Ratio returns the amount of loaded assets relative to the total, between 0
+// and 1.
+func (p PreloadProgress) Ratio() float64 {
+	if p.Total == 0 {
+		return 1
+	}
+	return float64(p.Loaded) / float64(p.Total)
+}
+
+// BytesRatio returns the amount of read bytes relative to the total bytes,
+// between 0 and 1.
+func (p PreloadProgress) BytesRatio() float64 {
+	if p.TotalBytes == 0 || p.Bytes >= p.TotalBytes {
+		return 1
+	}
+	return float64(p.Bytes) / float64(p.TotalBytes)
+}
+
+// preloadItem is a single asset within a PreloadQueue. It is read and decoded
+// on a worker goroutine, after which its textures are created on the main
+// thread.
+type preloadItem struct {
+	file     string
+	surfaces []*sdl.Surface
+	err      error
+
+	decode func(it *preloadItem) error
+	create func(it *preloadItem) error
+}
+
+// free frees all decoded surfaces of the item.
+func (it *preloadItem) free() {
+	for _, sf := range it.surfaces {
+		sf.Free()
+	}
+	it.surfaces = nil
+}
+
+// PreloadQueue loads a list of assets in the background. Files are read and
+// decoded into sdl.Surfaces using worker goroutines, after which the textures
+// are created on the main thread in small slices of time, so a loading scene
+// or SplashScreen remains responsive while showing the progress.
+//
+// Each asset is added with a pointer which is set to the loaded asset once it
+// is created. The pointers must not be read until the queue is Done.
+type PreloadQueue struct {
+	bytes      int64 // accessed atomically
+	totalBytes int64 // accessed atomically
+
+	// Workers is the amount of goroutines which read and decode the files.
+	// It defaults to the amount of CPUs.
+	Workers int
+
+	loader  *AssetsLoader
+	items   []*preloadItem
+	decoded chan *preloadItem
+	quit    chan struct{}
+	once    sync.Once
+	workers sync.WaitGroup
+	loaded  int
+}
+
+func NewPreloadQueue(loader *AssetsLoader) *PreloadQueue {
+	return &PreloadQueue{
+		Workers: runtime.NumCPU(),
+		loader:  loader,
+		quit:    make(chan struct{}),
+	}
+}
+
+// Len returns the amount of assets within the PreloadQueue.
+func (q *PreloadQueue) Len() int { return len(q.items) }
+
+func (q *PreloadQueue) add(file string, decode, create func(it *preloadItem) error) {
+	if q.decoded != nil {
+		panic("sdlkit.PreloadQueue: unable to add assets after Start")
+	}
+
+	q.items = append(q.items, &preloadItem{
+		file:   file,
+		decode: decode,
+		create: create,
+	})
+}
+
+// read reads file and adds its size to the progress. The size of files other
+// than the item's own file is added to the total as well, as it is not known
+// when the queue is started.
+func (q *PreloadQueue) read(it *preloadItem, file string) ([]byte, error) {
+	data, err := q.loader.Read(file)
+	atomic.AddInt64(&q.bytes, int64(len(data)))
+	if file != it.file {
+		atomic.AddInt64(&q.totalBytes, int64(len(data)))
+	}
+	return data, err
+}
+
+// surface reads and decodes file and adds the sdl.Surface to it.
+func (q *PreloadQueue) surface(it *preloadItem, file string) error {
+	data, err := q.read(it, file)
+	if err != nil {
+		return err
+	}
+
+	src, err := sdl.RWFromMem(data)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	sf, err := sdlimg.LoadRW(src, true)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	it.surfaces = append(it.surfaces, sf)
+	return nil
+}
+
+// texture creates a texture from the decoded sdl.Surface with index i, which
+// is read from file.
+func (q *PreloadQueue) texture(it *preloadItem, i int, file string) (*sdl.Texture, error) {
+	tx, err := q.loader.ren.CreateTextureFromSurface(it.surfaces[i])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	q.loader.addTexture(file, tx)
+	return tx, nil
+}
+
+func (q *PreloadQueue) Texture(file string, dst **sdl.Texture) {
+	q.add(file, func(it *preloadItem) error {
+		return q.surface(it, file)
+	}, func(it *preloadItem) (err error) {
+		*dst, err = q.texture(it, 0, file)
+		return err
+	})
+}
+
+func (q *PreloadQueue) TextureAtlasXml(file string, dst **TextureAtlas) {
+	var image string
+	var locs map[string]sdl.Rect
+
+	q.add(file, func(it *preloadItem) error {
+		data, err := q.read(it, file)
+		if err != nil {
+			return err
+		}
+
+		image, locs, err = parseTextureAtlasXml(data)
+		if err != nil {
+			return err
+		}
+
+		image = path.Join(path.Dir(file), image)
+		return q.surface(it, image)
+	}, func(it *preloadItem) error {
+		tx, err := q.texture(it, 0, image)
+		if err != nil {
+			return err
+		}
+
+		*dst = NewTextureAtlas(tx, locs)
+		q.loader.watchTextureAtlasXml(file, *dst)
+		return nil
+	})
+}
+
+func (q *PreloadQueue) TexturePackerAtlas(file string, dst **TextureAtlas) {
+	var x asepriteFile
+	var image string
+
+	q.add(file, func(it *preloadItem) error {
+		data, err := q.read(it, file)
+		if err != nil {
+			return err
+		}
+
+		x, err = parseAsepriteJson(data)
+		if err != nil {
+			return err
+		}
+
+		image = path.Join(path.Dir(file), x.Meta.Image)
+		return q.surface(it, image)
+	}, func(it *preloadItem) error {
+		tx, err := q.texture(it, 0, image)
+		if err != nil {
+			return err
+		}
+
+		*dst = newTexturePackerAtlas(x, NewTextureAtlas(tx, nil))
+		q.loader.watchTexturePackerAtlas(file, *dst)
+		return nil
+	})
+}
+
+func (q *PreloadQueue) LibGdxAtlas(file string, dst **TextureAtlas) {
+	var page libGdxPage
+	var image string
+
+	q.add(file, func(it *preloadItem) error {
+		data, err := q.read(it, file)
+		if err != nil {
+			return err
+		}
+
+		page, err = parseLibGdxAtlasPage(file, data)
+		if err != nil {
+			return err
+		}
+
+		image = path.Join(path.Dir(file), page.File)
+		return q.surface(it, image)
+	}, func(it *preloadItem) error {
+		tx, err := q.texture(it, 0, image)
+		if err != nil {
+			return err
+		}
+
+		*dst = newLibGdxAtlas(page, NewTextureAtlas(tx, nil))
+		q.loader.watchLibGdxAtlas(file, *dst)
+		return nil
+	})
+}
+
+func (q *PreloadQueue) UniformTextureAtlas(file string, w, h int32, total uint8, dst **TextureAtlas) {
+	q.add(file, func(it *preloadItem) error {
+		return q.surface(it, file)
+	}, func(it *preloadItem) error {
+		tx, err := q.texture(it, 0, file)
+		if err != nil {
+			return err
+		}
+
+		*dst, err = NewUniformTextureAtlas(tx, w, h, total)
+		return errors.Trace(err)
+	})
+}
+
+func (q *PreloadQueue) GridTextureAtlas(file string, grid AtlasGrid, dst **TextureAtlas) {
+	q.add(file, func(it *preloadItem) error {
+		return q.surface(it, file)
+	}, func(it *preloadItem) error {
+		tx, err := q.texture(it, 0, file)
+		if err != nil {
+			return err
+		}
+
+		*dst, err = NewGridTextureAtlas(tx, grid)
+		return errors.Trace(err)
+	})
+}
+
+func (q *PreloadQueue) AsepriteSheet(file string, dst **SpriteSheet) {
+	var x asepriteFile
+	var image string
+
+	q.add(file, func(it *preloadItem) error {
+		data, err := q.read(it, file)
+		if err != nil {
+			return err
+		}
+
+		x, err = parseAsepriteJson(data)
+		if err != nil {
+			return err
+		}
+
+		image = path.Join(path.Dir(file), x.Meta.Image)
+		return q.surface(it, image)
+	}, func(it *preloadItem) error {
+		tx, err := q.texture(it, 0, image)
+		if err != nil {
+			return err
+		}
+
+		*dst, err = newSpriteSheet(x, NewTextureAtlas(tx, nil))
+		if err != nil {
+			return err
+		}
+
+		q.loader.watchAsepriteSheet(file, *dst)
+		return nil
+	})
+}
+
+// TrueTypeFont reads the font file in the background. The font itself is
+// opened on the main thread.
+func (q *PreloadQueue) TrueTypeFont(file string, size int, dst **TrueTypeFont) {
+	var data []byte
+
+	q.add(file, func(it *preloadItem) (err error) {
+		data, err = q.read(it, file)
+		return err
+	}, func(it *preloadItem) error {
+		font, err := q.loader.openFont(file, data, size, 0)
+		if err != nil {
+			return err
+		}
+
+		*dst = NewTrueTypeFont(q.loader.ren, font)
+		q.loader.watchTrueTypeFont(file, size, *dst)
+		return nil
+	})
+}
+
+func (q *PreloadQueue) BitmapFont(file string, dst **BitmapFont) {
+	var desc bmFont
+	var files []string
+
+	q.add(file, func(it *preloadItem) error {
+		data, err := q.read(it, file)
+		if err != nil {
+			return err
+		}
+
+		desc, err = parseBMFont(data)
+		if err != nil {
+			return err
+		}
+
+		files, err = bmFontPageFiles(file, desc)
+		if err != nil {
+			return err
+		}
+
+		for _, pf := range files {
+			if err = q.surface(it, pf); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, func(it *preloadItem) error {
+		pages := make([]*TextureAtlas, len(files))
+		for i, pf := range files {
+			tx, err := q.texture(it, i, pf)
+			if err != nil {
+				return err
+			}
+
+			pages[i] = NewTextureAtlas(tx, nil)
+		}
+
+		var err error
+		*dst, err = newBitmapFont(desc, pages)
+		if err != nil {
+			return err
+		}
+
+		q.loader.watchBitmapFont(file, *dst)
+		return nil
+	})
+}
+
+// Start starts the workers which read and decode the files of the assets in
+// the queue. Assets cannot be added after the queue is started.
+func (q *PreloadQueue) Start() {
+	if q.decoded != nil {
+		return
+	}
+
+	jobs := make(chan *preloadItem, len(q.items))
+	for _, it := range q.items {
+		// files which can not be found fail to load later on
+		if fi, err := fs.Stat(q.loader.fs, it.file); err == nil {
+			atomic.AddInt64(&q.totalBytes, fi.Size())
+		}
+		jobs <- it
+	}
+	close(jobs)
+
+	// buffered so workers never block on items which are not yet created
+	q.decoded = make(chan *preloadItem, len(q.items))
+
+	workers := q.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	q.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go q.work(jobs)
+	}
+}
+
+func (q *PreloadQueue) work(jobs <-chan *preloadItem) {
+	defer q.workers.Done()
+	for it := range jobs {
+		select {
+		case <-q.quit:
+			return
+		default:
+		}
+
+		it.err = it.decode(it)
+		select {
+		case <-q.quit:
+			it.free()
+			return
+		default:
+			q.decoded <- it
+		}
+	}
+}
+
+// Update creates the assets which are decoded by the workers, until budget is
+// exceeded or no more decoded assets are available. It starts the queue when
+// it is not started yet. Update must be called from the main thread, eg. from
+// within a Scene's Update. The returned error contains the errors of all
+// assets which failed to load during this Update.
+func (q *PreloadQueue) Update(budget time.Duration) error {
+	q.Start()
+
+	var err error
+	start := time.Now()
+	for q.loaded < len(q.items) {
+		var it *preloadItem
+		select {
+		case it = <-q.decoded:
+		default:
+			return err
+		}
+
+		if it.err == nil {
+			it.err = it.create(it)
+		}
+		if it.err != nil {
+			errors.Append(&err, errors.Wrapf(it.err, "sdlkit: unable to preload `%s`", it.file))
+		}
+
+		it.free()
+		q.loaded++
+
+		if time.Since(start) >= budget {
+			break
+		}
+	}
+	return err
+}
+
+// Run calls Update until all assets are loaded, calling fn with the progress
+// after each Update. It blocks and should only be used when there is no loop
+// running yet, eg. while a SplashScreen is shown. Run returns early when the
+// queue is canceled, eg. by fn, in which case the queue is not Done.
+func (q *PreloadQueue) Run(budget time.Duration, fn func(p PreloadProgress)) error {
+	var err error
+	for {
+		errors.Append(&err, q.Update(budget))
+		if fn != nil {
+			fn(q.Progress())
+		}
+		if q.Done() || q.canceled() {
+			return err
+		}
+
+		sdl.Delay(1)
+	}
+}
+
+// Progress returns the current progress of the PreloadQueue.
+func (q *PreloadQueue) Progress() PreloadProgress {
+	return PreloadProgress{
+		Loaded: q.loaded,
+		Total:  len(q.items),
+		Bytes:  atomic.LoadInt64(&q.bytes),
+		// grows while the files are decoded
+		TotalBytes: atomic.LoadInt64(&q.totalBytes),
+	}
+}
+
+// Done indicates if all assets of the PreloadQueue are loaded.
+func (q *PreloadQueue) Done() bool { return q.loaded == len(q.items) }
+
+func (q *PreloadQueue) canceled() bool {
+	select {
+	case <-q.quit:
+		return true
+	default:
+		return false
+	}
+}
+
+// Cancel stops the workers and frees the decoded surfaces of the assets
+// which are not yet created. It waits for the workers to finish decoding
+// their current asset, so none are decoded after Cancel returns.
+func (q *PreloadQueue) Cancel() {
+	q.once.Do(func() { close(q.quit) })
+	if q.decoded == nil {
+		return
+	}
+
+	q.workers.Wait()
+
+	for {
+		select {
+		case it := <-q.decoded:
+			it.free()
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package sdlkit
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/go-pogo/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreloadProgress_Ratio(t *testing.T) {
+	assert.Equal(t, 1.0, PreloadProgress{}.Ratio())
+	assert.Equal(t, 0.25, PreloadProgress{Loaded: 1, Total: 4}.Ratio())
+	assert.Equal(t, 1.0, PreloadProgress{Bytes: 10}.BytesRatio())
+	assert.Equal(t, 0.5, PreloadProgress{Bytes: 10, TotalBytes: 20}.BytesRatio())
+}
+
+func newTestPreloadQueue(files ...string) *PreloadQueue {
+	fsys := make(fstest.MapFS, len(files))
+	for _, file := range files {
+		fsys[file] = &fstest.MapFile{Data: []byte(file)}
+	}
+	return NewPreloadQueue(NewAssetsLoader(fsys, nil))
+}
+
+func TestPreloadQueue_Update(t *testing.T) {
+	q := newTestPreloadQueue("foo.png", "bar.png", "baz.png")
+	q.Workers = 2
+
+	var created []string
+	for _, file := range []string{"foo.png", "bar.png", "baz.png"} {
+		file := file
+		q.add(file, func(it *preloadItem) error {
+			if file == "bar.png" {
+				return errors.New("invalid image")
+			}
+			return nil
+		}, func(it *preloadItem) error {
+			created = append(created, file)
+			return nil
+		})
+	}
+
+	assert.Equal(t, PreloadProgress{Total: 3}, q.Progress())
+	assert.False(t, q.Done())
+
+	var err error
+	deadline := time.Now().Add(time.Second)
+	for !q.Done() && time.Now().Before(deadline) {
+		errors.Append(&err, q.Update(time.Hour))
+	}
+
+	assert.True(t, q.Done())
+	assert.Error(t, err)
+	assert.ElementsMatch(t, []string{"foo.png", "baz.png"}, created)
+	assert.Equal(t, 1.0, q.Progress().Ratio())
+	assert.Equal(t, int64(21), q.Progress().TotalBytes)
+	assert.Panics(t, func() {
+		q.add("qux.png", nil, nil)
+	})
+}
+
+func TestPreloadQueue_Cancel(t *testing.T) {
+	q := newTestPreloadQueue("foo.png")
+	q.Workers = 1
+
+	release := make(chan struct{})
+	var created int
+	for i := 0; i < 3; i++ {
+		first := i == 0
+		q.add("foo.png", func(it *preloadItem) error {
+			if !first {
+				<-release
+			}
+			return nil
+		}, func(it *preloadItem) error {
+			created++
+			return nil
+		})
+	}
+
+	err := q.Run(time.Hour, func(p PreloadProgress) {
+		if p.Loaded == 1 {
+			// the worker is still decoding the second item
+			time.AfterFunc(10*time.Millisecond, func() { close(release) })
+			q.Cancel()
+		}
+	})
+
+	assert.NoError(t, err)
+	assert.False(t, q.Done())
+	assert.Equal(t, 1, created)
+	assert.Len(t, q.decoded, 0)
+}
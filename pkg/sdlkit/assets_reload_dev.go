@@ -0,0 +1,424 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !prod
+
+package sdlkit
+
+import (
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-pogo/errors"
+	sdlimg "github.com/veandco/go-sdl2/img"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// DefaultHotReloadInterval is the interval at which the modification times of
+// the loaded assets are polled, when HotReload is called without an interval.
+var DefaultHotReloadInterval = 500 * time.Millisecond
+
+var (
+	assetReloadEventOnce sync.Once
+	assetReloadEventType uint32
+
+	watchedMutex sync.Mutex
+	// watched contains the assetsWatcher of each watched asset, so the asset
+	// can be unwatched when it is destroyed
+	watched = make(map[interface{}]*assetsWatcher)
+	// watchedFiles contains the watched files of all AssetsLoaders, the index
+	// of a file is used as the code of its reload event
+	watchedFiles []*watchedFile
+)
+
+// assetsWatcher keeps track of the files an AssetsLoader has loaded, and how
+// to reload the assets which are created from them.
+type assetsWatcher struct {
+	fs       fs.FS
+	interval time.Duration
+	polled   time.Time
+	files    []*watchedFile
+	index    map[string]int
+}
+
+type watchedFile struct {
+	id        int
+	name      string
+	watcher   *assetsWatcher
+	modTime   time.Time
+	reloaders []assetReloader
+}
+
+type assetReloader struct {
+	value  interface{}
+	reload func(data []byte) error
+}
+
+// HotReload enables reloading of assets, which are loaded by the AssetsLoader,
+// when their files within directory dir change. This is useful while
+// developing, as the AssetsLoader's fs is often embedded in the binary.
+// Changes are detected by polling the modification times of the files every
+// interval, from within PollChanges. Only assets which are loaded after
+// HotReload is called are reloaded. HotReload is not available in prod builds.
+func (l *AssetsLoader) HotReload(dir string, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultHotReloadInterval
+	}
+
+	w := l.watcher()
+	w.fs = os.DirFS(dir)
+	w.interval = interval
+}
+
+// PollChanges reloads the assets of which the files have changed since the
+// last poll. Existing references to the reloaded textures, atlases and fonts
+// are updated in place, after which a sdl.UserEvent is pushed for each changed
+// file. The code of the event identifies the file among the files of all
+// AssetsLoaders, use ReloadedAsset to get the file from such an event.
+// PollChanges should be called from the main thread, eg. once each frame.
+func (l *AssetsLoader) PollChanges() error {
+	if l.watch == nil || l.watch.fs == nil {
+		return nil
+	}
+
+	now := time.Now()
+	if now.Sub(l.watch.polled) < l.watch.interval {
+		return nil
+	}
+
+	l.watch.polled = now
+	changed, err := l.watch.poll()
+
+	assetReloadEventOnce.Do(func() {
+		assetReloadEventType = sdl.RegisterEvents(1)
+	})
+	for _, i := range changed {
+		_, pushErr := sdl.PushEvent(&sdl.UserEvent{
+			Type:      assetReloadEventType,
+			Timestamp: sdl.GetTicks(),
+			Code:      int32(l.watch.files[i].id),
+		})
+		errors.Append(&err, pushErr)
+	}
+	return err
+}
+
+// ReloadedAsset returns the file which is reloaded when e is pushed by
+// PollChanges of this AssetsLoader. Events of other AssetsLoaders are ignored.
+func (l *AssetsLoader) ReloadedAsset(e *sdl.UserEvent) (string, bool) {
+	if l.watch == nil || e.Type != assetReloadEventType || assetReloadEventType == 0 {
+		return "", false
+	}
+
+	watchedMutex.Lock()
+	defer watchedMutex.Unlock()
+
+	if e.Code < 0 || int(e.Code) >= len(watchedFiles) {
+		return "", false
+	}
+
+	f := watchedFiles[e.Code]
+	if f.watcher != l.watch {
+		return "", false
+	}
+	return f.name, true
+}
+
+func (l *AssetsLoader) watcher() *assetsWatcher {
+	if l.watch == nil {
+		l.watch = &assetsWatcher{index: make(map[string]int)}
+	}
+	return l.watch
+}
+
+// watching returns the assetsWatcher when HotReload is enabled, or nil when
+// it is not.
+func (l *AssetsLoader) watching() *assetsWatcher {
+	if l.watch == nil || l.watch.fs == nil {
+		return nil
+	}
+	return l.watch
+}
+
+// add registers reload as the function which updates value when file
+// changes.
+func (w *assetsWatcher) add(file string, value interface{}, reload func(data []byte) error) {
+	watchedMutex.Lock()
+	defer watchedMutex.Unlock()
+
+	i, ok := w.index[file]
+	if !ok {
+		i = len(w.files)
+		w.index[file] = i
+		w.files = append(w.files, &watchedFile{
+			id:      len(watchedFiles),
+			name:    file,
+			watcher: w,
+		})
+		watchedFiles = append(watchedFiles, w.files[i])
+	}
+
+	f := w.files[i]
+	f.reloaders = append(f.reloaders, assetReloader{value: value, reload: reload})
+	watched[value] = w
+}
+
+// remove stops reloading value.
+func (w *assetsWatcher) remove(value interface{}) {
+	watchedMutex.Lock()
+	delete(watched, value)
+	watchedMutex.Unlock()
+
+	for _, f := range w.files {
+		for i := len(f.reloaders) - 1; i >= 0; i-- {
+			if f.reloaders[i].value == value {
+				f.reloaders = append(f.reloaders[:i], f.reloaders[i+1:]...)
+			}
+		}
+	}
+}
+
+// poll reloads the assets of all changed files and returns their indexes.
+// The first poll of a file only records its modification time.
+func (w *assetsWatcher) poll() ([]int, error) {
+	var changed []int
+	var err error
+	for i, f := range w.files {
+		if len(f.reloaders) == 0 {
+			continue
+		}
+
+		stat, statErr := fs.Stat(w.fs, f.name)
+		if statErr != nil {
+			continue // file is probably being written
+		}
+
+		modTime := stat.ModTime()
+		if f.modTime.IsZero() || !modTime.After(f.modTime) {
+			f.modTime = modTime
+			continue
+		}
+
+		data, readErr := fs.ReadFile(w.fs, f.name)
+		if readErr != nil {
+			errors.Append(&err, readErr)
+			continue
+		}
+
+		f.modTime = modTime
+		for _, r := range f.reloaders {
+			if reloadErr := r.reload(data); reloadErr != nil {
+				errors.Append(&err, errors.Wrapf(reloadErr, "sdlkit: unable to reload `%s`", f.name))
+			}
+		}
+		changed = append(changed, i)
+	}
+	return changed, err
+}
+
+func (l *AssetsLoader) unwatch(value interface{}) { unwatchAsset(value) }
+
+// unwatchAsset stops reloading value, which is watched by any AssetsLoader. It
+// is called when value is destroyed.
+func unwatchAsset(value interface{}) {
+	watchedMutex.Lock()
+	w, ok := watched[value]
+	watchedMutex.Unlock()
+
+	if ok {
+		w.remove(value)
+	}
+}
+
+// watchTexture updates the pixels of tx when file changes. The size of the
+// reloaded image must match the size of tx.
+func (l *AssetsLoader) watchTexture(file string, tx *sdl.Texture) {
+	w := l.watching()
+	if w == nil {
+		return
+	}
+
+	w.add(file, tx, func(data []byte) error {
+		src, err := sdl.RWFromMem(data)
+		if err != nil {
+			return errors.Trace(err)
+		}
+
+		sf, err := sdlimg.LoadRW(src, true)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		defer sf.Free()
+
+		format, _, w, h, err := tx.Query()
+		if err != nil {
+			if errors.Is(err, ErrInvalidTexture) {
+				err = nil // texture is probably already destroyed
+			}
+			return err
+		}
+		if sf.W != w || sf.H != h {
+			return errors.Newf("sdlkit: size changed from %dx%d to %dx%d", w, h, sf.W, sf.H)
+		}
+
+		conv, err := sf.ConvertFormat(format, 0)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		defer conv.Free()
+
+		return errors.Trace(tx.Update(nil, conv.Pixels(), int(conv.Pitch)))
+	})
+}
+
+// watchTextureAtlasXml updates the locations of ta when file changes, while
+// keeping the metadata which is added to its regions. Changes to its image
+// are handled by watchTexture.
+func (l *AssetsLoader) watchTextureAtlasXml(file string, ta *TextureAtlas) {
+	w := l.watching()
+	if w == nil {
+		return
+	}
+
+	w.add(file, ta, func(data []byte) error {
+		_, locs, err := parseTextureAtlasXml(data)
+		if err != nil {
+			return err
+		}
+
+		ta.setLocations(locs)
+		return nil
+	})
+}
+
+// watchTexturePackerAtlas replaces the regions of ta when file changes. Their
+// trim, rotation and pivot are defined by file, so they are replaced as well.
+func (l *AssetsLoader) watchTexturePackerAtlas(file string, ta *TextureAtlas) {
+	w := l.watching()
+	if w == nil {
+		return
+	}
+
+	w.add(file, ta, func(data []byte) error {
+		x, err := parseAsepriteJson(data)
+		if err != nil {
+			return err
+		}
+
+		*ta = *newTexturePackerAtlas(x, NewTextureAtlas(ta.texture, nil))
+		return nil
+	})
+}
+
+// watchLibGdxAtlas replaces the regions of ta when file changes. Their trim,
+// rotation and insets are defined by file, so they are replaced as well.
+func (l *AssetsLoader) watchLibGdxAtlas(file string, ta *TextureAtlas) {
+	w := l.watching()
+	if w == nil {
+		return
+	}
+
+	w.add(file, ta, func(data []byte) error {
+		page, err := parseLibGdxAtlasPage(file, data)
+		if err != nil {
+			return err
+		}
+
+		*ta = *newLibGdxAtlas(page, NewTextureAtlas(ta.texture, nil))
+		return nil
+	})
+}
+
+// watchAsepriteSheet replaces the frames, tags and slices of ss when file
+// changes.
+func (l *AssetsLoader) watchAsepriteSheet(file string, ss *SpriteSheet) {
+	w := l.watching()
+	if w == nil {
+		return
+	}
+
+	w.add(file, ss, func(data []byte) error {
+		x, err := parseAsepriteJson(data)
+		if err != nil {
+			return err
+		}
+
+		res, err := newSpriteSheet(x, NewTextureAtlas(ss.Atlas.texture, nil))
+		if err != nil {
+			return err
+		}
+
+		atlas := ss.Atlas
+		*atlas = *res.Atlas
+		res.Atlas = atlas
+		*ss = *res
+		return nil
+	})
+}
+
+// watchTrueTypeFont reopens the font of f when file changes. Its cached glyphs
+// are destroyed, so they are rendered again using the new font. The previous
+// sdlttf.Font is closed and replaced within the Fonts map, as f owns the font.
+func (l *AssetsLoader) watchTrueTypeFont(file string, size int, f *TrueTypeFont) {
+	w := l.watching()
+	if w == nil {
+		return
+	}
+
+	w.add(file, f, func(data []byte) error {
+		font, err := OpenFontFromMem(data, size)
+		if err != nil {
+			return errors.Trace(err)
+		}
+
+		prev := f.font
+		err = f.destroyPages()
+		f.font = font
+		f.kerning = make(map[[2]rune]int32)
+
+		if l.Fonts != nil && (*l.Fonts)[file] == prev {
+			(*l.Fonts)[file] = font
+		}
+		prev.Close()
+		return err
+	})
+}
+
+// watchBitmapFont replaces the glyphs of f when file changes. Its pages must
+// remain the same, changes to the page images are handled by watchTexture.
+func (l *AssetsLoader) watchBitmapFont(file string, f *BitmapFont) {
+	w := l.watching()
+	if w == nil {
+		return
+	}
+
+	w.add(file, f, func(data []byte) error {
+		desc, err := parseBMFont(data)
+		if err != nil {
+			return err
+		}
+		if len(desc.Pages) != len(f.pages) {
+			return errors.Newf("sdlkit: amount of pages changed from %d to %d", len(f.pages), len(desc.Pages))
+		}
+
+		pages := make([]*TextureAtlas, len(f.pages))
+		for i, p := range f.pages {
+			pages[i] = NewTextureAtlas(p.texture, nil)
+		}
+
+		res, err := newBitmapFont(desc, pages)
+		if err != nil {
+			return err
+		}
+
+		for i, p := range f.pages {
+			*p = *pages[i]
+			res.pages[i] = p
+		}
+		*f = *res
+		return nil
+	})
+}
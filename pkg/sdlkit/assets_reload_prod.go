@@ -0,0 +1,42 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build prod
+
+package sdlkit
+
+import (
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+type assetsWatcher struct{}
+
+// HotReload is not available in prod builds.
+func (l *AssetsLoader) HotReload(string, time.Duration) {}
+
+// PollChanges is not available in prod builds.
+func (l *AssetsLoader) PollChanges() error { return nil }
+
+// ReloadedAsset is not available in prod builds.
+func (l *AssetsLoader) ReloadedAsset(*sdl.UserEvent) (string, bool) { return "", false }
+
+func (l *AssetsLoader) unwatch(interface{}) {}
+
+func unwatchAsset(interface{}) {}
+
+func (l *AssetsLoader) watchTexture(string, *sdl.Texture) {}
+
+func (l *AssetsLoader) watchTextureAtlasXml(string, *TextureAtlas) {}
+
+func (l *AssetsLoader) watchTexturePackerAtlas(string, *TextureAtlas) {}
+
+func (l *AssetsLoader) watchLibGdxAtlas(string, *TextureAtlas) {}
+
+func (l *AssetsLoader) watchAsepriteSheet(string, *SpriteSheet) {}
+
+func (l *AssetsLoader) watchTrueTypeFont(string, int, *TrueTypeFont) {}
+
+func (l *AssetsLoader) watchBitmapFont(string, *BitmapFont) {}
@@ -0,0 +1,87 @@
+// +build !prod
+
+package sdlkit
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/go-pogo/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssetsWatcher_poll(t *testing.T) {
+	start := time.Now()
+	files := fstest.MapFS{
+		"foo.png": {Data: []byte("foo"), ModTime: start},
+		"bar.png": {Data: []byte("bar"), ModTime: start},
+	}
+
+	var reloaded []string
+	reload := func(data []byte) error {
+		reloaded = append(reloaded, string(data))
+		return nil
+	}
+
+	w := &assetsWatcher{fs: files, index: make(map[string]int)}
+	w.add("foo.png", 1, reload)
+	w.add("bar.png", 2, reload)
+	w.add("bar.png", 3, reload)
+	w.add("missing.png", 4, reload)
+
+	// the first poll records the modification times
+	changed, err := w.poll()
+	assert.NoError(t, err)
+	assert.Empty(t, changed)
+
+	files["bar.png"] = &fstest.MapFile{Data: []byte("baz"), ModTime: start.Add(time.Second)}
+	changed, err = w.poll()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, changed)
+	assert.Equal(t, []string{"baz", "baz"}, reloaded)
+
+	t.Run("remove", func(t *testing.T) {
+		reloaded = nil
+		w.remove(3)
+		files["bar.png"] = &fstest.MapFile{ModTime: start.Add(2 * time.Second)}
+
+		changed, err = w.poll()
+		assert.Equal(t, []int{1}, changed)
+		assert.Equal(t, []string{""}, reloaded)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		w.add("foo.png", 5, func([]byte) error {
+			return errors.New("invalid image")
+		})
+		files["foo.png"] = &fstest.MapFile{ModTime: start.Add(time.Second)}
+
+		changed, err = w.poll()
+		assert.Error(t, err)
+		assert.Equal(t, []int{0}, changed)
+	})
+}
+
+func TestUnwatchAsset(t *testing.T) {
+	w := &assetsWatcher{fs: fstest.MapFS{}, index: make(map[string]int)}
+	value := new(int)
+	w.add("foo.png", value, func([]byte) error { return nil })
+	assert.Len(t, w.files[0].reloaders, 1)
+
+	unwatchAsset(value)
+	assert.Empty(t, w.files[0].reloaders)
+	assert.NotContains(t, watched, value)
+}
+
+func TestAssetsWatcher_add_ids(t *testing.T) {
+	a := &assetsWatcher{index: make(map[string]int)}
+	b := &assetsWatcher{index: make(map[string]int)}
+	a.add("foo.png", 1, nil)
+	a.add("foo.png", 2, nil)
+	b.add("foo.png", 3, nil)
+
+	assert.NotEqual(t, a.files[0].id, b.files[0].id)
+	assert.Same(t, a.files[0], watchedFiles[a.files[0].id])
+	assert.Same(t, b, watchedFiles[b.files[0].id].watcher)
+}
@@ -0,0 +1,574 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sdlkit
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/go-pogo/errors"
+	sdlimg "github.com/veandco/go-sdl2/img"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// AtlasPacker packs a set of images into one or more pages at runtime, so
+// they can be drawn using a few large textures instead of a texture per
+// image. The images are bin-packed using the MaxRects algorithm.
+type AtlasPacker struct {
+	// MaxW and MaxH are the maximum size of a single page.
+	MaxW, MaxH int32
+	// Padding is the amount of transparent pixels between the images.
+	Padding int32
+	// Extrude is the amount of pixels the edges of each image are repeated
+	// outwards, which prevents bleeding of neighbouring pixels when a clip is
+	// drawn scaled or at a subpixel position.
+	Extrude int32
+	// CacheDir is the directory to which the packed pages are saved as PNG
+	// images, each with a TexturePacker JSON file. When the images and
+	// settings of the packer did not change, Pack loads the pages from
+	// CacheDir instead of packing them again.
+	CacheDir string
+
+	name     string
+	images   []packerImage
+	cacheErr error
+}
+
+type packerImage struct {
+	name string
+	data []byte
+	sf   *sdl.Surface
+}
+
+// NewAtlasPacker creates a new AtlasPacker with a maximum page size of
+// 2048x2048. Name is used as prefix of the files within CacheDir.
+func NewAtlasPacker(name string) *AtlasPacker {
+	return &AtlasPacker{
+		MaxW:    2048,
+		MaxH:    2048,
+		Padding: 2,
+		Extrude: 1,
+		name:    name,
+	}
+}
+
+// Len returns the amount of images added to the AtlasPacker.
+func (p *AtlasPacker) Len() int { return len(p.images) }
+
+// Add adds the encoded image data, eg. the contents of a PNG file. It is only
+// decoded when the pages are not loaded from CacheDir.
+func (p *AtlasPacker) Add(name string, data []byte) {
+	p.images = append(p.images, packerImage{name: name, data: data})
+}
+
+// AddSurface adds the sdl.Surface. It must not be freed before Pack is called.
+func (p *AtlasPacker) AddSurface(name string, sf *sdl.Surface) {
+	p.images = append(p.images, packerImage{name: name, sf: sf})
+}
+
+// AddFiles reads the files using the AssetsLoader and adds them with their
+// file name as name.
+func (p *AtlasPacker) AddFiles(l *AssetsLoader, files ...string) error {
+	for _, file := range files {
+		data, err := l.Read(file)
+		if err != nil {
+			return err
+		}
+
+		p.Add(file, data)
+	}
+	return nil
+}
+
+// Hash returns a hash of the images and settings of the AtlasPacker, which is
+// used to determine if the pages within CacheDir are up to date.
+func (p *AtlasPacker) Hash() string {
+	h := sha256.New()
+	_ = binary.Write(h, binary.LittleEndian, [4]int32{p.MaxW, p.MaxH, p.Padding, p.Extrude})
+
+	for _, img := range p.images {
+		_ = binary.Write(h, binary.LittleEndian, int64(len(img.name)))
+		h.Write([]byte(img.name))
+
+		if img.sf != nil {
+			_ = binary.Write(h, binary.LittleEndian, [4]int32{img.sf.W, img.sf.H, img.sf.Pitch, int32(img.sf.Format.Format)})
+			h.Write(img.sf.Pixels())
+		} else {
+			_ = binary.Write(h, binary.LittleEndian, int64(len(img.data)))
+			h.Write(img.data)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Pack packs the images into pages and creates a texture for each page. The
+// returned PackedAtlas contains a TextureAtlas per page, with a clip for each
+// image, registered under its name.
+func (p *AtlasPacker) Pack(ren *sdl.Renderer) (*PackedAtlas, error) {
+	if len(p.images) == 0 {
+		return nil, errors.New("sdlkit.AtlasPacker: no images to pack")
+	}
+
+	names := make(map[string]struct{}, len(p.images))
+	for _, img := range p.images {
+		if _, ok := names[img.name]; ok {
+			return nil, errors.Newf("sdlkit.AtlasPacker: duplicate image name `%s`", img.name)
+		}
+		names[img.name] = struct{}{}
+	}
+
+	var hash string
+	p.cacheErr = nil
+	if p.CacheDir != "" {
+		hash = p.Hash()
+		if pa, ok := p.loadCache(ren, hash); ok {
+			return pa, nil
+		}
+	}
+
+	surfaces, err := p.decode()
+	if err != nil {
+		return nil, err
+	}
+	defer freeSurfaces(surfaces)
+
+	sizes := make([]sdl.Rect, len(surfaces))
+	for i, sf := range surfaces {
+		sizes[i] = sdl.Rect{W: sf.W, H: sf.H}
+	}
+
+	places, pageCount, err := packRects(sizes, p.MaxW, p.MaxH, p.Padding, p.Extrude)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]*sdl.Surface, pageCount)
+	defer freeSurfaces(pages)
+
+	frames := make([][]asepriteFrame, pageCount)
+	for i, sf := range surfaces {
+		pl := places[i]
+		frames[pl.page] = append(frames[pl.page], asepriteFrame{
+			Filename: p.images[i].name,
+			Frame:    asepriteRect{X: pl.rect.X, Y: pl.rect.Y, W: pl.rect.W, H: pl.rect.H},
+		})
+
+		if pages[pl.page] == nil {
+			w, h := pageSize(places, pl.page, p.Extrude)
+			pages[pl.page], err = sdl.CreateRGBSurfaceWithFormat(0, w, h, 32, sdl.PIXELFORMAT_RGBA32)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+
+		page := pages[pl.page]
+		copyPixels(page.Pixels(), int(page.Pitch), sf.Pixels(), int(sf.Pitch), pl.rect)
+		extrudePixels(page.Pixels(), int(page.Pitch), pl.rect, p.Extrude)
+	}
+
+	pa := &PackedAtlas{pages: make(map[string]int, len(p.images))}
+	for i, page := range pages {
+		tx, err := ren.CreateTextureFromSurface(page)
+		if err != nil {
+			_ = pa.Destroy()
+			return nil, errors.Trace(err)
+		}
+
+		pa.add(tx, frames[i])
+	}
+
+	if p.CacheDir != "" {
+		// the pages are packed again when they could not be cached
+		p.cacheErr = p.saveCache(pages, frames, hash)
+	}
+	return pa, nil
+}
+
+// CacheErr returns the error which occurred while saving the packed pages to
+// CacheDir, during the last call to Pack. Such an error does not fail Pack.
+func (p *AtlasPacker) CacheErr() error { return p.cacheErr }
+
+// decode returns the images as sdl.Surfaces with PIXELFORMAT_RGBA32, which
+// must be freed by the caller.
+func (p *AtlasPacker) decode() ([]*sdl.Surface, error) {
+	res := make([]*sdl.Surface, 0, len(p.images))
+	for _, img := range p.images {
+		sf := img.sf
+		if sf == nil {
+			src, err := sdl.RWFromMem(img.data)
+			if err != nil {
+				freeSurfaces(res)
+				return nil, errors.Trace(err)
+			}
+
+			sf, err = sdlimg.LoadRW(src, true)
+			if err != nil {
+				freeSurfaces(res)
+				return nil, errors.Wrapf(err, "sdlkit.AtlasPacker: unable to decode `%s`", img.name)
+			}
+		}
+
+		conv, err := sf.ConvertFormat(sdl.PIXELFORMAT_RGBA32, 0)
+		if sf != img.sf {
+			sf.Free()
+		}
+		if err != nil {
+			freeSurfaces(res)
+			return nil, errors.Trace(err)
+		}
+
+		res = append(res, conv)
+	}
+	return res, nil
+}
+
+func freeSurfaces(surfaces []*sdl.Surface) {
+	for _, sf := range surfaces {
+		if sf != nil {
+			sf.Free()
+		}
+	}
+}
+
+func (p *AtlasPacker) cacheFile(page int, ext string) string {
+	name := p.name
+	if name == "" {
+		name = "atlas"
+	}
+	return filepath.Join(p.CacheDir, name+"-"+strconv.Itoa(page)+ext)
+}
+
+// packerCacheFile is a TexturePacker JSON file, in the array variant, which
+// describes a single page within the CacheDir of an AtlasPacker.
+type packerCacheFile struct {
+	Frames []asepriteFrame `json:"frames"`
+	Meta   struct {
+		App   string       `json:"app"`
+		Image string       `json:"image"`
+		Size  asepriteRect `json:"size"`
+		Hash  string       `json:"hash"`
+		Pages int          `json:"pages"`
+	} `json:"meta"`
+}
+
+func (p *AtlasPacker) saveCache(pages []*sdl.Surface, frames [][]asepriteFrame, hash string) error {
+	if err := os.MkdirAll(p.CacheDir, 0755); err != nil {
+		return errors.Trace(err)
+	}
+
+	for i, page := range pages {
+		image := p.cacheFile(i, ".png")
+		if err := sdlimg.SavePNG(page, image); err != nil {
+			return errors.Wrapf(err, "sdlkit.AtlasPacker: unable to save `%s`", image)
+		}
+
+		var x packerCacheFile
+		x.Frames = frames[i]
+		x.Meta.App = "sdlkit.AtlasPacker"
+		x.Meta.Image = filepath.Base(image)
+		x.Meta.Size = asepriteRect{W: page.W, H: page.H}
+		x.Meta.Hash = hash
+		x.Meta.Pages = len(pages)
+
+		data, err := json.MarshalIndent(x, "", "  ")
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err = os.WriteFile(p.cacheFile(i, ".json"), data, 0644); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// loadCache loads the pages from CacheDir when they are packed from the same
+// images and settings, as indicated by hash. Any error is treated as a cache
+// miss, after which the images are packed again.
+func (p *AtlasPacker) loadCache(ren *sdl.Renderer, hash string) (*PackedAtlas, bool) {
+	pa := &PackedAtlas{pages: make(map[string]int, len(p.images))}
+	for i, pages := 0, 1; i < pages; i++ {
+		data, err := os.ReadFile(p.cacheFile(i, ".json"))
+		if err != nil {
+			_ = pa.Destroy()
+			return nil, false
+		}
+
+		var x packerCacheFile
+		if err = json.Unmarshal(data, &x); err != nil || x.Meta.Hash != hash {
+			_ = pa.Destroy()
+			return nil, false
+		}
+
+		data, err = os.ReadFile(filepath.Join(p.CacheDir, x.Meta.Image))
+		if err != nil {
+			_ = pa.Destroy()
+			return nil, false
+		}
+
+		tx, err := LoadTextureFromMem(ren, data)
+		if err != nil {
+			_ = pa.Destroy()
+			return nil, false
+		}
+
+		pa.add(tx, x.Frames)
+		pages = x.Meta.Pages
+	}
+
+	if len(pa.pages) != len(p.images) {
+		_ = pa.Destroy()
+		return nil, false
+	}
+	return pa, true
+}
+
+// PackedAtlas is the result of an AtlasPacker. It contains a TextureAtlas for
+// each packed page.
+type PackedAtlas struct {
+	Pages []*TextureAtlas
+	pages map[string]int
+}
+
+func (pa *PackedAtlas) add(tx *sdl.Texture, frames []asepriteFrame) {
+	a := NewTextureAtlas(tx, nil)
+	for _, f := range frames {
+		a.AddClip(f.Filename, f.clip())
+		pa.pages[f.Filename] = len(pa.Pages)
+	}
+	pa.Pages = append(pa.Pages, a)
+}
+
+// Page returns the TextureAtlas of the page which contains the image with
+// name.
+func (pa *PackedAtlas) Page(name string) (*TextureAtlas, bool) {
+	i, ok := pa.pages[name]
+	if !ok {
+		return nil, false
+	}
+	return pa.Pages[i], true
+}
+
+func (pa *PackedAtlas) HasName(name string) bool {
+	_, ok := pa.pages[name]
+	return ok
+}
+
+// GetFromName returns the TextureClip of the image with name, from the page
+// which contains it.
+func (pa *PackedAtlas) GetFromName(name string) (TextureClip, error) {
+	a, ok := pa.Page(name)
+	if !ok {
+		return TextureClip{}, errors.Newf("sdlkit: unknown name `%s` in PackedAtlas", name)
+	}
+	return a.GetFromName(name)
+}
+
+// Destroy destroys the textures of all pages.
+func (pa *PackedAtlas) Destroy() error {
+	var err error
+	for _, a := range pa.Pages {
+		errors.Append(&err, a.Destroy())
+	}
+	return err
+}
+
+// packedRect is the location of an image within a page. Rect excludes the
+// extruded edges and padding.
+type packedRect struct {
+	page int
+	rect sdl.Rect
+}
+
+// packRects packs rectangles with the sizes of sizes into as few pages of at
+// most maxW*maxH as possible. Each rectangle is grown by extrude on all sides
+// and separated from its neighbours by padding. It returns the locations in
+// the order of sizes, and the amount of pages.
+func packRects(sizes []sdl.Rect, maxW, maxH, padding, extrude int32) ([]packedRect, int, error) {
+	// larger rectangles are packed first, which results in less wasted space
+	order := make([]int, len(sizes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := sizes[order[i]], sizes[order[j]]
+		if sa, sb := maxInt32(a.W, a.H), maxInt32(b.W, b.H); sa != sb {
+			return sa > sb
+		}
+		return a.W*a.H > b.W*b.H
+	})
+
+	// the padding after the last rectangle of a row or column does not need
+	// to fit within the page
+	binW, binH := maxW+padding, maxH+padding
+
+	var bins []*maxRectsBin
+	res := make([]packedRect, len(sizes))
+	for _, i := range order {
+		w := sizes[i].W + 2*extrude + padding
+		h := sizes[i].H + 2*extrude + padding
+		if w > binW || h > binH {
+			return nil, 0, errors.Newf(
+				"sdlkit.AtlasPacker: image of %dx%d does not fit within a page of %dx%d",
+				sizes[i].W, sizes[i].H, maxW, maxH,
+			)
+		}
+
+		page := -1
+		var r sdl.Rect
+		for b, bin := range bins {
+			var ok bool
+			if r, ok = bin.insert(w, h); ok {
+				page = b
+				break
+			}
+		}
+		if page < 0 {
+			page = len(bins)
+			bins = append(bins, newMaxRectsBin(binW, binH))
+			r, _ = bins[page].insert(w, h)
+		}
+
+		res[i] = packedRect{
+			page: page,
+			rect: sdl.Rect{X: r.X + extrude, Y: r.Y + extrude, W: sizes[i].W, H: sizes[i].H},
+		}
+	}
+	return res, len(bins), nil
+}
+
+// pageSize returns the size of the page which fits all its rectangles,
+// including their extruded edges.
+func pageSize(places []packedRect, page int, extrude int32) (w, h int32) {
+	for _, pl := range places {
+		if pl.page != page {
+			continue
+		}
+		w = maxInt32(w, pl.rect.X+pl.rect.W+extrude)
+		h = maxInt32(h, pl.rect.Y+pl.rect.H+extrude)
+	}
+	return w, h
+}
+
+// maxRectsBin keeps track of the free space within a page, as a list of
+// possibly overlapping maximal free rectangles.
+type maxRectsBin struct {
+	free []sdl.Rect
+}
+
+func newMaxRectsBin(w, h int32) *maxRectsBin {
+	return &maxRectsBin{free: []sdl.Rect{{W: w, H: h}}}
+}
+
+// insert finds a location for a rectangle of w*h using the best short side
+// fit heuristic, and marks it as used.
+func (b *maxRectsBin) insert(w, h int32) (sdl.Rect, bool) {
+	best := -1
+	var bestShort, bestLong int32
+	for i, f := range b.free {
+		if w > f.W || h > f.H {
+			continue
+		}
+
+		dw, dh := f.W-w, f.H-h
+		short, long := minInt32(dw, dh), maxInt32(dw, dh)
+		if best < 0 || short < bestShort || (short == bestShort && long < bestLong) {
+			best, bestShort, bestLong = i, short, long
+		}
+	}
+	if best < 0 {
+		return sdl.Rect{}, false
+	}
+
+	r := sdl.Rect{X: b.free[best].X, Y: b.free[best].Y, W: w, H: h}
+	b.place(r)
+	return r, true
+}
+
+// place splits all free rectangles which intersect with r into the maximal
+// rectangles around it, and removes the ones contained by others.
+func (b *maxRectsBin) place(r sdl.Rect) {
+	free := make([]sdl.Rect, 0, len(b.free)+4)
+	for _, f := range b.free {
+		if !f.HasIntersection(&r) {
+			free = append(free, f)
+			continue
+		}
+
+		if r.X > f.X {
+			free = append(free, sdl.Rect{X: f.X, Y: f.Y, W: r.X - f.X, H: f.H})
+		}
+		if r.X+r.W < f.X+f.W {
+			free = append(free, sdl.Rect{X: r.X + r.W, Y: f.Y, W: f.X + f.W - r.X - r.W, H: f.H})
+		}
+		if r.Y > f.Y {
+			free = append(free, sdl.Rect{X: f.X, Y: f.Y, W: f.W, H: r.Y - f.Y})
+		}
+		if r.Y+r.H < f.Y+f.H {
+			free = append(free, sdl.Rect{X: f.X, Y: r.Y + r.H, W: f.W, H: f.Y + f.H - r.Y - r.H})
+		}
+	}
+
+	b.free = b.free[:0]
+	for i, f := range free {
+		contained := false
+		for j, g := range free {
+			if i != j && containsRect(g, f) && (f != g || j < i) {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			b.free = append(b.free, f)
+		}
+	}
+}
+
+// containsRect indicates if a fully contains b.
+func containsRect(a, b sdl.Rect) bool {
+	return b.X >= a.X && b.Y >= a.Y && b.X+b.W <= a.X+a.W && b.Y+b.H <= a.Y+a.H
+}
+
+// copyPixels copies the 32 bit pixels of src to dst at location r.
+func copyPixels(dst []byte, dstPitch int, src []byte, srcPitch int, r sdl.Rect) {
+	n := int(r.W) * 4
+	for y := 0; y < int(r.H); y++ {
+		d := (int(r.Y)+y)*dstPitch + int(r.X)*4
+		copy(dst[d:d+n], src[y*srcPitch:y*srcPitch+n])
+	}
+}
+
+// extrudePixels repeats the 32 bit pixels at the edges of location r n times
+// outwards, including the corners.
+func extrudePixels(pix []byte, pitch int, r sdl.Rect, n int32) {
+	if n <= 0 || r.W <= 0 || r.H <= 0 {
+		return
+	}
+
+	x0, x1 := int(r.X), int(r.X+r.W-1)
+	for y := int(r.Y); y < int(r.Y+r.H); y++ {
+		row := y * pitch
+		left := pix[row+x0*4 : row+x0*4+4]
+		right := pix[row+x1*4 : row+x1*4+4]
+		for i := 1; i <= int(n); i++ {
+			copy(pix[row+(x0-i)*4:], left)
+			copy(pix[row+(x1+i)*4:], right)
+		}
+	}
+
+	// rows are copied including their extruded pixels, which fills the corners
+	start, end := (x0-int(n))*4, (x1+int(n)+1)*4
+	top := pix[int(r.Y)*pitch+start : int(r.Y)*pitch+end]
+	bottom := pix[int(r.Y+r.H-1)*pitch+start : int(r.Y+r.H-1)*pitch+end]
+	for i := 1; i <= int(n); i++ {
+		copy(pix[(int(r.Y)-i)*pitch+start:], top)
+		copy(pix[(int(r.Y+r.H-1)+i)*pitch+start:], bottom)
+	}
+}
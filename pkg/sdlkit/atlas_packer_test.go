@@ -0,0 +1,83 @@
+package sdlkit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func TestPackRects(t *testing.T) {
+	sizes := []sdl.Rect{
+		{W: 10, H: 10},
+		{W: 30, H: 20},
+		{W: 20, H: 30},
+		{W: 5, H: 5},
+		{W: 16, H: 16},
+	}
+
+	places, pages, err := packRects(sizes, 64, 64, 2, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, pages)
+	assert.Equal(t, sdl.Rect{X: 1, Y: 1, W: 30, H: 20}, places[1].rect, "largest is packed first")
+
+	for i, a := range places {
+		assert.Equal(t, sizes[i].W, a.rect.W)
+		assert.Equal(t, sizes[i].H, a.rect.H)
+
+		// extruded edges stay within the page
+		assert.GreaterOrEqual(t, a.rect.X, int32(1))
+		assert.GreaterOrEqual(t, a.rect.Y, int32(1))
+		assert.LessOrEqual(t, a.rect.X+a.rect.W+1, int32(64))
+		assert.LessOrEqual(t, a.rect.Y+a.rect.H+1, int32(64))
+
+		// extruded edges and padding do not overlap
+		ga := ShrinkRect(a.rect, -1)
+		ga.W += 2
+		ga.H += 2
+		for j, b := range places {
+			if i != j {
+				assert.False(t, ga.HasIntersection(&sdl.Rect{X: b.rect.X - 1, Y: b.rect.Y - 1, W: b.rect.W + 2, H: b.rect.H + 2}), "%d overlaps %d", i, j)
+			}
+		}
+	}
+
+	w, h := pageSize(places, 0, 1)
+	assert.LessOrEqual(t, w, int32(64))
+	assert.LessOrEqual(t, h, int32(64))
+
+	t.Run("multiple pages", func(t *testing.T) {
+		sizes := []sdl.Rect{{W: 30, H: 30}, {W: 30, H: 30}, {W: 30, H: 30}}
+		places, pages, err := packRects(sizes, 64, 32, 0, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, pages)
+		assert.Equal(t, []int{0, 0, 1}, []int{places[0].page, places[1].page, places[2].page})
+		assert.Equal(t, sdl.Rect{X: 0, Y: 0, W: 30, H: 30}, places[2].rect)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		_, _, err := packRects([]sdl.Rect{{W: 64, H: 10}}, 64, 64, 2, 1)
+		assert.Error(t, err)
+	})
+}
+
+func TestExtrudePixels(t *testing.T) {
+	// 4x4 page with a 2x2 image in the center
+	pitch := 4 * 4
+	pix := make([]byte, pitch*4)
+	src := []byte{
+		1, 1, 1, 1, 2, 2, 2, 2,
+		3, 3, 3, 3, 4, 4, 4, 4,
+	}
+
+	r := sdl.Rect{X: 1, Y: 1, W: 2, H: 2}
+	copyPixels(pix, pitch, src, 8, r)
+	extrudePixels(pix, pitch, r, 1)
+
+	want := []byte{1, 1, 2, 2, 1, 1, 2, 2, 3, 3, 4, 4, 3, 3, 4, 4}
+	got := make([]byte, 0, 16)
+	for i := 0; i < len(pix); i += 4 {
+		got = append(got, pix[i])
+	}
+	assert.Equal(t, want, got)
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package audio plays sound effects and music using SDL_mixer.
+package audio
+
+import (
+	"math"
+	"time"
+
+	"github.com/go-pogo/errors"
+	"github.com/veandco/go-sdl2/mix"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// DefaultChannels is the amount of channels sounds are played on, when Open
+// is called without an amount of channels.
+var DefaultChannels = 16
+
+// VolumeGroup is a group of which the volumes are multiplied with the volume
+// of each sound or music within it.
+type VolumeGroup uint8
+
+const (
+	// MasterVolume applies to both music and sound effects.
+	MasterVolume VolumeGroup = iota
+	MusicVolume
+	SfxVolume
+)
+
+// Mixer plays sounds on a pool of channels, and music which fades out and in
+// between tracks. All its methods must be called from the main thread.
+type Mixer struct {
+	volumes  [3]float64
+	channels []channelState
+	lastID   uint64
+
+	music *Music
+	// next is the music which starts once the current music is faded out
+	next      *Music
+	nextLoops int
+	fadeIn    time.Duration
+}
+
+type channelState struct {
+	id     uint64
+	volume float64
+}
+
+// Open opens the default audio device and allocates the channels to play
+// sounds on. The audio subsystem is initialized when needed. Set the
+// SDL_AUDIODRIVER environment variable to "dummy" to use the Mixer without an
+// audio device, eg. while running tests. Call mix.Init beforehand to play
+// formats other than WAV.
+func Open(channels int) (*Mixer, error) {
+	if sdl.WasInit(sdl.INIT_AUDIO) == 0 {
+		if err := sdl.InitSubSystem(sdl.INIT_AUDIO); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
+	err := mix.OpenAudio(mix.DEFAULT_FREQUENCY, mix.DEFAULT_FORMAT, mix.DEFAULT_CHANNELS, mix.DEFAULT_CHUNKSIZE)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	if channels <= 0 {
+		channels = DefaultChannels
+	}
+
+	m := &Mixer{
+		volumes:  [3]float64{1, 1, 1},
+		channels: make([]channelState, mix.AllocateChannels(channels)),
+	}
+	return m, nil
+}
+
+// Close stops all sounds and music and closes the audio device.
+func (m *Mixer) Close() {
+	mix.HaltChannel(-1)
+	mix.HaltMusic()
+	mix.CloseAudio()
+}
+
+// Volume returns the volume of VolumeGroup g, between 0 and 1.
+func (m *Mixer) Volume(g VolumeGroup) float64 { return m.volumes[g] }
+
+// SetVolume sets the volume of VolumeGroup g, between 0 and 1. The volumes of
+// the playing sounds and music are updated accordingly.
+func (m *Mixer) SetVolume(g VolumeGroup, v float64) {
+	m.volumes[g] = clamp(v)
+	if g != SfxVolume {
+		m.updateMusicVolume()
+	}
+	if g != MusicVolume {
+		for i, st := range m.channels {
+			mix.Volume(i, m.sfxVolume(st.volume))
+		}
+	}
+}
+
+func (m *Mixer) sfxVolume(v float64) int {
+	return mixVolume(m.volumes[MasterVolume] * m.volumes[SfxVolume] * v)
+}
+
+func (m *Mixer) updateMusicVolume() {
+	v := m.volumes[MasterVolume] * m.volumes[MusicVolume]
+	if m.music != nil {
+		v *= m.music.Volume
+	}
+	mix.VolumeMusic(mixVolume(v))
+}
+
+// Update starts the next music once the current music has faded out. It
+// should be called once each frame, eg. from within a Scene's Update.
+func (m *Mixer) Update() error {
+	if m.next == nil || mix.PlayingMusic() {
+		return nil
+	}
+	return m.startNext()
+}
+
+// mixVolume converts a volume between 0 and 1 to a volume SDL_mixer uses.
+func mixVolume(v float64) int {
+	return int(math.Round(clamp(v) * mix.MAX_VOLUME))
+}
+
+// panning returns the volumes of the left and right speakers for pan, which
+// is between -1 (left) and 1 (right).
+func panning(pan float64) (left, right uint8) {
+	pan = math.Max(-1, math.Min(1, pan))
+	return uint8(math.Round(255 * math.Min(1, 1-pan))),
+		uint8(math.Round(255 * math.Min(1, 1+pan)))
+}
+
+func setPanning(channel int, pan float64) error {
+	l, r := panning(pan)
+	return errors.Trace(mix.SetPanning(channel, l, r))
+}
+
+func clamp(v float64) float64 { return math.Max(0, math.Min(1, v)) }
+
+func millis(d time.Duration) int { return int(d / time.Millisecond) }
@@ -0,0 +1,181 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
+)
+
+func TestMain(m *testing.M) {
+	_ = os.Setenv("SDL_AUDIODRIVER", "dummy")
+	if err := sdl.Init(sdl.INIT_AUDIO); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	sdl.Quit()
+	os.Exit(code)
+}
+
+// wav returns a mono 16 bit WAV file with a silent sample of d.
+func wav(d time.Duration) []byte {
+	const rate = 22050
+	size := uint32(rate*d/time.Second) * 2
+
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	_ = binary.Write(&buf, binary.LittleEndian, 36+size)
+	buf.WriteString("WAVEfmt ")
+	_ = binary.Write(&buf, binary.LittleEndian, []uint32{16})
+	_ = binary.Write(&buf, binary.LittleEndian, []uint16{1, 1})
+	_ = binary.Write(&buf, binary.LittleEndian, []uint32{rate, rate * 2})
+	_ = binary.Write(&buf, binary.LittleEndian, []uint16{2, 16})
+	buf.WriteString("data")
+	_ = binary.Write(&buf, binary.LittleEndian, size)
+	buf.Write(make([]byte, size))
+	return buf.Bytes()
+}
+
+func openMixer(t *testing.T, channels int) (*Mixer, *sdlkit.AssetsLoader) {
+	m, err := Open(channels)
+	require.NoError(t, err)
+	t.Cleanup(m.Close)
+
+	return m, sdlkit.NewAssetsLoader(fstest.MapFS{
+		"short.wav": {Data: wav(100 * time.Millisecond)},
+		"long.wav":  {Data: wav(5 * time.Second)},
+	}, nil)
+}
+
+func TestMixer_Play(t *testing.T) {
+	m, l := openMixer(t, 2)
+	snd, err := LoadSound(l, "long.wav")
+	require.NoError(t, err)
+	defer snd.Destroy()
+
+	assert.Equal(t, 5*time.Second, snd.Length())
+
+	first, err := m.PlayEx(snd, 1, 0, -1)
+	assert.NoError(t, err)
+	second, err := m.Play(snd)
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.Index(), second.Index())
+	assert.True(t, first.Playing())
+
+	t.Run("steal oldest", func(t *testing.T) {
+		third, err := m.Play(snd)
+		assert.NoError(t, err)
+		assert.Equal(t, first.Index(), third.Index())
+		assert.False(t, first.Playing())
+		assert.True(t, third.Playing())
+
+		// first no longer controls the channel
+		first.Stop()
+		assert.True(t, third.Playing())
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		second.Stop()
+		assert.False(t, second.Playing())
+	})
+}
+
+func TestLoadSound_error(t *testing.T) {
+	_, l := openMixer(t, 1)
+	_, err := LoadSound(l, "missing.wav")
+	assert.Error(t, err)
+}
+
+func TestMixer_SetVolume(t *testing.T) {
+	m, _ := openMixer(t, 1)
+	assert.Equal(t, 1.0, m.Volume(MasterVolume))
+
+	m.SetVolume(MasterVolume, .5)
+	m.SetVolume(SfxVolume, 2)
+	assert.Equal(t, .5, m.Volume(MasterVolume))
+	assert.Equal(t, 1.0, m.Volume(SfxVolume))
+	assert.Equal(t, 32, m.sfxVolume(.5))
+
+	m.SetVolume(MusicVolume, -1)
+	assert.Equal(t, 0.0, m.Volume(MusicVolume))
+}
+
+func TestMixer_PlayMusic(t *testing.T) {
+	m, l := openMixer(t, 1)
+	a, err := LoadMusic(l, "long.wav")
+	require.NoError(t, err)
+	defer a.Destroy()
+	b, err := LoadMusic(l, "long.wav")
+	require.NoError(t, err)
+	defer b.Destroy()
+
+	assert.NoError(t, m.PlayMusic(a, -1, time.Second))
+	assert.Same(t, a, m.Music())
+	assert.True(t, m.MusicPlaying())
+
+	assert.NoError(t, m.PlayMusic(b, -1, 200*time.Millisecond))
+	assert.Same(t, b, m.Music())
+
+	deadline := time.Now().Add(2 * time.Second)
+	for m.music != b && time.Now().Before(deadline) {
+		assert.NoError(t, m.Update())
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Same(t, b, m.music)
+	assert.True(t, m.MusicPlaying())
+
+	m.StopMusic(0)
+	assert.False(t, m.MusicPlaying())
+}
+
+type musicScene struct {
+	sdlkit.Scene
+	name  string
+	music *Music
+}
+
+func (s *musicScene) SceneName() string  { return s.name }
+func (s *musicScene) SceneMusic() *Music { return s.music }
+
+func TestMixer_FollowScenes(t *testing.T) {
+	m, l := openMixer(t, 1)
+	mus, err := LoadMusic(l, "long.wav")
+	require.NoError(t, err)
+	defer mus.Destroy()
+
+	sm := sdlkit.NewSceneManager()
+	sm.Add(&musicScene{name: "menu", music: mus})
+	sm.Add(&musicScene{name: "silent"})
+	m.FollowScenes(sm)
+
+	_, err = sm.Activate("menu")
+	assert.NoError(t, err)
+	assert.Same(t, mus, m.Music())
+
+	_, err = sm.Activate("silent")
+	assert.NoError(t, err)
+	assert.Nil(t, m.Music())
+}
+
+func TestPanning(t *testing.T) {
+	tests := map[float64][2]uint8{
+		-2: {255, 0},
+		-1: {255, 0},
+		0:  {255, 255},
+		.5: {128, 255},
+		1:  {0, 255},
+	}
+	for pan, want := range tests {
+		l, r := panning(pan)
+		assert.Equal(t, want, [2]uint8{l, r}, "pan %v", pan)
+	}
+}
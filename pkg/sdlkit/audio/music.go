@@ -0,0 +1,156 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package audio
+
+import (
+	"time"
+
+	"github.com/go-pogo/errors"
+	"github.com/veandco/go-sdl2/mix"
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
+)
+
+// Music is a music track which is decoded while it is played.
+type Music struct {
+	mus *mix.Music
+	// data is streamed from while the music plays and must be kept alive for
+	// as long as mus exists
+	data []byte
+	// Volume is the volume the music is played with, between 0 and 1.
+	Volume float64
+}
+
+// LoadMusic loads the music with file using the AssetsLoader. Its data is
+// kept in memory and decoded while it plays.
+func LoadMusic(l *sdlkit.AssetsLoader, file string) (*Music, error) {
+	data, err := l.Read(file)
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := sdl.RWFromMem(data)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	mus, err := mix.LoadMUSRW(src, 1)
+	if err != nil {
+		return nil, errors.Wrapf(err, "audio: unable to load music `%s`", file)
+	}
+	return &Music{mus: mus, data: data, Volume: 1}, nil
+}
+
+func (mus *Music) Music() *mix.Music { return mus.mus }
+
+// Destroy frees the music. It must not be playing.
+func (mus *Music) Destroy() error {
+	if mus.mus != nil {
+		mus.mus.Free()
+		mus.mus = nil
+		mus.data = nil
+	}
+	return nil
+}
+
+// Music returns the music which is playing, or is about to play once the
+// current music has faded out.
+func (m *Mixer) Music() *Music {
+	if m.next != nil {
+		return m.next
+	}
+	return m.music
+}
+
+// PlayMusic plays mus and repeats it loops times, or forever when loops is
+// -1. When other music is playing, it fades out the current music and then
+// fades in mus. SDL_mixer plays one music track at a time, so the tracks do
+// not overlap: the current music is faded out during the first half of fade,
+// and mus is faded in during the second half. Update must be called each
+// frame to start mus once the current music has faded out.
+func (m *Mixer) PlayMusic(mus *Music, loops int, fade time.Duration) error {
+	if mus == m.Music() && mix.PlayingMusic() {
+		return nil
+	}
+
+	m.next, m.nextLoops, m.fadeIn = mus, loops, fade
+	if !mix.PlayingMusic() || fade <= 0 {
+		mix.HaltMusic()
+		return m.startNext()
+	}
+
+	m.fadeIn = fade / 2
+	if mix.FadingMusic() != mix.FADING_OUT {
+		mix.FadeOutMusic(millis(fade / 2))
+	}
+	return nil
+}
+
+// StopMusic stops the current music after fading it out during fade.
+func (m *Mixer) StopMusic(fade time.Duration) {
+	m.next = nil
+	m.music = nil
+	if fade <= 0 {
+		mix.HaltMusic()
+	} else if mix.FadingMusic() != mix.FADING_OUT {
+		mix.FadeOutMusic(millis(fade))
+	}
+}
+
+// MusicPlaying indicates if music is playing, or is about to play.
+func (m *Mixer) MusicPlaying() bool {
+	return m.next != nil || (m.music != nil && mix.PlayingMusic())
+}
+
+func (m *Mixer) startNext() error {
+	mus, loops, fade := m.next, m.nextLoops, m.fadeIn
+	m.next = nil
+	m.music = mus
+	m.updateMusicVolume()
+
+	// music loops are the amount of times it is played, where chunk loops
+	// are the amount of times it is repeated
+	if loops >= 0 {
+		loops++
+	}
+	if fade > 0 {
+		return errors.Trace(mus.mus.FadeIn(loops, millis(fade)))
+	}
+	return errors.Trace(mus.mus.Play(loops))
+}
+
+// SceneMusic is a sdlkit.Scene which has music that should play while it is
+// active.
+type SceneMusic interface {
+	sdlkit.Scene
+	SceneMusic() *Music
+}
+
+// MusicFade is the duration of the fade out and fade in between the music of
+// scenes.
+var MusicFade = 2 * time.Second
+
+// FollowScenes fades to the music of each scene which is activated by
+// sm and implements SceneMusic. Scenes without music keep the current music
+// playing.
+func (m *Mixer) FollowScenes(sm *sdlkit.SceneManager) {
+	sm.OnActivate(m.SceneChanged)
+}
+
+// SceneChanged fades to the music of scene, when it implements
+// SceneMusic.
+func (m *Mixer) SceneChanged(scene sdlkit.Scene) error {
+	sc, ok := scene.(SceneMusic)
+	if !ok {
+		return nil
+	}
+	if mus := sc.SceneMusic(); mus != nil {
+		return m.PlayMusic(mus, -1, MusicFade)
+	}
+
+	m.StopMusic(MusicFade)
+	return nil
+}
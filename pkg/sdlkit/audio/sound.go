@@ -0,0 +1,159 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package audio
+
+import (
+	"time"
+
+	"github.com/go-pogo/errors"
+	"github.com/veandco/go-sdl2/mix"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
+)
+
+// Sound is a sound effect which is fully decoded in memory.
+type Sound struct {
+	chunk *mix.Chunk
+	// Volume is the volume the sound is played with by Mixer.Play, between 0
+	// and 1.
+	Volume float64
+}
+
+func NewSound(chunk *mix.Chunk) *Sound {
+	return &Sound{chunk: chunk, Volume: 1}
+}
+
+// LoadSound loads and decodes the sound with file using the AssetsLoader.
+func LoadSound(l *sdlkit.AssetsLoader, file string) (*Sound, error) {
+	src, err := l.ReadRW(file)
+	if err != nil {
+		return nil, err
+	}
+
+	chunk, err := mix.LoadWAVRW(src, true)
+	if err != nil {
+		return nil, errors.Wrapf(err, "audio: unable to load sound `%s`", file)
+	}
+	return NewSound(chunk), nil
+}
+
+func (s *Sound) Chunk() *mix.Chunk { return s.chunk }
+
+// Length returns the duration of the sound.
+func (s *Sound) Length() time.Duration {
+	return time.Duration(s.chunk.LengthInMs()) * time.Millisecond
+}
+
+// Destroy frees the sound. It must not be playing.
+func (s *Sound) Destroy() error {
+	if s.chunk != nil {
+		s.chunk.Free()
+		s.chunk = nil
+	}
+	return nil
+}
+
+// Play plays s once on a free channel, with its Volume and centered.
+func (m *Mixer) Play(s *Sound) (Channel, error) {
+	return m.PlayEx(s, s.Volume, 0, 0)
+}
+
+// PlayEx plays s on a free channel with volume, which is between 0 and 1, and
+// pan, which is between -1 (left) and 1 (right). The sound is repeated loops
+// times, or forever when loops is -1. When all channels are in use, the
+// channel which plays the oldest sound is reused.
+func (m *Mixer) PlayEx(s *Sound, volume, pan float64, loops int) (Channel, error) {
+	i := m.freeChannel()
+	mix.Volume(i, m.sfxVolume(volume))
+	if err := setPanning(i, pan); err != nil {
+		return Channel{}, err
+	}
+	if _, err := s.chunk.Play(i, loops); err != nil {
+		return Channel{}, errors.Trace(err)
+	}
+
+	m.lastID++
+	m.channels[i] = channelState{id: m.lastID, volume: volume}
+	return Channel{m: m, index: i, id: m.lastID}, nil
+}
+
+// freeChannel returns the index of a channel which is not playing. When all
+// channels are playing, the one which plays the oldest sound is halted.
+func (m *Mixer) freeChannel() int {
+	oldest := 0
+	for i, st := range m.channels {
+		if mix.Playing(i) == 0 {
+			return i
+		}
+		if st.id < m.channels[oldest].id {
+			oldest = i
+		}
+	}
+
+	mix.HaltChannel(oldest)
+	return oldest
+}
+
+// Channel is a sound which is played by a Mixer. It no longer affects its
+// channel once the channel is reused for another sound.
+type Channel struct {
+	m     *Mixer
+	index int
+	id    uint64
+}
+
+// valid indicates if the channel still plays the sound the Channel is created
+// for.
+func (c Channel) valid() bool {
+	return c.m != nil && c.m.channels[c.index].id == c.id && mix.Playing(c.index) != 0
+}
+
+// Index returns the index of the SDL_mixer channel.
+func (c Channel) Index() int { return c.index }
+
+// Playing indicates if the sound is still playing, or is paused.
+func (c Channel) Playing() bool { return c.valid() }
+
+func (c Channel) Stop() {
+	if c.valid() {
+		mix.HaltChannel(c.index)
+	}
+}
+
+// FadeOut fades out the sound during d, after which it is stopped.
+func (c Channel) FadeOut(d time.Duration) {
+	if c.valid() {
+		mix.FadeOutChannel(c.index, millis(d))
+	}
+}
+
+func (c Channel) Pause() {
+	if c.valid() {
+		mix.Pause(c.index)
+	}
+}
+
+func (c Channel) Resume() {
+	if c.valid() {
+		mix.Resume(c.index)
+	}
+}
+
+// SetVolume sets the volume of the sound, between 0 and 1.
+func (c Channel) SetVolume(v float64) {
+	if c.valid() {
+		c.m.channels[c.index].volume = v
+		mix.Volume(c.index, c.m.sfxVolume(v))
+	}
+}
+
+// SetPan sets the position of the sound between the speakers, from -1 (left)
+// to 1 (right).
+func (c Channel) SetPan(pan float64) error {
+	if !c.valid() {
+		return nil
+	}
+	return setPanning(c.index, pan)
+}
@@ -1,26 +1,94 @@
 package sdlkit
 
 import (
+	"math"
+
+	"github.com/veandco/go-sdl2/sdl"
+
 	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom"
 )
 
-// Convert world positions to screen positions
+// CameraSmoothing determines how a Camera moves towards the target it follows.
+type CameraSmoothing uint8
+
+const (
+	// SmoothNone moves the Camera directly to its target.
+	SmoothNone CameraSmoothing = iota
+	// SmoothLerp moves the Camera a fraction of the remaining distance
+	// towards its target each update.
+	SmoothLerp
+	// SmoothDamped moves the Camera like a critically damped spring, it
+	// accelerates and decelerates smoothly without overshooting its target.
+	SmoothDamped
+)
+
+// Camera converts world positions to screen positions. Its position is the
+// top left corner of the view, its size the size of the viewport on screen.
+// The view is zoomed and rotated around its center.
+//
+// Positions on screen are either logical, relative to the Stage's logical
+// size, or relative to the viewport of the Camera. See Stage for conversion
+// between window and logical positions.
 // https://gamedev.stackexchange.com/questions/121421/how-to-use-the-sdl-viewport-properly
 // https://www.youtube.com/watch?v=D1dw7L0nC6s
 type Camera struct {
-	i32 [4]int32   // x,y,w,h
-	f64 [4]float64 // x,y,w,h
+	Smoothing CameraSmoothing
+	// SmoothTime is the time in seconds it roughly takes the Camera to catch
+	// up with its target.
+	SmoothTime float64
+
+	// LookAhead moves the Camera ahead of its target, in the direction the
+	// target is moving. The distance is the target's velocity times LookAhead
+	// seconds, limited to MaxLookAhead pixels when it is not 0.
+	LookAhead    float64
+	MaxLookAhead float64
+
+	// ShakeOffset and ShakeAngle are the maximum offset in pixels and the
+	// maximum rotation in degrees of the Camera when its trauma is 1.
+	ShakeOffset float64
+	ShakeAngle  float64
+	// ShakeDecay is the amount of trauma which is removed each second.
+	ShakeDecay float64
+	// ShakeFrequency is the speed of the shake.
+	ShakeFrequency float64
+
+	center   [2]float64 // center of the view in world coordinates
+	size     [2]float64 // size of the viewport on screen
+	viewport [2]int32   // position of the viewport on screen
+	zoom     float64
+	rot      float64 // rotation in degrees
 
-	targetX  float64
-	targetY  float64
+	bounds    [4]float64 // x,y,w,h
+	hasBounds bool
+	deadzone  [2]float64 // w,h
+
+	target     geom.XYGetter
+	targetPrev [2]float64
+	goal       [2]float64
+	velocity   [2]float64
+	look       [2]float64
+
+	trauma    float64
+	shakeTime float64
+	shake     [3]float64 // x,y,deg
+
+	sin, cos float64 // of the total rotation, including shake
 	disabled bool
 }
 
 func NewCamera(x, y float64, w, h int32) *Camera {
-	var cam Camera
+	cam := Camera{
+		SmoothTime:     0.25,
+		ShakeOffset:    12,
+		ShakeAngle:     4,
+		ShakeDecay:     1,
+		ShakeFrequency: 20,
+		zoom:           1,
+		cos:            1,
+	}
+	cam.Resize(w, h)
 	cam.SetX(x)
 	cam.SetY(y)
-	cam.Resize(w, h)
 	return &cam
 }
 
@@ -31,60 +99,414 @@ func (c *Camera) IsEnabled() bool {
 	return c != nil && !c.disabled
 }
 
-func (c *Camera) Width() int32  { return c.i32[2] }
-func (c *Camera) Height() int32 { return c.i32[3] }
+// Viewport returns the area on screen the Camera renders to.
+func (c *Camera) Viewport() sdl.Rect {
+	return sdl.Rect{
+		X: c.viewport[0],
+		Y: c.viewport[1],
+		W: c.Width(),
+		H: c.Height(),
+	}
+}
+
+// SetViewport sets the area on screen the Camera renders to, when used with
+// Canvas.DrawCameras. The view is resized around its center, see Resize.
+func (c *Camera) SetViewport(rect sdl.Rect) {
+	c.viewport = [2]int32{rect.X, rect.Y}
+	c.Resize(rect.W, rect.H)
+}
+
+// Width returns the width of the viewport on screen.
+func (c *Camera) Width() int32 { return int32(c.size[0]) }
 
-func (c *Camera) GetX() float64 { return c.f64[0] }
-func (c *Camera) GetY() float64 { return c.f64[1] }
+// Height returns the height of the viewport on screen.
+func (c *Camera) Height() int32 { return int32(c.size[1]) }
+
+// viewSize returns the size of the view in world coordinates, without taking
+// rotation into account.
+func (c *Camera) viewSize() (float64, float64) {
+	return c.size[0] / c.zoom, c.size[1] / c.zoom
+}
+
+// GetX returns the x position of the left side of the view.
+func (c *Camera) GetX() float64 {
+	w, _ := c.viewSize()
+	return c.center[0] - w/2
+}
+
+// GetY returns the y position of the top side of the view.
+func (c *Camera) GetY() float64 {
+	_, h := c.viewSize()
+	return c.center[1] - h/2
+}
 
 func (c *Camera) SetX(x float64) {
-	if x < 0 {
-		x = 0
-	}
-	c.i32[0], c.f64[0] = int32(x), x
+	w, _ := c.viewSize()
+	c.CenterOn(x+w/2, c.center[1])
 }
 
 func (c *Camera) SetY(y float64) {
-	if y < 0 {
-		y = 0
+	_, h := c.viewSize()
+	c.CenterOn(c.center[0], y+h/2)
+}
+
+// Center returns the center of the view in world coordinates.
+func (c *Camera) Center() (float64, float64) { return c.center[0], c.center[1] }
+
+// CenterOn centers the view on x, y while keeping it within the bounds of
+// the Camera.
+func (c *Camera) CenterOn(x, y float64) {
+	c.center[0], c.center[1] = c.clamp(x, y)
+}
+
+// clamp returns x, y so the view stays within its bounds. Without bounds the
+// view is kept right and below 0, 0.
+func (c *Camera) clamp(x, y float64) (float64, float64) {
+	w, h := c.viewSize()
+	if !c.hasBounds {
+		return math.Max(x, w/2), math.Max(y, h/2)
+	}
+
+	return clampView(x, c.bounds[0], c.bounds[2], w),
+		clampView(y, c.bounds[1], c.bounds[3], h)
+}
+
+// clampView clamps center v of a view of size so it stays within min and
+// min+length. A view larger than length is centered.
+func clampView(v, min, length, size float64) float64 {
+	if size >= length {
+		return min + length/2
+	}
+	if v-size/2 < min {
+		return min + size/2
+	}
+	if v+size/2 > min+length {
+		return min + length - size/2
+	}
+	return v
+}
+
+// SetBounds limits the view to the area at x, y with size w x h. The
+// rotation of the Camera is not taken into account.
+func (c *Camera) SetBounds(x, y, w, h float64) {
+	c.bounds = [4]float64{x, y, w, h}
+	c.hasBounds = true
+	c.CenterOn(c.center[0], c.center[1])
+}
+
+// RemoveBounds removes the bounds, the view is only kept right and below
+// 0, 0.
+func (c *Camera) RemoveBounds() {
+	c.hasBounds = false
+	c.CenterOn(c.center[0], c.center[1])
+}
+
+// Bounds returns the bounds of the Camera and if they are set.
+func (c *Camera) Bounds() (geom.Rect, bool) {
+	return geom.Rect{
+		X: c.bounds[0],
+		Y: c.bounds[1],
+		W: c.bounds[2],
+		H: c.bounds[3],
+	}, c.hasBounds
+}
+
+// SetDeadzone sets the size of the area, around the center of the view, in
+// which the followed target can move without moving the Camera.
+func (c *Camera) SetDeadzone(w, h float64) { c.deadzone = [2]float64{w, h} }
+
+func (c *Camera) Deadzone() (float64, float64) { return c.deadzone[0], c.deadzone[1] }
+
+func (c *Camera) Zoom() float64 { return c.zoom }
+
+// SetZoom sets the zoom factor of the view. A factor of 2 shows everything
+// twice as large. Factors of 0 or less are ignored.
+func (c *Camera) SetZoom(zoom float64) {
+	if zoom <= 0 {
+		return
 	}
-	c.i32[1], c.f64[1] = int32(y), y
+
+	c.zoom = zoom
+	c.CenterOn(c.center[0], c.center[1])
+}
+
+// Rotation returns the rotation of the view in degrees.
+func (c *Camera) Rotation() float64 { return c.rot }
+
+// SetRotation rotates the view clockwise, around its center, by deg degrees.
+func (c *Camera) SetRotation(deg float64) {
+	c.rot = deg
+	c.updateRotation()
 }
 
+// angle returns the total rotation of the view in degrees, including shake.
+func (c *Camera) angle() float64 { return c.rot + c.shake[2] }
+
+func (c *Camera) updateRotation() {
+	c.sin, c.cos = math.Sincos(-c.angle() * math.Pi / 180)
+}
+
+func (c *Camera) isRotated() bool { return c.angle() != 0 }
+
+// Follow makes the Camera follow target on each Update. The view is directly
+// centered on the target. A nil target stops following.
 func (c *Camera) Follow(target geom.XYGetter) {
-	tx, ty := target.GetX(), target.GetY()
-	if tx-c.targetX > 0.5 || tx-c.targetX < -0.5 {
-		c.SetX(tx - (c.f64[2] / 2))
-		c.targetX = tx
+	c.target = target
+	c.velocity = [2]float64{}
+	c.look = [2]float64{}
+	if target == nil {
+		return
+	}
+
+	x, y := target.GetX(), target.GetY()
+	c.targetPrev = [2]float64{x, y}
+	c.goal = [2]float64{x, y}
+	c.CenterOn(x, y)
+}
+
+// Update moves the Camera towards the target it follows and updates the
+// shake.
+func (c *Camera) Update(dt float64) {
+	if c.target != nil {
+		c.follow(dt)
+	}
+	c.updateShake(dt)
+}
+
+func (c *Camera) follow(dt float64) {
+	tx, ty := c.target.GetX(), c.target.GetY()
+	if dt > 0 && c.LookAhead > 0 {
+		c.look[0] = lookAhead((tx-c.targetPrev[0])/dt*c.LookAhead, c.MaxLookAhead)
+		c.look[1] = lookAhead((ty-c.targetPrev[1])/dt*c.LookAhead, c.MaxLookAhead)
+	}
+	c.targetPrev = [2]float64{tx, ty}
+
+	// the goal only moves when the target leaves the deadzone around it
+	c.goal[0] = deadzone(c.goal[0], tx+c.look[0], c.deadzone[0]/2)
+	c.goal[1] = deadzone(c.goal[1], ty+c.look[1], c.deadzone[1]/2)
+
+	x, y := c.center[0], c.center[1]
+	switch {
+	case c.Smoothing == SmoothLerp && c.SmoothTime > 0:
+		t := 1 - math.Exp(-dt/c.SmoothTime)
+		x += (c.goal[0] - x) * t
+		y += (c.goal[1] - y) * t
+
+	case c.Smoothing == SmoothDamped && c.SmoothTime > 0:
+		x, c.velocity[0] = smoothDamp(x, c.goal[0], c.velocity[0], c.SmoothTime, dt)
+		y, c.velocity[1] = smoothDamp(y, c.goal[1], c.velocity[1], c.SmoothTime, dt)
+
+	default:
+		x, y = c.goal[0], c.goal[1]
+	}
+
+	c.CenterOn(x, y)
+}
+
+func lookAhead(v, max float64) float64 {
+	if max > 0 {
+		return math.Max(-max, math.Min(v, max))
+	}
+	return v
+}
+
+// deadzone moves goal towards target until target is within half of the
+// deadzone around goal.
+func deadzone(goal, target, half float64) float64 {
+	if d := target - goal; d > half {
+		return target - half
+	} else if d < -half {
+		return target + half
 	}
-	if ty-c.targetY > 0.5 || ty-c.targetY < -0.5 {
-		c.SetY(ty - (c.f64[3] / 2))
-		c.targetY = ty
+	return goal
+}
+
+// smoothDamp moves cur towards target like a critically damped spring. It
+// returns the new value and velocity.
+// Game Programming Gems 4, chapter 1.10
+func smoothDamp(cur, target, velocity, smoothTime, dt float64) (float64, float64) {
+	omega := 2 / smoothTime
+	x := omega * dt
+	exp := 1 / (1 + x + 0.48*x*x + 0.235*x*x*x)
+	change := cur - target
+	temp := (velocity + omega*change) * dt
+	return target + (change+temp)*exp, (velocity - omega*temp) * exp
+}
+
+// AddTrauma adds trauma to the Camera, which makes it shake. Trauma is
+// between 0 and 1 and decreases over time. The intensity of the shake is the
+// square of the trauma.
+func (c *Camera) AddTrauma(trauma float64) {
+	c.trauma = math.Max(0, math.Min(c.trauma+trauma, 1))
+}
+
+func (c *Camera) Trauma() float64 { return c.trauma }
+
+func (c *Camera) updateShake(dt float64) {
+	if c.trauma <= 0 {
+		if c.shake != [3]float64{} {
+			c.shake = [3]float64{}
+			c.updateRotation()
+		}
+		return
 	}
+
+	c.shakeTime += dt
+	t := c.shakeTime * c.ShakeFrequency
+	s := c.trauma * c.trauma
+
+	c.shake[0] = c.ShakeOffset * s * shakeNoise(1, t)
+	c.shake[1] = c.ShakeOffset * s * shakeNoise(2, t)
+	c.shake[2] = c.ShakeAngle * s * shakeNoise(3, t)
+	c.updateRotation()
+
+	c.trauma = math.Max(0, c.trauma-c.ShakeDecay*dt)
 }
 
+// shakeNoise returns a smooth pseudo random value between -1 and 1.
+func shakeNoise(seed, t float64) float64 {
+	return 0.5*math.Sin(t+seed*12.9898) +
+		0.3*math.Sin(t*2.17+seed*78.233) +
+		0.2*math.Sin(t*4.73+seed*37.719)
+}
+
+// Resize resizes the viewport while keeping the center of the view in place,
+// as far as its bounds allow, so a followed target remains in view. The top
+// left corner of the view moves when the size changes. SetViewport resizes
+// the same way.
 func (c *Camera) Resize(w, h int32) {
-	c.i32[2], c.f64[2] = w, float64(w)
-	c.i32[3], c.f64[3] = h, float64(h)
+	c.size = [2]float64{float64(w), float64(h)}
+	c.CenterOn(c.center[0], c.center[1])
+}
+
+// WorldToViewport converts world position x, y to a position relative to the
+// viewport.
+func (c *Camera) WorldToViewport(x, y float64) (float64, float64) {
+	x -= c.center[0] + c.shake[0]
+	y -= c.center[1] + c.shake[1]
+	if c.isRotated() {
+		x, y = x*c.cos-y*c.sin, x*c.sin+y*c.cos
+	}
+	return x*c.zoom + c.size[0]/2, y*c.zoom + c.size[1]/2
+}
+
+// ViewportToWorld converts position x, y, relative to the viewport, to a
+// position in the world.
+func (c *Camera) ViewportToWorld(x, y float64) (float64, float64) {
+	x = (x - c.size[0]/2) / c.zoom
+	y = (y - c.size[1]/2) / c.zoom
+	if c.isRotated() {
+		x, y = x*c.cos+y*c.sin, -x*c.sin+y*c.cos
+	}
+	return x + c.center[0] + c.shake[0], y + c.center[1] + c.shake[1]
+}
+
+// LogicalToViewport converts logical position x, y to a position relative to
+// the viewport.
+func (c *Camera) LogicalToViewport(x, y float64) (float64, float64) {
+	return x - float64(c.viewport[0]), y - float64(c.viewport[1])
+}
+
+// ViewportToLogical converts position x, y, relative to the viewport, to a
+// logical position.
+func (c *Camera) ViewportToLogical(x, y float64) (float64, float64) {
+	return x + float64(c.viewport[0]), y + float64(c.viewport[1])
+}
+
+// LogicalToWorld converts logical position x, y to a position in the world.
+func (c *Camera) LogicalToWorld(x, y float64) (float64, float64) {
+	return c.ViewportToWorld(c.LogicalToViewport(x, y))
+}
+
+// WorldToLogical converts world position x, y to a logical position.
+func (c *Camera) WorldToLogical(x, y float64) (float64, float64) {
+	return c.ViewportToLogical(c.WorldToViewport(x, y))
+}
+
+// InViewport indicates if logical position x, y is within the viewport of the
+// Camera.
+func (c *Camera) InViewport(x, y float64) bool {
+	return geom.Point{X: x, Y: y}.InRect(c.Viewport())
+}
+
+// View returns the area of the world which is visible to the Camera. When
+// the Camera is rotated, this is the bounding box of the view.
+func (c *Camera) View() sdl.Rect {
+	x0, y0 := math.Inf(1), math.Inf(1)
+	x1, y1 := math.Inf(-1), math.Inf(-1)
+	for _, pt := range [4][2]float64{{0, 0}, {c.size[0], 0}, {0, c.size[1]}, c.size} {
+		x, y := c.ViewportToWorld(pt[0], pt[1])
+		x0, y0 = math.Min(x0, x), math.Min(y0, y)
+		x1, y1 = math.Max(x1, x), math.Max(y1, y)
+	}
+
+	// ignore rounding errors of the rotation
+	const e = 1e-9
+	x0, y0 = math.Floor(x0+e), math.Floor(y0+e)
+	return sdl.Rect{
+		X: int32(x0),
+		Y: int32(y0),
+		W: int32(math.Ceil(x1 - x0 - e)),
+		H: int32(math.Ceil(y1 - y0 - e)),
+	}
+}
+
+func (c *Camera) screenPoint(x, y float64) (int32, int32) {
+	x, y = c.WorldToViewport(x, y)
+	return int32(math.Round(x)), int32(math.Round(y))
+}
+
+// screenRect converts rect to screen coordinates. Its corners are converted
+// separately, so adjacent rects stay adjacent when zoomed. Rotation is
+// ignored.
+func (c *Camera) screenRect(rect sdl.Rect) sdl.Rect {
+	x0, y0 := c.screenPoint(float64(rect.X), float64(rect.Y))
+	x1, y1 := c.screenPoint(float64(rect.X+rect.W), float64(rect.Y+rect.H))
+	return sdl.Rect{X: x0, Y: y0, W: x1 - x0, H: y1 - y0}
 }
 
+// screenTexture converts the destination of a texture, which is rotated deg
+// degrees around origin, to screen coordinates.
+func (c *Camera) screenTexture(dest sdl.Rect, deg float64, origin sdl.Point) (sdl.Rect, float64, sdl.Point) {
+	if !c.isRotated() {
+		scaled := c.screenRect(dest)
+		origin.X = int32(math.Round(float64(origin.X) * c.zoom))
+		origin.Y = int32(math.Round(float64(origin.Y) * c.zoom))
+		return scaled, deg, origin
+	}
+
+	ox, oy := c.screenPoint(float64(dest.X+origin.X), float64(dest.Y+origin.Y))
+	origin.X = int32(math.Round(float64(origin.X) * c.zoom))
+	origin.Y = int32(math.Round(float64(origin.Y) * c.zoom))
+	return sdl.Rect{
+		X: ox - origin.X,
+		Y: oy - origin.Y,
+		W: int32(math.Round(float64(dest.W) * c.zoom)),
+		H: int32(math.Round(float64(dest.H) * c.zoom)),
+	}, deg - c.angle(), origin
+}
+
+// scale returns length v on screen.
+func (c *Camera) scale(v int32) int32 {
+	return int32(math.Round(float64(v) * c.zoom))
+}
+
+// TranslateX translates world position x to the screen, ignoring zoom and
+// rotation.
 func (c *Camera) TranslateX(x int32) int32 {
-	return x - c.i32[0]
+	return x - int32(c.GetX()+c.shake[0])
 }
 
 func (c *Camera) TranslateXF(x float64) int32 {
-	return int32(x - c.f64[0])
+	return int32(x - c.GetX() - c.shake[0])
 }
 
+// TranslateY translates world position y to the screen, ignoring zoom and
+// rotation.
 func (c *Camera) TranslateY(y int32) int32 {
-	return y - c.i32[1]
+	return y - int32(c.GetY()+c.shake[1])
 }
 
 func (c *Camera) TranslateYF(y float64) int32 {
-	return int32(y - c.f64[1])
+	return int32(y - c.GetY() - c.shake[1])
 }
-
-// - camera + viewport toevoegen
-// - ook ZOOM kunnen regelen
-// - alle Draw() methods aanpassen zodat de nieuwe renderer gebruikt word
-// - camera shake
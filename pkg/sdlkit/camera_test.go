@@ -0,0 +1,153 @@
+package sdlkit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom"
+)
+
+func TestCamera_bounds(t *testing.T) {
+	cam := NewCamera(20, 40, 200, 100)
+	assert.Equal(t, 20.0, cam.GetX())
+	assert.Equal(t, 40.0, cam.GetY())
+
+	cam.SetX(-5)
+	assert.Equal(t, 0.0, cam.GetX())
+
+	cam.SetBounds(0, 0, 1000, 500)
+	cam.SetX(900)
+	cam.SetY(-10)
+	assert.Equal(t, 800.0, cam.GetX())
+	assert.Equal(t, 0.0, cam.GetY())
+
+	// a view larger than its bounds is centered
+	cam.SetBounds(0, 0, 100, 500)
+	assert.Equal(t, -50.0, cam.GetX())
+}
+
+func TestCamera_WorldToViewport(t *testing.T) {
+	cam := NewCamera(20, 40, 200, 100)
+	cam.SetZoom(2)
+
+	x, y := cam.Center()
+	assert.Equal(t, [2]float64{120, 90}, [2]float64{x, y})
+	assert.Equal(t, [2]float64{70, 65}, [2]float64{cam.GetX(), cam.GetY()})
+	assert.Equal(t, sdl.Rect{X: 70, Y: 65, W: 100, H: 50}, cam.View())
+
+	x, y = cam.WorldToViewport(130, 90)
+	assert.Equal(t, [2]float64{120, 50}, [2]float64{x, y})
+
+	cam.SetZoom(1)
+	cam.SetRotation(90)
+	x, y = cam.WorldToViewport(130, 90)
+	assert.InDelta(t, 100, x, 1e-9)
+	assert.InDelta(t, 40, y, 1e-9)
+
+	x, y = cam.ViewportToWorld(x, y)
+	assert.InDelta(t, 130, x, 1e-9)
+	assert.InDelta(t, 90, y, 1e-9)
+
+	view := cam.View()
+	assert.Equal(t, [2]int32{100, 200}, [2]int32{view.W, view.H})
+}
+
+func TestCamera_Follow(t *testing.T) {
+	target := &geom.Point{X: 500, Y: 250}
+	cam := NewCamera(0, 0, 200, 100)
+	cam.SetDeadzone(40, 20)
+	cam.Follow(target)
+
+	x, y := cam.Center()
+	assert.Equal(t, [2]float64{500, 250}, [2]float64{x, y})
+
+	// within the deadzone
+	target.X = 515
+	cam.Update(0.1)
+	x, _ = cam.Center()
+	assert.Equal(t, 500.0, x)
+
+	target.X = 530
+	cam.Update(0.1)
+	x, _ = cam.Center()
+	assert.Equal(t, 510.0, x)
+
+	t.Run("look ahead", func(t *testing.T) {
+		cam.SetDeadzone(0, 0)
+		cam.LookAhead = 0.5
+		cam.MaxLookAhead = 20
+
+		target.X += 6
+		cam.Update(0.1)
+		x, _ = cam.Center()
+		assert.Equal(t, target.X+20, x)
+	})
+}
+
+func TestCamera_smoothing(t *testing.T) {
+	for _, smoothing := range []CameraSmoothing{SmoothLerp, SmoothDamped} {
+		target := &geom.Point{X: 500, Y: 250}
+		cam := NewCamera(0, 0, 200, 100)
+		cam.Smoothing = smoothing
+		cam.Follow(target)
+
+		target.X = 600
+		prev, _ := cam.Center()
+		for i := 0; i < 120; i++ {
+			cam.Update(1.0 / 60)
+
+			x, _ := cam.Center()
+			assert.GreaterOrEqual(t, x, prev)
+			assert.LessOrEqual(t, x, 600.0)
+			prev = x
+		}
+		assert.InDelta(t, 600, prev, 1)
+	}
+}
+
+func TestCamera_AddTrauma(t *testing.T) {
+	cam := NewCamera(0, 0, 200, 100)
+	cam.AddTrauma(0.5)
+	cam.AddTrauma(0.7)
+	assert.Equal(t, 1.0, cam.Trauma())
+
+	cam.Update(0.1)
+	assert.InDelta(t, 0.9, cam.Trauma(), 1e-9)
+	assert.NotEqual(t, [3]float64{}, cam.shake)
+
+	cam.Update(1)
+	cam.Update(0.1)
+	assert.Equal(t, 0.0, cam.Trauma())
+
+	x, y := cam.WorldToViewport(0, 0)
+	assert.Equal(t, [2]float64{0, 0}, [2]float64{x, y})
+}
+
+func TestCamera_LogicalToWorld(t *testing.T) {
+	cam := NewCamera(0, 0, 100, 100)
+	cam.SetViewport(sdl.Rect{X: 100, Y: 20, W: 100, H: 100})
+	cam.SetX(500)
+	cam.SetZoom(2)
+
+	assert.True(t, cam.InViewport(150, 70))
+	assert.False(t, cam.InViewport(50, 70))
+
+	// center of the viewport
+	x, y := cam.LogicalToWorld(150, 70)
+	assert.Equal(t, [2]float64{550, 50}, [2]float64{x, y})
+
+	x, y = cam.WorldToLogical(560, 40)
+	assert.Equal(t, [2]float64{170, 50}, [2]float64{x, y})
+}
+
+func TestCamera_Resize(t *testing.T) {
+	cam := NewCamera(100, 100, 100, 100)
+	cam.Resize(200, 50)
+
+	// the center of the view is kept in place
+	assert.Equal(t, [2]float64{50, 125}, [2]float64{cam.GetX(), cam.GetY()})
+	x, y := cam.WorldToViewport(150, 150)
+	assert.Equal(t, [2]float64{100, 25}, [2]float64{x, y})
+}
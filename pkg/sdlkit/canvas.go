@@ -20,6 +20,16 @@ type DrawableFunc func(canvas *Canvas)
 
 func (fn DrawableFunc) Draw(canvas *Canvas) { fn(canvas) }
 
+func (fn DrawableFunc) Render(ren *sdl.Renderer) error { return RenderDrawable(ren, fn) }
+
+// RenderDrawable draws d onto a new Canvas of ren, without a Camera. It can be
+// used to implement Renderable for a Drawable.
+func RenderDrawable(ren *sdl.Renderer, d Drawable) error {
+	c := NewCanvas(ren)
+	d.Draw(c)
+	return c.Done()
+}
+
 type Canvas struct {
 	engine  *sdl.Renderer
 	camera  *Camera
@@ -52,6 +62,10 @@ func (c *Canvas) catchErr(err ...error) {
 	c.errors = append(c.errors, err...)
 }
 
+// CatchErr collects errors which occur while drawing. They are returned as a
+// combined error on the next call to Done.
+func (c *Canvas) CatchErr(err ...error) { c.catchErr(err...) }
+
 func (c *Canvas) Renderer() *sdl.Renderer { return c.engine }
 
 func (c *Canvas) Render(r Renderable) { c.catchErr(r.Render(c.engine)) }
@@ -60,23 +74,60 @@ func (c *Canvas) Camera() *Camera { return c.camera }
 
 func (c *Canvas) SetCamera(cam *Camera) { c.camera = cam }
 
+// DrawCameras draws d once for each enabled Camera. Each pass is drawn
+// relative to, and clipped by, the viewport of the Camera. The Canvas' own
+// Camera and viewport are restored afterwards.
+func (c *Canvas) DrawCameras(d Drawable, cams ...*Camera) {
+	prev, viewport := c.camera, c.engine.GetViewport()
+	for _, cam := range cams {
+		if !cam.IsEnabled() {
+			continue
+		}
+
+		rect := cam.Viewport()
+		if err := c.engine.SetViewport(&rect); err != nil {
+			c.catchErr(err)
+			continue
+		}
+
+		c.camera = cam
+		d.Draw(c)
+	}
+
+	c.camera = prev
+	c.catchErr(c.engine.SetViewport(&viewport))
+}
+
 func (c *Canvas) CreateTexture(format uint32, access int, w, h int32) (*sdl.Texture, error) {
 	tx, err := c.engine.CreateTexture(format, access|sdl.TEXTUREACCESS_TARGET, w, h)
 	if err != nil {
 		return nil, err
 	}
 
+	if err = c.SetTarget(tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// SetTarget sets the provided sdl.Texture as render target of the Canvas. The
+// texture must be created with sdl.TEXTUREACCESS_TARGET. All following draw
+// calls render to this texture until Done is called, which restores the
+// previous render target.
+func (c *Canvas) SetTarget(tx *sdl.Texture) error {
 	c.target = c.engine.GetRenderTarget()
-	if err = c.engine.SetRenderTarget(tx); err != nil {
+	if err := c.engine.SetRenderTarget(tx); err != nil {
 		c.target = nil
-		return nil, err
+		return err
 	}
-	if err = tx.SetBlendMode(c.blendMode); err != nil {
-		return nil, err
+	if err := tx.SetBlendMode(c.blendMode); err != nil {
+		_ = c.engine.SetRenderTarget(c.target)
+		c.target = nil
+		return err
 	}
 
 	c.texture = tx
-	return tx, nil
+	return nil
 }
 
 func (c *Canvas) CreateTextureClip(format uint32, access int, w, h int32) (TextureClip, error) {
@@ -89,6 +140,8 @@ func (c *Canvas) CreateTextureClip(format uint32, access int, w, h int32) (Textu
 
 func (c *Canvas) SetDrawAntiAlias(aa bool) { c.antiAlias = aa }
 
+func (c *Canvas) DrawBlendMode() sdl.BlendMode { return c.blendMode }
+
 func (c *Canvas) SetDrawBlendMode(mode sdl.BlendMode) {
 	if err := c.engine.SetDrawBlendMode(mode); err != nil {
 		c.catchErr(err)
@@ -156,9 +209,9 @@ func (c *Canvas) EndLineStyle() { c.line = false }
 func (c *Canvas) Draw(d Drawable) { d.Draw(c) }
 
 func (c *Canvas) DrawPixel(x, y, size int32) {
-	if c.camera != nil && !c.camera.disabled {
-		x = c.camera.TranslateX(x)
-		y = c.camera.TranslateY(y)
+	if c.camera.IsEnabled() {
+		x, y = c.camera.screenPoint(float64(x), float64(y))
+		size = c.camera.scale(size)
 	}
 
 	if size < 2 {
@@ -197,11 +250,9 @@ func (c *Canvas) DrawLine(x1, y1, x2, y2 int32) {
 		return
 	}
 
-	if c.camera != nil && !c.camera.disabled {
-		x1 = c.camera.TranslateX(x1)
-		y1 = c.camera.TranslateY(y1)
-		x2 = c.camera.TranslateX(x2)
-		y2 = c.camera.TranslateY(y2)
+	if c.camera.IsEnabled() {
+		x1, y1 = c.camera.screenPoint(float64(x1), float64(y1))
+		x2, y2 = c.camera.screenPoint(float64(x2), float64(y2))
 	}
 
 	if c.lineStyle[0] > 1 {
@@ -218,9 +269,9 @@ func (c *Canvas) DrawLineF(x1, y1, x2, y2 float64) {
 }
 
 func (c *Canvas) DrawEllipse(x, y, radX, radY int32) {
-	if c.camera != nil && !c.camera.disabled {
-		x = c.camera.TranslateX(x)
-		y = c.camera.TranslateY(y)
+	if c.camera.IsEnabled() {
+		x, y = c.camera.screenPoint(float64(x), float64(y))
+		radX, radY = c.camera.scale(radX), c.camera.scale(radY)
 	}
 
 	if c.fill {
@@ -252,9 +303,12 @@ func (c *Canvas) DrawRectF(x, y, w, h float64) {
 }
 
 func (c *Canvas) DrawSdlRect(rect sdl.Rect) {
-	if c.camera != nil && !c.camera.disabled {
-		rect.X = c.camera.TranslateX(rect.X)
-		rect.Y = c.camera.TranslateY(rect.Y)
+	if c.camera.IsEnabled() {
+		if c.camera.isRotated() {
+			c.drawRotatedRect(float64(rect.X), float64(rect.Y), float64(rect.W), float64(rect.H))
+			return
+		}
+		rect = c.camera.screenRect(rect)
 	}
 
 	if c.fill {
@@ -272,9 +326,15 @@ func (c *Canvas) DrawSdlRect(rect sdl.Rect) {
 }
 
 func (c *Canvas) DrawSdlFRect(rect sdl.FRect) {
-	if c.camera != nil && !c.camera.disabled {
-		rect.X += float32(c.camera.TranslateXF(0))
-		rect.Y += float32(c.camera.TranslateYF(0))
+	if c.camera.IsEnabled() {
+		if c.camera.isRotated() {
+			c.drawRotatedRect(float64(rect.X), float64(rect.Y), float64(rect.W), float64(rect.H))
+			return
+		}
+
+		x0, y0 := c.camera.WorldToViewport(float64(rect.X), float64(rect.Y))
+		x1, y1 := c.camera.WorldToViewport(float64(rect.X+rect.W), float64(rect.Y+rect.H))
+		rect = sdl.FRect{X: float32(x0), Y: float32(y0), W: float32(x1 - x0), H: float32(y1 - y0)}
 	}
 
 	if c.fill {
@@ -291,10 +351,25 @@ func (c *Canvas) DrawSdlFRect(rect sdl.FRect) {
 	}
 }
 
+// drawRotatedRect draws a rect in world coordinates as a polygon, so it
+// rotates along with the Camera.
+func (c *Canvas) drawRotatedRect(x, y, w, h float64) {
+	vx, vy := make([]int16, 4), make([]int16, 4)
+	for i, pt := range [4][2]float64{{x, y}, {x + w, y}, {x + w, y + h}, {x, y + h}} {
+		sx, sy := c.camera.screenPoint(pt[0], pt[1])
+		vx[i], vy[i] = int16(sx), int16(sy)
+	}
+
+	c.drawPolygon(vx, vy)
+}
+
+// DrawRoundRect draws a rect with rounded corners. It is zoomed but not
+// rotated by the Camera.
 func (c *Canvas) DrawRoundRect(x, y, w, h, rad int32) {
-	if c.camera != nil && !c.camera.disabled {
-		x = c.camera.TranslateX(x)
-		y = c.camera.TranslateY(y)
+	if c.camera.IsEnabled() {
+		r := c.camera.screenRect(sdl.Rect{X: x, Y: y, W: w, H: h})
+		x, y, w, h = r.X, r.Y, r.W, r.H
+		rad = c.camera.scale(rad)
 	}
 
 	x2, y2 := x+w, y+h
@@ -311,14 +386,17 @@ func (c *Canvas) DrawRoundRectF(x, y, w, h, rad float64) {
 }
 
 func (c *Canvas) DrawPolygon(vx, vy []int16) {
-	if c.camera != nil && !c.camera.disabled {
-		tx, ty := int16(c.camera.TranslateX(0)), int16(c.camera.TranslateY(0))
+	if c.camera.IsEnabled() {
 		for i := 0; i < len(vx); i++ {
-			vx[i] += tx
-			vy[i] += ty
+			x, y := c.camera.screenPoint(float64(vx[i]), float64(vy[i]))
+			vx[i], vy[i] = int16(x), int16(y)
 		}
 	}
 
+	c.drawPolygon(vx, vy)
+}
+
+func (c *Canvas) drawPolygon(vx, vy []int16) {
 	if c.fill {
 		sdlgfx.FilledPolygonColor(c.engine, vx, vy, c.fillColor)
 	}
@@ -330,9 +408,12 @@ func (c *Canvas) DrawPolygon(vx, vy []int16) {
 }
 
 func (c *Canvas) DrawTexture(tx *sdl.Texture, src *sdl.Rect, dest sdl.Rect) {
-	if c.camera != nil && !c.camera.disabled {
-		dest.X = c.camera.TranslateX(dest.X)
-		dest.Y = c.camera.TranslateY(dest.Y)
+	if c.camera.IsEnabled() {
+		if c.camera.isRotated() {
+			c.DrawTextureEx(tx, src, dest, 0, sdl.Point{X: dest.W / 2, Y: dest.H / 2}, sdl.FLIP_NONE)
+			return
+		}
+		dest = c.camera.screenRect(dest)
 	}
 
 	c.catchErr(c.engine.Copy(tx, src, &dest))
@@ -343,19 +424,26 @@ func (c *Canvas) DrawTexture(tx *sdl.Texture, src *sdl.Rect, dest sdl.Rect) {
 }
 
 func (c *Canvas) DrawTextureEx(tx *sdl.Texture, src *sdl.Rect, dest sdl.Rect, deg float64, origin sdl.Point, flip sdl.RendererFlip) {
-	if c.camera != nil && !c.camera.disabled {
-		dest.X = c.camera.TranslateX(dest.X)
-		dest.Y = c.camera.TranslateY(dest.Y)
+	if c.camera.IsEnabled() {
+		dest, deg, origin = c.camera.screenTexture(dest, deg, origin)
 	}
 
 	c.catchErr(c.engine.CopyEx(tx, src, &dest, deg, &origin, flip))
 }
 
+// DrawTextureClip draws the TextureClip's original frame within dest. Trimmed
+// and rotated frames are drawn at their offset and original orientation.
 func (c *Canvas) DrawTextureClip(clip TextureClip, dest sdl.Rect) {
+	if clip.IsTrimmed() || clip.IsRotated() {
+		c.DrawTextureClipEx(clip, dest, 0, sdl.Point{X: dest.W / 2, Y: dest.H / 2}, sdl.FLIP_NONE)
+		return
+	}
+
 	c.DrawTexture(clip.Texture, &clip.Location, dest)
 }
 
 func (c *Canvas) DrawTextureClipEx(clip TextureClip, dest sdl.Rect, deg float64, origin sdl.Point, flip sdl.RendererFlip) {
+	dest, deg, origin, flip = clip.transform(dest, deg, origin, flip)
 	c.DrawTextureEx(clip.Texture, &clip.Location, dest, deg, origin, flip)
 }
 
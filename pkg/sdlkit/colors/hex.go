@@ -0,0 +1,38 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package colors
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/go-pogo/errors"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// ParseHex parses a hexadecimal color notation in the form of `#rrggbb` or
+// `#rrggbbaa`. The leading `#` is optional. The alpha value defaults to 0xff
+// when it is omitted.
+func ParseHex(s string) (sdl.Color, error) {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) != 6 && len(s) != 8 {
+		return sdl.Color{}, errors.Newf("colors: invalid hex color `%s`", s)
+	}
+
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return sdl.Color{}, errors.Trace(err)
+	}
+	if len(s) == 6 {
+		v = v<<8 | 0xff
+	}
+
+	return sdl.Color{
+		R: uint8(v >> 24),
+		G: uint8(v >> 16),
+		B: uint8(v >> 8),
+		A: uint8(v),
+	}, nil
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package display
+
+import (
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom"
+)
+
+// WorldDrawable is a display object which can be added to a Container. It is
+// drawn using the world transform Matrix of its parent.
+type WorldDrawable interface {
+	DrawWorld(canvas *sdlkit.Canvas, parent geom.Matrix)
+}
+
+// Container is a node within a scene graph. It holds child display objects
+// which are positioned, rotated and scaled relative to the Container. Its
+// world transform is calculated from the chain of parent Containers and is
+// only recalculated when the Container or any of its parents has changed.
+type Container struct {
+	x, y     float64
+	rotation float64 // in radians
+	scaleX   float64
+	scaleY   float64
+	origin   geom.Point
+
+	parent   *Container
+	children []WorldDrawable
+
+	local, world           geom.Matrix
+	localDirty, worldDirty bool
+}
+
+func NewContainer(children ...WorldDrawable) *Container {
+	c := &Container{
+		scaleX:     1,
+		scaleY:     1,
+		localDirty: true,
+		worldDirty: true,
+	}
+	c.AddChild(children...)
+	return c
+}
+
+func (c *Container) GetX() float64 { return c.x }
+func (c *Container) GetY() float64 { return c.y }
+
+func (c *Container) SetX(x float64) {
+	c.x = x
+	c.invalidateLocal()
+}
+
+func (c *Container) SetY(y float64) {
+	c.y = y
+	c.invalidateLocal()
+}
+
+func (c *Container) SetPosition(x, y float64) {
+	c.x, c.y = x, y
+	c.invalidateLocal()
+}
+
+// Rotation returns the local rotation of the Container in radians.
+func (c *Container) Rotation() float64 { return c.rotation }
+
+// SetRotation sets the local rotation of the Container in radians. The
+// Container rotates around its origin.
+func (c *Container) SetRotation(rad float64) {
+	c.rotation = rad
+	c.invalidateLocal()
+}
+
+func (c *Container) Scale() (float64, float64) { return c.scaleX, c.scaleY }
+
+func (c *Container) SetScale(x, y float64) {
+	c.scaleX, c.scaleY = x, y
+	c.invalidateLocal()
+}
+
+// Origin returns the point, in local coordinates, the Container is rotated
+// and scaled around. This point is placed at the Container's position.
+func (c *Container) Origin() geom.Point { return c.origin }
+
+func (c *Container) SetOrigin(x, y float64) {
+	c.origin = geom.Point{X: x, Y: y}
+	c.invalidateLocal()
+}
+
+func (c *Container) Parent() *Container { return c.parent }
+
+func (c *Container) Children() []WorldDrawable { return c.children }
+
+// AddChild appends the children to the Container, they are drawn in order.
+// Child Containers are removed from their previous parent.
+func (c *Container) AddChild(children ...WorldDrawable) {
+	for _, child := range children {
+		if cc, ok := child.(*Container); ok {
+			if cc.parent != nil {
+				cc.parent.RemoveChild(cc)
+			}
+			cc.parent = c
+			cc.invalidateWorld()
+		}
+	}
+	c.children = append(c.children, children...)
+}
+
+// RemoveChild removes the child from the Container. It returns false when
+// child is not a child of the Container.
+func (c *Container) RemoveChild(child WorldDrawable) bool {
+	for i, ch := range c.children {
+		if ch != child {
+			continue
+		}
+
+		c.children = append(c.children[:i], c.children[i+1:]...)
+		if cc, ok := child.(*Container); ok {
+			cc.parent = nil
+			cc.invalidateWorld()
+		}
+		return true
+	}
+	return false
+}
+
+func (c *Container) invalidateLocal() {
+	c.localDirty = true
+	c.invalidateWorld()
+}
+
+// invalidateWorld marks the world transform of the Container and all of its
+// child Containers dirty. When a Container is already dirty, so are its
+// children.
+func (c *Container) invalidateWorld() {
+	if c.worldDirty {
+		return
+	}
+
+	c.worldDirty = true
+	for _, child := range c.children {
+		if cc, ok := child.(*Container); ok {
+			cc.invalidateWorld()
+		}
+	}
+}
+
+// LocalMatrix returns the transform Matrix of the Container relative to its
+// parent.
+func (c *Container) LocalMatrix() geom.Matrix {
+	if c.localDirty {
+		c.local = geom.TranslationMatrix(c.x, c.y).
+			Multiply(geom.RotationMatrix(c.rotation)).
+			Multiply(geom.ScaleMatrix(c.scaleX, c.scaleY)).
+			Multiply(geom.TranslationMatrix(-c.origin.X, -c.origin.Y))
+		c.localDirty = false
+	}
+	return c.local
+}
+
+// WorldMatrix returns the transform Matrix which converts the Container's
+// local coordinates to world coordinates.
+func (c *Container) WorldMatrix() geom.Matrix {
+	if c.worldDirty {
+		if c.parent == nil {
+			c.world = c.LocalMatrix()
+		} else {
+			c.world = c.parent.WorldMatrix().Multiply(c.LocalMatrix())
+		}
+		c.worldDirty = false
+	}
+	return c.world
+}
+
+// LocalToWorld converts a point within the Container's local coordinate
+// space to world coordinates.
+func (c *Container) LocalToWorld(pt geom.Point) geom.Point {
+	x, y := c.WorldMatrix().TransformXY(pt.X, pt.Y)
+	return geom.Point{X: x, Y: y}
+}
+
+// WorldToLocal converts a point in world coordinates to the Container's local
+// coordinate space.
+func (c *Container) WorldToLocal(pt geom.Point) geom.Point {
+	inv, ok := c.WorldMatrix().Invert()
+	if !ok {
+		// the Container is scaled to 0, any point is at its origin
+		return c.origin
+	}
+
+	x, y := inv.TransformXY(pt.X, pt.Y)
+	return geom.Point{X: x, Y: y}
+}
+
+// Draw draws all children in order.
+func (c *Container) Draw(canvas *sdlkit.Canvas) {
+	world := c.WorldMatrix()
+	for _, child := range c.children {
+		child.DrawWorld(canvas, world)
+	}
+}
+
+// DrawWorld draws all children in order. The parent Matrix is ignored,
+// because the Container already keeps track of its parent's world transform.
+func (c *Container) DrawWorld(canvas *sdlkit.Canvas, _ geom.Matrix) { c.Draw(canvas) }
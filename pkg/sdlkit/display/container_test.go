@@ -0,0 +1,125 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package display
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom"
+)
+
+func assertPoint(t *testing.T, want, have geom.Point) {
+	assert.InDelta(t, want.X, have.X, 1e-9, "x")
+	assert.InDelta(t, want.Y, have.Y, 1e-9, "y")
+}
+
+func TestContainer_LocalToWorld(t *testing.T) {
+	tank := NewContainer()
+	tank.SetPosition(100, 50)
+
+	turret := NewContainer()
+	turret.SetPosition(10, 0)
+	tank.AddChild(turret)
+
+	assertPoint(t, geom.Point{X: 115, Y: 50}, turret.LocalToWorld(geom.Point{X: 5}))
+
+	// rotating the parent moves the child
+	tank.SetRotation(math.Pi / 2)
+	assertPoint(t, geom.Point{X: 100, Y: 65}, turret.LocalToWorld(geom.Point{X: 5}))
+
+	// the child rotates around its own origin, on top of its parent's rotation
+	turret.SetRotation(math.Pi / 2)
+	assertPoint(t, geom.Point{X: 95, Y: 60}, turret.LocalToWorld(geom.Point{X: 5}))
+
+	tank.SetScale(2, 2)
+	assertPoint(t, geom.Point{X: 90, Y: 70}, turret.LocalToWorld(geom.Point{X: 5}))
+}
+
+func TestContainer_WorldToLocal(t *testing.T) {
+	parent := NewContainer()
+	parent.SetPosition(30, 40)
+	parent.SetRotation(0.7)
+	parent.SetScale(1.5, 0.5)
+	parent.SetOrigin(4, 2)
+
+	child := NewContainer()
+	child.SetPosition(-3, 9)
+	child.SetRotation(-1.2)
+	parent.AddChild(child)
+
+	pt := geom.Point{X: 12, Y: -7}
+	assertPoint(t, pt, child.WorldToLocal(child.LocalToWorld(pt)))
+
+	parent.SetScale(0, 1)
+	assert.Equal(t, child.Origin(), child.WorldToLocal(pt))
+}
+
+func TestContainer_Origin(t *testing.T) {
+	c := NewContainer()
+	c.SetPosition(50, 50)
+	c.SetOrigin(10, 10)
+
+	assertPoint(t, geom.Point{X: 50, Y: 50}, c.LocalToWorld(geom.Point{X: 10, Y: 10}))
+
+	c.SetRotation(math.Pi)
+	assertPoint(t, geom.Point{X: 50, Y: 50}, c.LocalToWorld(geom.Point{X: 10, Y: 10}))
+	assertPoint(t, geom.Point{X: 60, Y: 60}, c.LocalToWorld(geom.Point{}))
+}
+
+func TestContainer_Dirty(t *testing.T) {
+	root := NewContainer()
+	mid := NewContainer()
+	leaf := NewContainer()
+	root.AddChild(mid)
+	mid.AddChild(leaf)
+
+	leaf.WorldMatrix()
+	assert.False(t, root.worldDirty)
+	assert.False(t, mid.worldDirty)
+	assert.False(t, leaf.worldDirty)
+
+	root.SetX(10)
+	assert.True(t, root.localDirty)
+	assert.True(t, mid.worldDirty)
+	assert.True(t, leaf.worldDirty)
+	assert.False(t, mid.localDirty)
+	assertPoint(t, geom.Point{X: 10}, leaf.LocalToWorld(geom.Point{}))
+
+	// moving a child to another parent updates its world transform
+	other := NewContainer()
+	other.SetPosition(0, 20)
+	other.AddChild(leaf)
+	assert.Len(t, mid.Children(), 0)
+	assert.Same(t, other, leaf.Parent())
+	assertPoint(t, geom.Point{Y: 20}, leaf.LocalToWorld(geom.Point{}))
+
+	assert.True(t, other.RemoveChild(leaf))
+	assert.False(t, other.RemoveChild(leaf))
+	assert.Nil(t, leaf.Parent())
+	assertPoint(t, geom.Point{}, leaf.LocalToWorld(geom.Point{}))
+}
+
+type drawOrder []int
+
+func (o *drawOrder) child(i int) WorldDrawable {
+	return worldDrawableFunc(func(_ *sdlkit.Canvas, _ geom.Matrix) { *o = append(*o, i) })
+}
+
+type worldDrawableFunc func(canvas *sdlkit.Canvas, parent geom.Matrix)
+
+func (fn worldDrawableFunc) DrawWorld(canvas *sdlkit.Canvas, parent geom.Matrix) { fn(canvas, parent) }
+
+func TestContainer_Draw(t *testing.T) {
+	var order drawOrder
+	c := NewContainer(order.child(1), order.child(2))
+	c.AddChild(NewContainer(order.child(3)), order.child(4))
+
+	c.Draw(nil)
+	assert.Equal(t, drawOrder{1, 2, 3, 4}, order)
+}
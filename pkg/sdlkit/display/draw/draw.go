@@ -54,25 +54,16 @@ func Rect(rs geom.Rect) sdlkit.DrawableFunc {
 
 func Polygon(ps geom.PolygonShape) sdlkit.DrawableFunc {
 	return func(canvas *sdlkit.Canvas) {
-		var tx, ty int16
-
-		camera := canvas.Camera()
-		if camera.IsEnabled() {
-			tx = int16(camera.TranslateX(0))
-			ty = int16(camera.TranslateY(0))
-		}
-
 		vertices := ps.Vertices()
 		vx := make([]int16, len(vertices))
 		vy := make([]int16, len(vertices))
 
 		for i, pt := range vertices {
-			vx[i] = int16(pt.X-1) + tx
-			vy[i] = int16(pt.Y-1) + ty
+			vx[i] = int16(pt.X - 1)
+			vy[i] = int16(pt.Y - 1)
 		}
 
-		canvas.Camera().Disable()
+		// the Canvas converts the vertices to screen coordinates
 		canvas.DrawPolygon(vx, vy)
-		canvas.Camera().Enable()
 	}
 }
@@ -0,0 +1,175 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package display
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
+)
+
+// NineSlice divides a TextureClip in nine parts using Insets. When resized,
+// the corners keep their original size, the edges are stretched or tiled
+// along one axis and the center is stretched or tiled along both axis. This
+// makes it suitable for scalable UI elements like buttons and panels.
+type NineSlice struct {
+	X, Y,
+	W, H float64
+
+	// EdgeMode determines if the edges are stretched (StretchFit) or tiled
+	// (StretchTile).
+	EdgeMode StretchMode
+	// CenterMode determines if the center is stretched (StretchFit) or tiled
+	// (StretchTile).
+	CenterMode StretchMode
+
+	clip   sdlkit.TextureClip
+	insets sdlkit.Insets
+	minW   float64
+	minH   float64
+}
+
+// NewNineSlice creates a NineSlice with the original size of clip. A trimmed
+// or rotated clip can not be sliced, drawing it adds an error to the Canvas.
+func NewNineSlice(clip sdlkit.TextureClip, insets sdlkit.Insets) *NineSlice {
+	w, h := clip.Size()
+	return &NineSlice{
+		W:      w,
+		H:      h,
+		clip:   clip,
+		insets: insets,
+	}
+}
+
+// NewNineSliceFromAtlas creates a NineSlice from the location with name
+// within the TextureAtlas, using the Insets set on the TextureAtlas. It
+// returns an error when the location is trimmed or rotated.
+func NewNineSliceFromAtlas(atlas *sdlkit.TextureAtlas, name string) (*NineSlice, error) {
+	clip, err := atlas.GetFromName(name)
+	if err != nil {
+		return nil, err
+	}
+	if isTransformedClip(clip) {
+		return nil, errTransformedClip("NineSlice")
+	}
+
+	insets, _ := atlas.GetInsets(name)
+	return NewNineSlice(clip, insets), nil
+}
+
+func MustNewNineSlice(clip sdlkit.TextureClip, insets sdlkit.Insets, possibleErr error) *NineSlice {
+	if possibleErr != nil {
+		sdlkit.FailOnErr(possibleErr)
+	}
+
+	return NewNineSlice(clip, insets)
+}
+
+func (ns *NineSlice) GetX() float64  { return ns.X }
+func (ns *NineSlice) GetY() float64  { return ns.Y }
+func (ns *NineSlice) SetX(x float64) { ns.X = x }
+func (ns *NineSlice) SetY(y float64) { ns.Y = y }
+
+func (ns *NineSlice) Clip() sdlkit.TextureClip { return ns.clip }
+func (ns *NineSlice) Insets() sdlkit.Insets    { return ns.insets }
+
+func (ns *NineSlice) SetInsets(insets sdlkit.Insets) { ns.insets = insets }
+
+// MinSize returns the minimum size of the NineSlice. It is never smaller than
+// the size of its corners.
+func (ns *NineSlice) MinSize() (float64, float64) {
+	w := float64(ns.insets.Left + ns.insets.Right)
+	h := float64(ns.insets.Top + ns.insets.Bottom)
+	if ns.minW > w {
+		w = ns.minW
+	}
+	if ns.minH > h {
+		h = ns.minH
+	}
+	return w, h
+}
+
+// SetMinSize sets the minimum size of the NineSlice. A smaller W or H is
+// increased to this size when drawn.
+func (ns *NineSlice) SetMinSize(w, h float64) {
+	ns.minW, ns.minH = w, h
+}
+
+// Size returns the size of the NineSlice, taking its minimum size into
+// account.
+func (ns *NineSlice) Size() (float64, float64) {
+	w, h := ns.MinSize()
+	if ns.W > w {
+		w = ns.W
+	}
+	if ns.H > h {
+		h = ns.H
+	}
+	return w, h
+}
+
+func (ns *NineSlice) Draw(canvas *sdlkit.Canvas) {
+	if isTransformedClip(ns.clip) {
+		canvas.CatchErr(errTransformedClip("NineSlice"))
+		return
+	}
+
+	w, h := ns.Size()
+	src, dest := nineSliceRects(ns.clip.Location, ns.insets, sdl.Rect{
+		X: int32(ns.X - (w / 2)),
+		Y: int32(ns.Y - (h / 2)),
+		W: int32(w),
+		H: int32(h),
+	})
+
+	for i := range src {
+		if src[i].W <= 0 || src[i].H <= 0 || dest[i].W <= 0 || dest[i].H <= 0 {
+			continue
+		}
+
+		mode := ns.EdgeMode
+		if i == 4 {
+			mode = ns.CenterMode
+		} else if i%2 == 0 {
+			mode = StretchFit // corners always keep their size
+		}
+
+		clip := sdlkit.TextureClip{Texture: ns.clip.Texture, Location: src[i]}
+		if mode == StretchTile {
+			drawStretchTile(canvas, clip, dest[i])
+		} else {
+			canvas.DrawTextureClip(clip, dest[i])
+		}
+	}
+}
+
+// nineSliceRects divides src and dest in nine parts, row by row starting at
+// the top left corner. The edges and center of dest are resized so the
+// corners keep the same size as those of src.
+func nineSliceRects(src sdl.Rect, in sdlkit.Insets, dest sdl.Rect) (s, d [9]sdl.Rect) {
+	srcX := [4]int32{src.X, src.X + in.Left, src.X + src.W - in.Right, src.X + src.W}
+	srcY := [4]int32{src.Y, src.Y + in.Top, src.Y + src.H - in.Bottom, src.Y + src.H}
+	destX := [4]int32{dest.X, dest.X + in.Left, dest.X + dest.W - in.Right, dest.X + dest.W}
+	destY := [4]int32{dest.Y, dest.Y + in.Top, dest.Y + dest.H - in.Bottom, dest.Y + dest.H}
+
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			i := row*3 + col
+			s[i] = sdl.Rect{
+				X: srcX[col],
+				Y: srcY[row],
+				W: srcX[col+1] - srcX[col],
+				H: srcY[row+1] - srcY[row],
+			}
+			d[i] = sdl.Rect{
+				X: destX[col],
+				Y: destY[row],
+				W: destX[col+1] - destX[col],
+				H: destY[row+1] - destY[row],
+			}
+		}
+	}
+	return s, d
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package display
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
+)
+
+func TestNineSliceRects(t *testing.T) {
+	src, dest := nineSliceRects(
+		sdl.Rect{X: 10, Y: 20, W: 30, H: 30},
+		sdlkit.Insets{Left: 5, Top: 6, Right: 7, Bottom: 8},
+		sdl.Rect{X: 100, Y: 200, W: 112, H: 64},
+	)
+
+	assert.Equal(t, [9]sdl.Rect{
+		{X: 10, Y: 20, W: 5, H: 6}, {X: 15, Y: 20, W: 18, H: 6}, {X: 33, Y: 20, W: 7, H: 6},
+		{X: 10, Y: 26, W: 5, H: 16}, {X: 15, Y: 26, W: 18, H: 16}, {X: 33, Y: 26, W: 7, H: 16},
+		{X: 10, Y: 42, W: 5, H: 8}, {X: 15, Y: 42, W: 18, H: 8}, {X: 33, Y: 42, W: 7, H: 8},
+	}, src)
+	assert.Equal(t, [9]sdl.Rect{
+		{X: 100, Y: 200, W: 5, H: 6}, {X: 105, Y: 200, W: 100, H: 6}, {X: 205, Y: 200, W: 7, H: 6},
+		{X: 100, Y: 206, W: 5, H: 50}, {X: 105, Y: 206, W: 100, H: 50}, {X: 205, Y: 206, W: 7, H: 50},
+		{X: 100, Y: 256, W: 5, H: 8}, {X: 105, Y: 256, W: 100, H: 8}, {X: 205, Y: 256, W: 7, H: 8},
+	}, dest)
+}
+
+func TestNineSlice_Size(t *testing.T) {
+	ns := NewNineSlice(
+		sdlkit.TextureClip{Location: sdl.Rect{W: 30, H: 30}},
+		sdlkit.Insets{Left: 10, Top: 10, Right: 10, Bottom: 12},
+	)
+
+	ns.W, ns.H = 5, 5
+	w, h := ns.Size()
+	assert.Equal(t, 20.0, w)
+	assert.Equal(t, 22.0, h)
+
+	ns.SetMinSize(40, 10)
+	w, h = ns.Size()
+	assert.Equal(t, 40.0, w)
+	assert.Equal(t, 22.0, h)
+
+	ns.W, ns.H = 100, 50
+	w, h = ns.Size()
+	assert.Equal(t, 100.0, w)
+	assert.Equal(t, 50.0, h)
+}
+
+func TestNewNineSliceFromAtlas_transformed(t *testing.T) {
+	atlas := sdlkit.NewTextureAtlas(nil, nil)
+	atlas.AddClip("rotated", sdlkit.TextureClip{Location: sdl.Rect{W: 20, H: 30}, Rotation: 90})
+
+	ns, err := NewNineSliceFromAtlas(atlas, "rotated")
+	assert.Nil(t, ns)
+	assert.Error(t, err)
+}
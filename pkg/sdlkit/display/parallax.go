@@ -0,0 +1,132 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package display
+
+import (
+	"math"
+
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
+)
+
+// ParallaxLayer is a background texture which scrolls relative to the
+// Camera. It can repeat horizontally and/or vertically to fill the whole
+// view, wherever the Camera scrolls to.
+type ParallaxLayer struct {
+	// ScrollX and ScrollY are the scroll factors relative to the Camera. A
+	// factor of 0 keeps the layer fixed to the screen, 1 moves it along with
+	// the world.
+	ScrollX, ScrollY float64
+	// SpeedX and SpeedY are the auto-scroll speed in pixels per second.
+	SpeedX, SpeedY float64
+	// OffsetX and OffsetY are the position of the texture on screen, when
+	// the Camera is at 0, 0.
+	OffsetX, OffsetY float64
+
+	RepeatX, RepeatY bool
+
+	clip             sdlkit.TextureClip
+	scrollX, scrollY float64
+}
+
+// NewParallaxLayer creates a ParallaxLayer which repeats on both axes. A
+// trimmed or rotated clip can not be repeated, drawing it adds an error to the
+// Canvas.
+func NewParallaxLayer(clip sdlkit.TextureClip, scrollX, scrollY float64) *ParallaxLayer {
+	return &ParallaxLayer{
+		ScrollX: scrollX,
+		ScrollY: scrollY,
+		RepeatX: true,
+		RepeatY: true,
+		clip:    clip,
+	}
+}
+
+func (l *ParallaxLayer) Clip() sdlkit.TextureClip { return l.clip }
+
+// Update auto-scrolls the layer. The scrolled distance wraps around the size
+// of the texture on repeating axes, so it never grows too large.
+func (l *ParallaxLayer) Update(dt float64) {
+	w, h := l.clip.Size()
+	l.scrollX = wrapScroll(l.scrollX+l.SpeedX*dt, w, l.RepeatX)
+	l.scrollY = wrapScroll(l.scrollY+l.SpeedY*dt, h, l.RepeatY)
+}
+
+func wrapScroll(v, size float64, repeat bool) float64 {
+	if !repeat || size <= 0 {
+		return v
+	}
+	return math.Mod(v, size)
+}
+
+// area returns the area, in world coordinates, which is covered by the layer
+// when the Camera is at camX, camY. Repeating axes cover the full view.
+func (l *ParallaxLayer) area(camX, camY float64, view sdl.Rect) sdl.Rect {
+	loc := l.clip.Location
+	// position of the texture's top left corner in world coordinates
+	x := camX*(1-l.ScrollX) + l.OffsetX + l.scrollX
+	y := camY*(1-l.ScrollY) + l.OffsetY + l.scrollY
+
+	res := sdl.Rect{X: int32(math.Floor(x)), Y: int32(math.Floor(y)), W: loc.W, H: loc.H}
+	if l.RepeatX && loc.W > 0 {
+		n := int32(math.Floor(float64(view.X-res.X) / float64(loc.W)))
+		res.X += n * loc.W
+		res.W = view.X + view.W - res.X
+	}
+	if l.RepeatY && loc.H > 0 {
+		n := int32(math.Floor(float64(view.Y-res.Y) / float64(loc.H)))
+		res.Y += n * loc.H
+		res.H = view.Y + view.H - res.Y
+	}
+	return res
+}
+
+// Parallax is a background of multiple ParallaxLayers, which are drawn in
+// order.
+type Parallax struct {
+	// W and H are the size of the view when the Canvas has no Camera, eg. the
+	// size of the Stage.
+	W, H float64
+
+	Layers []*ParallaxLayer
+}
+
+func NewParallax(w, h float64, layers ...*ParallaxLayer) *Parallax {
+	return &Parallax{
+		W:      w,
+		H:      h,
+		Layers: layers,
+	}
+}
+
+// Update auto-scrolls all layers.
+func (p *Parallax) Update(dt float64) {
+	for _, l := range p.Layers {
+		l.Update(dt)
+	}
+}
+
+// Draw draws the visible part of all layers. Layers are tiled within the view
+// of the Canvas' Camera, or within W and H when there is no Camera.
+func (p *Parallax) Draw(canvas *sdlkit.Canvas) {
+	var camX, camY float64
+	view := sdl.Rect{W: int32(p.W), H: int32(p.H)}
+	if cam := canvas.Camera(); cam.IsEnabled() {
+		camX, camY = cam.GetX(), cam.GetY()
+		view = cam.View()
+	}
+
+	for _, l := range p.Layers {
+		if isTransformedClip(l.clip) {
+			canvas.CatchErr(errTransformedClip("Parallax"))
+			continue
+		}
+
+		eachStretchTile(l.clip.Location, l.area(camX, camY, view), view, func(src, dst sdl.Rect) {
+			canvas.DrawTextureEx(l.clip.Texture, &src, dst, 0, sdl.Point{}, sdl.FLIP_NONE)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package display
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
+)
+
+func TestParallaxLayer_area(t *testing.T) {
+	clip := sdlkit.TextureClip{Location: sdl.Rect{W: 100, H: 50}}
+	view := sdl.Rect{X: 250, Y: 120, W: 300, H: 200}
+
+	l := NewParallaxLayer(clip, 0.5, 0)
+	// the texture is at 125, 120 and repeated over the full view
+	assert.Equal(t, sdl.Rect{X: 225, Y: 120, W: 325, H: 200}, l.area(250, 120, view))
+
+	l.RepeatY = false
+	l.OffsetY = 10
+	assert.Equal(t, sdl.Rect{X: 225, Y: 130, W: 325, H: 50}, l.area(250, 120, view))
+
+	// a factor of 1 moves the texture with the world
+	l = NewParallaxLayer(clip, 1, 1)
+	l.RepeatX = false
+	assert.Equal(t, sdl.Rect{X: 0, Y: 100, W: 100, H: 220}, l.area(250, 120, view))
+}
+
+func TestParallaxLayer_Update(t *testing.T) {
+	l := NewParallaxLayer(sdlkit.TextureClip{Location: sdl.Rect{W: 100, H: 50}}, 0, 0)
+	l.SpeedX, l.SpeedY = -30, 20
+
+	for i := 0; i < 10; i++ {
+		l.Update(1)
+	}
+	assert.Equal(t, 0.0, l.scrollX)
+	assert.Equal(t, 0.0, l.scrollY)
+
+	l.Update(0.5)
+	view := sdl.Rect{W: 300, H: 200}
+	assert.Equal(t, sdl.Rect{X: -15, Y: 10 - 50, W: 315, H: 240}, l.area(0, 0, view))
+}
@@ -23,13 +23,13 @@ type Sprite struct {
 	clip   sdlkit.TextureClip
 }
 
+// NewSprite creates a Sprite with the size of the TextureClip's original
+// frame. The pivot of the TextureClip, if any, is used as its origin.
 func NewSprite(clip sdlkit.TextureClip) *Sprite {
 	// sprite heeft translate nodig
-	s := &Sprite{
-		W:    float64(clip.Location.W),
-		H:    float64(clip.Location.H),
-		clip: clip,
-	}
+	s := &Sprite{clip: clip}
+	s.W, s.H = clip.Size()
+	s.origin, _ = clipOrigin(clip)
 	s.Reset()
 	return s
 }
@@ -42,6 +42,24 @@ func MustNewSprite(clip sdlkit.TextureClip, possibleErr error) *Sprite {
 	return NewSprite(clip)
 }
 
+// NewSpriteFromSheet creates a Sprite of the frame within the
+// sdlkit.SpriteSheet. The pivot of the slice with name pivotSlice, if any, is
+// used as the Sprite's origin.
+func NewSpriteFromSheet(sheet *sdlkit.SpriteSheet, frame int, pivotSlice string) (*Sprite, error) {
+	clip, err := sheet.Atlas.GetFromIndex(frame)
+	if err != nil {
+		return nil, err
+	}
+
+	s := NewSprite(clip)
+	if pivotSlice != "" {
+		if pt, ok := sheet.Pivot(pivotSlice, frame); ok {
+			s.origin = pivotToOrigin(pt, clip)
+		}
+	}
+	return s, nil
+}
+
 func (s *Sprite) GetX() float64  { return s.X }
 func (s *Sprite) GetY() float64  { return s.Y }
 func (s *Sprite) SetX(x float64) { s.X = x }
@@ -50,12 +68,18 @@ func (s *Sprite) SetY(y float64) { s.Y = y }
 func (s *Sprite) Clip() sdlkit.TextureClip { return s.clip }
 func (s *Sprite) Origin() *geom.Point      { return &s.origin }
 
+// Reset resets the Sprite's ColorTransform and TextureTransform.
+func (s *Sprite) Reset() {
+	s.ColorTransform.Reset()
+	s.TextureTransform.Reset()
+}
+
 func (s *Sprite) AbsoluteOrigin() geom.Point {
 	return geom.Point{X: s.X + s.origin.X, Y: s.Y + s.origin.Y}
 }
 
 func (s *Sprite) Draw(canvas *sdlkit.Canvas) {
-	canvas.DrawTextureClipEx(s.clip,
+	drawTransformedClip(canvas, s.clip,
 		sdl.Rect{
 			X: int32(s.X - (s.W / 2)),
 			Y: int32(s.Y - (s.H / 2)),
@@ -66,7 +90,43 @@ func (s *Sprite) Draw(canvas *sdlkit.Canvas) {
 		s.Rotation*math.R2D,
 		// center point is relative to top left (0,0) of texture
 		sdl.Point{X: int32(s.origin.X + s.W/2), Y: int32(s.origin.Y + s.H/2)},
-		s.Flip,
+		&s.ColorTransform,
+		&s.TextureTransform,
+	)
+}
+
+// DrawWorld draws the Sprite transformed by the parent Matrix, eg. of the
+// Container the Sprite is a child of.
+func (s *Sprite) DrawWorld(canvas *sdlkit.Canvas, parent geom.Matrix) {
+	rot, sx, sy := parent.Decompose()
+
+	tt := s.TextureTransform
+	mirror := sy < 0
+	if mirror {
+		sy = -sy
+		tt.Flip ^= sdl.FLIP_VERTICAL
+	}
+
+	w, h := s.W*sx, s.H*sy
+	// the rotation point, relative to the top left of the scaled texture
+	rx, ry := (s.origin.X+s.W/2)*sx, (s.origin.Y+s.H/2)*sy
+	if mirror {
+		ry = h - ry
+	}
+	// the position of the rotation point in the world
+	px, py := parent.TransformXY(s.X+s.origin.X, s.Y+s.origin.Y)
+
+	drawTransformedClip(canvas, s.clip,
+		sdl.Rect{
+			X: int32(px - rx),
+			Y: int32(py - ry),
+			W: int32(w),
+			H: int32(h),
+		},
+		(rot+s.Rotation)*math.R2D,
+		sdl.Point{X: int32(rx), Y: int32(ry)},
+		&s.ColorTransform,
+		&tt,
 	)
 }
 
@@ -0,0 +1,401 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package display
+
+import (
+	"time"
+
+	"github.com/go-pogo/errors"
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom"
+)
+
+type LoopMode uint8
+
+const (
+	// Loop restarts the animation from the first frame after the last frame.
+	Loop LoopMode = iota
+	// LoopOnce stops the animation at its last frame.
+	LoopOnce
+	// LoopPingPong plays the animation forward and backward.
+	LoopPingPong
+)
+
+type AnimationFrame struct {
+	Clip     sdlkit.TextureClip
+	Duration time.Duration
+	// Event is passed to AnimatedSprite.OnFrameEvent when the frame is shown.
+	// An empty Event is ignored.
+	Event string
+	// Origin, when not nil, replaces the AnimatedSprite's origin when the
+	// frame is shown. Otherwise the pivot of Clip is used, if any.
+	Origin *geom.Point
+}
+
+type Animation struct {
+	Name   string
+	Frames []AnimationFrame
+	Loop   LoopMode
+	// Repeat is the number of times a Loop or LoopPingPong Animation is
+	// played before it stops at its last shown frame, 0 means forever. Each
+	// pass of a LoopPingPong Animation, either forward or backward, counts as
+	// a play.
+	Repeat int
+}
+
+// NewAnimationFromIndexes creates an Animation with frames from the
+// TextureAtlas at the provided indexes. All frames have the same duration,
+// which can be changed afterwards.
+func NewAnimationFromIndexes(atlas *sdlkit.TextureAtlas, name string, frameDuration time.Duration, indexes ...int) (*Animation, error) {
+	anim := &Animation{
+		Name:   name,
+		Frames: make([]AnimationFrame, 0, len(indexes)),
+	}
+	for _, i := range indexes {
+		clip, err := atlas.GetFromIndex(i)
+		if err != nil {
+			return nil, err
+		}
+
+		anim.Frames = append(anim.Frames, AnimationFrame{Clip: clip, Duration: frameDuration})
+	}
+	return anim, nil
+}
+
+// NewAnimationFromNames creates an Animation with frames from the
+// TextureAtlas with the provided names. All frames have the same duration,
+// which can be changed afterwards.
+func NewAnimationFromNames(atlas *sdlkit.TextureAtlas, name string, frameDuration time.Duration, names ...string) (*Animation, error) {
+	anim := &Animation{
+		Name:   name,
+		Frames: make([]AnimationFrame, 0, len(names)),
+	}
+	for _, n := range names {
+		clip, err := atlas.GetFromName(n)
+		if err != nil {
+			return nil, err
+		}
+
+		anim.Frames = append(anim.Frames, AnimationFrame{Clip: clip, Duration: frameDuration})
+	}
+	return anim, nil
+}
+
+// NewAnimationsFromSheet creates an Animation for each AnimationTag of the
+// sdlkit.SpriteSheet. The pivots of the slice with name pivotSlice, if any,
+// are used as the origins of the frames.
+func NewAnimationsFromSheet(sheet *sdlkit.SpriteSheet, pivotSlice string) ([]*Animation, error) {
+	res := make([]*Animation, 0, len(sheet.Tags))
+	for _, tag := range sheet.Tags {
+		anim := &Animation{
+			Name:   tag.Name,
+			Frames: make([]AnimationFrame, 0, tag.To-tag.From+1),
+		}
+
+		switch tag.Direction {
+		case sdlkit.AnimatePingPong, sdlkit.AnimatePingPongReverse:
+			anim.Loop = LoopPingPong
+			anim.Repeat = tag.Repeat
+		default:
+			if tag.Repeat == 1 {
+				anim.Loop = LoopOnce
+			} else {
+				anim.Repeat = tag.Repeat
+			}
+		}
+
+		for i := tag.From; i <= tag.To; i++ {
+			frame, err := newSheetFrame(sheet, i, pivotSlice)
+			if err != nil {
+				return nil, err
+			}
+
+			anim.Frames = append(anim.Frames, frame)
+		}
+
+		if tag.Direction == sdlkit.AnimateReverse || tag.Direction == sdlkit.AnimatePingPongReverse {
+			for i, j := 0, len(anim.Frames)-1; i < j; i, j = i+1, j-1 {
+				anim.Frames[i], anim.Frames[j] = anim.Frames[j], anim.Frames[i]
+			}
+		}
+
+		res = append(res, anim)
+	}
+	return res, nil
+}
+
+func newSheetFrame(sheet *sdlkit.SpriteSheet, i int, pivotSlice string) (AnimationFrame, error) {
+	clip, err := sheet.Atlas.GetFromIndex(i)
+	if err != nil {
+		return AnimationFrame{}, err
+	}
+
+	frame := AnimationFrame{Clip: clip}
+	if i < len(sheet.Durations) {
+		frame.Duration = sheet.Durations[i]
+	}
+	if pivotSlice != "" {
+		if pt, ok := sheet.Pivot(pivotSlice, i); ok {
+			origin := pivotToOrigin(pt, clip)
+			frame.Origin = &origin
+		}
+	}
+	return frame, nil
+}
+
+// pivotToOrigin converts a pivot point relative to the top left corner of the
+// TextureClip's original frame to an origin relative to its center.
+func pivotToOrigin(pt sdl.Point, clip sdlkit.TextureClip) geom.Point {
+	w, h := clip.Size()
+	return geom.Point{
+		X: float64(pt.X) - w/2,
+		Y: float64(pt.Y) - h/2,
+	}
+}
+
+// clipOrigin returns the pivot of the TextureClip as an origin relative to its
+// center.
+func clipOrigin(clip sdlkit.TextureClip) (geom.Point, bool) {
+	if !clip.HasPivot {
+		return geom.Point{}, false
+	}
+
+	w, h := clip.Size()
+	return geom.Point{X: clip.Pivot.X - w/2, Y: clip.Pivot.Y - h/2}, true
+}
+
+// Duration returns the total duration of a single play of the Animation.
+func (a *Animation) Duration() time.Duration {
+	var d time.Duration
+	for _, f := range a.Frames {
+		d += f.Duration
+	}
+	return d
+}
+
+// AnimatedSprite is a Sprite which plays one of its named Animations. Call
+// Update every frame to advance the animation using the delta time of its
+// sdlkit.Clock, so it respects the Clock's TimeScale.
+type AnimatedSprite struct {
+	Sprite
+
+	// OnFrameEvent is called when a frame with a non-empty Event is shown.
+	OnFrameEvent func(s *AnimatedSprite, event string)
+	// OnFrameChange is called whenever the current frame changes.
+	OnFrameChange func(s *AnimatedSprite, frame int)
+	// OnComplete is called when an Animation with LoopOnce, or with a
+	// Repeat, has finished.
+	OnComplete func(s *AnimatedSprite, anim *Animation)
+
+	clock      *sdlkit.Clock
+	animations map[string]*Animation
+	current    *Animation
+	frame      int
+	plays      int // finished plays of the current Animation
+	reverse    bool
+	elapsed    float64 // seconds the current frame has been shown
+	playing    bool
+}
+
+// NewAnimatedSprite creates an AnimatedSprite with the provided Animations.
+// Its size is set to the size of the first frame of the first Animation, which
+// is also shown until an Animation is played.
+func NewAnimatedSprite(clock *sdlkit.Clock, anims ...*Animation) *AnimatedSprite {
+	s := &AnimatedSprite{
+		clock:      clock,
+		animations: make(map[string]*Animation, len(anims)),
+	}
+	s.Sprite.Reset()
+
+	for _, anim := range anims {
+		s.AddAnimation(anim)
+	}
+	if len(anims) != 0 && len(anims[0].Frames) != 0 {
+		first := anims[0].Frames[0]
+		s.clip = first.Clip
+		s.W, s.H = s.clip.Size()
+		if first.Origin != nil {
+			s.origin = *first.Origin
+		} else if o, ok := clipOrigin(s.clip); ok {
+			s.origin = o
+		}
+	}
+	return s
+}
+
+func (s *AnimatedSprite) AddAnimation(anim *Animation) { s.animations[anim.Name] = anim }
+
+func (s *AnimatedSprite) HasAnimation(name string) bool {
+	_, ok := s.animations[name]
+	return ok
+}
+
+func (s *AnimatedSprite) Animation(name string) *Animation { return s.animations[name] }
+
+// CurrentAnimation returns the Animation which is currently played, or was
+// played last.
+func (s *AnimatedSprite) CurrentAnimation() *Animation { return s.current }
+
+// Frame returns the index of the current frame within the current Animation.
+func (s *AnimatedSprite) Frame() int { return s.frame }
+
+func (s *AnimatedSprite) IsPlaying() bool { return s.playing }
+
+// Play plays the Animation with name. When this Animation is already playing,
+// it continues to play.
+func (s *AnimatedSprite) Play(name string) error {
+	if s.playing && s.current != nil && s.current.Name == name {
+		return nil
+	}
+	return s.PlayFrom(name, 0)
+}
+
+// PlayFrom plays the Animation with name, starting at the provided frame.
+func (s *AnimatedSprite) PlayFrom(name string, frame int) error {
+	anim, ok := s.animations[name]
+	if !ok {
+		return errors.Newf("display: unknown animation `%s`", name)
+	}
+	if frame < 0 || frame >= len(anim.Frames) {
+		return errors.Newf("display: unknown frame %d in animation `%s`", frame, name)
+	}
+
+	s.current = anim
+	s.plays = 0
+	s.reverse = false
+	s.elapsed = 0
+	s.playing = true
+	s.setFrame(frame)
+	return nil
+}
+
+// Stop stops the current Animation and shows its first frame.
+func (s *AnimatedSprite) Stop() {
+	s.playing = false
+	if s.current != nil && len(s.current.Frames) != 0 {
+		s.elapsed = 0
+		s.plays = 0
+		s.reverse = false
+		s.setFrame(0)
+	}
+}
+
+// Pause pauses the current Animation at its current frame.
+func (s *AnimatedSprite) Pause() { s.playing = false }
+
+// Resume continues playing a paused Animation.
+func (s *AnimatedSprite) Resume() {
+	if s.current != nil {
+		s.playing = true
+	}
+}
+
+// Update advances the current Animation with the delta time of the
+// AnimatedSprite's sdlkit.Clock.
+func (s *AnimatedSprite) Update() { s.Advance(s.clock.Delta64) }
+
+// Advance advances the current Animation with dt seconds. Multiple frames are
+// skipped when dt is larger than the duration of the current frame.
+func (s *AnimatedSprite) Advance(dt float64) {
+	if !s.playing || s.current == nil || len(s.current.Frames) == 0 {
+		return
+	}
+
+	s.elapsed += dt
+	for s.playing {
+		d := s.current.Frames[s.frame].Duration.Seconds()
+		if d <= 0 || s.elapsed < d {
+			break
+		}
+
+		s.elapsed -= d
+		s.nextFrame()
+	}
+}
+
+func (s *AnimatedSprite) nextFrame() {
+	last := len(s.current.Frames) - 1
+	next := s.frame
+
+	switch s.current.Loop {
+	case LoopOnce:
+		if s.frame >= last {
+			s.complete()
+			return
+		}
+		next++
+
+	case LoopPingPong:
+		if s.reverse {
+			next--
+		} else {
+			next++
+		}
+		if next >= 0 && next <= last {
+			break
+		}
+		if s.finishPlay() || last == 0 {
+			return
+		}
+		if next > last {
+			s.reverse = true
+			next = last - 1
+		} else {
+			s.reverse = false
+			next = 1
+		}
+
+	default:
+		next++
+		if next > last {
+			if s.finishPlay() {
+				return
+			}
+			next = 0
+		}
+	}
+
+	s.setFrame(next)
+}
+
+// finishPlay counts a finished play of the current Animation and completes it
+// when it is played Repeat times.
+func (s *AnimatedSprite) finishPlay() bool {
+	s.plays++
+	if s.current.Repeat <= 0 || s.plays < s.current.Repeat {
+		return false
+	}
+
+	s.complete()
+	return true
+}
+
+func (s *AnimatedSprite) complete() {
+	s.playing = false
+	s.elapsed = 0
+	if s.OnComplete != nil {
+		s.OnComplete(s, s.current)
+	}
+}
+
+func (s *AnimatedSprite) setFrame(i int) {
+	s.frame = i
+	s.clip = s.current.Frames[i].Clip
+	s.W, s.H = s.clip.Size()
+	if o := s.current.Frames[i].Origin; o != nil {
+		s.origin = *o
+	} else if o, ok := clipOrigin(s.clip); ok {
+		s.origin = o
+	}
+
+	if s.OnFrameChange != nil {
+		s.OnFrameChange(s, i)
+	}
+	if e := s.current.Frames[i].Event; e != "" && s.OnFrameEvent != nil {
+		s.OnFrameEvent(s, e)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package display
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom"
+)
+
+func newTestAnimatedSprite(t *testing.T, loop LoopMode) *AnimatedSprite {
+	atlas := sdlkit.NewTextureAtlas(nil, map[string]sdl.Rect{
+		"f0": {X: 0, W: 10, H: 10},
+		"f1": {X: 10, W: 10, H: 10},
+		"f2": {X: 20, W: 12, H: 14},
+	})
+
+	anim, err := NewAnimationFromNames(atlas, "walk", 100*time.Millisecond, "f0", "f1", "f2")
+	assert.NoError(t, err)
+	anim.Loop = loop
+
+	s := NewAnimatedSprite(sdlkit.NewClock(), anim)
+	assert.NoError(t, s.Play("walk"))
+	return s
+}
+
+func collectFrames(s *AnimatedSprite, steps int, dt float64) []int {
+	frames := make([]int, 0, steps)
+	for i := 0; i < steps; i++ {
+		s.Advance(dt)
+		frames = append(frames, s.Frame())
+	}
+	return frames
+}
+
+func TestAnimatedSprite_Advance(t *testing.T) {
+	tests := map[LoopMode][]int{
+		Loop:         {1, 2, 0, 1, 2, 0, 1},
+		LoopOnce:     {1, 2, 2, 2, 2, 2, 2},
+		LoopPingPong: {1, 2, 1, 0, 1, 2, 1},
+	}
+
+	for loop, want := range tests {
+		s := newTestAnimatedSprite(t, loop)
+		assert.Equal(t, want, collectFrames(s, len(want), 0.1), "loop mode %d", loop)
+	}
+}
+
+func TestAnimatedSprite_Repeat(t *testing.T) {
+	tests := map[LoopMode][]int{
+		Loop:         {1, 2, 0, 1, 2, 2, 2},
+		LoopPingPong: {1, 2, 1, 0, 0, 0, 0},
+	}
+
+	for loop, want := range tests {
+		s := newTestAnimatedSprite(t, loop)
+		s.CurrentAnimation().Repeat = 2
+
+		var completed int
+		s.OnComplete = func(*AnimatedSprite, *Animation) { completed++ }
+
+		assert.Equal(t, want, collectFrames(s, len(want), 0.1), "loop mode %d", loop)
+		assert.False(t, s.IsPlaying(), "loop mode %d", loop)
+		assert.Equal(t, 1, completed, "loop mode %d", loop)
+	}
+}
+
+func TestAnimatedSprite_Clip(t *testing.T) {
+	s := newTestAnimatedSprite(t, Loop)
+	assert.Equal(t, sdl.Rect{X: 0, W: 10, H: 10}, s.Clip().Location)
+	assert.Equal(t, 10.0, s.W)
+
+	s.Advance(0.25)
+	assert.Equal(t, 2, s.Frame())
+	assert.Equal(t, sdl.Rect{X: 20, W: 12, H: 14}, s.Clip().Location)
+	assert.Equal(t, 12.0, s.W)
+	assert.Equal(t, 14.0, s.H)
+}
+
+func TestAnimatedSprite_Callbacks(t *testing.T) {
+	s := newTestAnimatedSprite(t, LoopOnce)
+	s.CurrentAnimation().Frames[1].Event = "step"
+
+	var events []string
+	var completed int
+	s.OnFrameEvent = func(_ *AnimatedSprite, event string) { events = append(events, event) }
+	s.OnComplete = func(_ *AnimatedSprite, anim *Animation) {
+		assert.Equal(t, "walk", anim.Name)
+		completed++
+	}
+
+	s.Advance(1)
+	assert.Equal(t, []string{"step"}, events)
+	assert.Equal(t, 1, completed)
+	assert.False(t, s.IsPlaying())
+}
+
+func TestAnimatedSprite_Update(t *testing.T) {
+	s := newTestAnimatedSprite(t, Loop)
+	s.clock.TimeScale = 0.5
+	s.clock.Delta64 = 0.2 * s.clock.TimeScale
+
+	s.Update()
+	assert.Equal(t, 1, s.Frame())
+
+	s.Pause()
+	s.Update()
+	assert.Equal(t, 1, s.Frame())
+
+	s.Resume()
+	s.Update()
+	assert.Equal(t, 2, s.Frame())
+
+	assert.Error(t, s.Play("run"))
+}
+
+func TestNewAnimationsFromSheet(t *testing.T) {
+	sheet := &sdlkit.SpriteSheet{
+		Atlas: sdlkit.NewTextureAtlas(nil, map[string]sdl.Rect{
+			"f0": {X: 0, W: 10, H: 20},
+			"f1": {X: 10, W: 10, H: 20},
+			"f2": {X: 20, W: 10, H: 20},
+		}),
+		Durations: []time.Duration{time.Second, 2 * time.Second, 3 * time.Second},
+		Tags: []sdlkit.AnimationTag{
+			{Name: "walk", From: 0, To: 2, Direction: sdlkit.AnimatePingPong, Repeat: 2},
+			{Name: "die", From: 1, To: 2, Direction: sdlkit.AnimateReverse, Repeat: 1},
+		},
+		Slices: []sdlkit.SpriteSlice{{
+			Name: "pivot",
+			Keys: []sdlkit.SpriteSliceKey{{
+				Frame:    1,
+				Bounds:   sdl.Rect{X: 2, Y: 16, W: 6, H: 4},
+				Pivot:    sdl.Point{X: 3, Y: 4},
+				HasPivot: true,
+			}},
+		}},
+	}
+
+	anims, err := NewAnimationsFromSheet(sheet, "pivot")
+	assert.NoError(t, err)
+	if !assert.Len(t, anims, 2) {
+		return
+	}
+
+	clip := func(i int) sdlkit.TextureClip {
+		c, _ := sheet.Atlas.GetFromIndex(i)
+		return c
+	}
+
+	walk := anims[0]
+	assert.Equal(t, LoopPingPong, walk.Loop)
+	assert.Equal(t, 2, walk.Repeat)
+	assert.Equal(t, 6*time.Second, walk.Duration())
+	assert.Nil(t, walk.Frames[0].Origin)
+	assert.Equal(t, &geom.Point{X: 0, Y: 10}, walk.Frames[1].Origin)
+
+	die := anims[1]
+	assert.Equal(t, LoopOnce, die.Loop)
+	assert.Equal(t, []sdlkit.TextureClip{clip(2), clip(1)}, []sdlkit.TextureClip{die.Frames[0].Clip, die.Frames[1].Clip})
+	assert.Equal(t, 3*time.Second, die.Frames[0].Duration)
+
+	s := NewAnimatedSprite(sdlkit.NewClock(), die)
+	assert.NoError(t, s.Play("die"))
+	assert.Equal(t, geom.Point{X: 0, Y: 10}, *s.Origin())
+}
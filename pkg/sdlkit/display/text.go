@@ -0,0 +1,316 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package display
+
+import (
+	"github.com/go-pogo/errors"
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/colors"
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom/align"
+)
+
+// Text displays a (multiline) text using a sdlkit.GlyphFont. Its glyphs are
+// drawn onto a sdlkit.CachedLayer, which is only redrawn when the text or any
+// of its styling changes.
+// The text may contain inline color markup in the form of
+// `[color=#rrggbb]colored text[/color]`.
+type Text struct {
+	// X and Y indicate the position of the Text. Which point of the Text is
+	// placed at this position depends on its alignment, the top left corner
+	// is used by default.
+	X, Y float64
+
+	font  sdlkit.GlyphFont
+	cache *sdlkit.CachedLayer
+	text  string
+	align align.Alignment
+	color sdl.Color
+
+	width        int32 // max width of a line before it wraps
+	lineSpacing  int32
+	outline      int32
+	outlineColor sdl.Color
+	shadow       [2]int32
+	shadowColor  sdl.Color
+
+	layout textLayout
+	err    error
+	dirty  bool
+}
+
+func NewText(canvas *sdlkit.Canvas, font sdlkit.GlyphFont, text string) *Text {
+	t := &Text{
+		font:  font,
+		text:  text,
+		color: colors.White,
+		dirty: true,
+	}
+	t.cache = sdlkit.NewCachedLayer(canvas, 1, 1, sdlkit.DrawableFunc(t.drawGlyphs))
+	return t
+}
+
+func (t *Text) GetX() float64  { return t.X }
+func (t *Text) GetY() float64  { return t.Y }
+func (t *Text) SetX(x float64) { t.X = x }
+func (t *Text) SetY(y float64) { t.Y = y }
+
+func (t *Text) Font() sdlkit.GlyphFont { return t.font }
+
+func (t *Text) SetFont(font sdlkit.GlyphFont) {
+	if t.font != font {
+		t.font = font
+		t.dirty = true
+	}
+}
+
+func (t *Text) Text() string { return t.text }
+
+// SetText changes the text. The Text is only updated when the new text
+// differs from the current text.
+func (t *Text) SetText(text string) {
+	if t.text != text {
+		t.text = text
+		t.dirty = true
+	}
+}
+
+func (t *Text) Color() sdl.Color { return t.color }
+
+// SetColor sets the default color of the text. Colors set with inline markup
+// take precedence over this color.
+func (t *Text) SetColor(color sdl.Color) {
+	if t.color != color {
+		t.color = color
+		t.dirty = true
+	}
+}
+
+func (t *Text) Alignment() align.Alignment { return t.align }
+
+// SetAlignment aligns the lines of the Text relative to each other, and
+// determines which point of the Text is placed at X and Y. Eg. align.ToCenter
+// centers all lines and places the center of the Text at X and Y.
+func (t *Text) SetAlignment(to align.Alignment) {
+	if t.align != to {
+		t.align = to
+		t.dirty = true
+	}
+}
+
+func (t *Text) Width() int32 { return t.width }
+
+// SetWidth sets the maximum width of a line, longer lines are wrapped. A width
+// of 0 disables wrapping.
+func (t *Text) SetWidth(w int32) {
+	if t.width != w {
+		t.width = w
+		t.dirty = true
+	}
+}
+
+func (t *Text) LineSpacing() int32 { return t.lineSpacing }
+
+// SetLineSpacing sets the additional space between lines in pixels.
+func (t *Text) SetLineSpacing(spacing int32) {
+	if t.lineSpacing != spacing {
+		t.lineSpacing = spacing
+		t.dirty = true
+	}
+}
+
+// SetOutline draws an outline with the given thickness around all glyphs. A
+// thickness of 0 disables the outline.
+func (t *Text) SetOutline(thickness int32, color sdl.Color) {
+	if t.outline != thickness || t.outlineColor != color {
+		t.outline = thickness
+		t.outlineColor = color
+		t.dirty = true
+	}
+}
+
+// SetShadow draws a shadow of all glyphs at the given offset. An offset of
+// 0, 0 disables the shadow.
+func (t *Text) SetShadow(offsetX, offsetY int32, color sdl.Color) {
+	if t.shadow[0] != offsetX || t.shadow[1] != offsetY || t.shadowColor != color {
+		t.shadow[0], t.shadow[1] = offsetX, offsetY
+		t.shadowColor = color
+		t.dirty = true
+	}
+}
+
+// Size returns the width and height of the Text's layout, excluding any
+// outline or shadow.
+func (t *Text) Size() (int32, int32) {
+	_ = t.Update()
+	return t.layout.boxW, t.layout.h
+}
+
+// Update recalculates the Text's layout when it has changed. It returns any
+// errors that occurred while retrieving glyphs from the font.
+func (t *Text) Update() error {
+	if !t.dirty {
+		return t.err
+	}
+
+	t.dirty = false
+	t.layout, t.err = layoutText(t.font, parseMarkup(t.text, t.color), t.width, t.lineSpacing, t.align)
+
+	left, top, right, bottom := t.padding()
+	w := t.layout.boxW + left + right
+	h := t.layout.h + top + bottom
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+
+	errors.Append(&t.err, t.cache.Resize(w, h))
+	t.cache.MarkDirty()
+	return t.err
+}
+
+// padding returns the additional space needed around the layout to draw the
+// outline and shadow.
+func (t *Text) padding() (left, top, right, bottom int32) {
+	left, top, right, bottom = t.outline, t.outline, t.outline, t.outline
+	if t.shadowColor.A == 0 {
+		return
+	}
+	if -t.shadow[0] > left {
+		left = -t.shadow[0]
+	} else if t.shadow[0] > right {
+		right = t.shadow[0]
+	}
+	if -t.shadow[1] > top {
+		top = -t.shadow[1]
+	} else if t.shadow[1] > bottom {
+		bottom = t.shadow[1]
+	}
+	return
+}
+
+func (t *Text) updateCachePosition() {
+	var ax, ay float64
+	align.Values(t.align, &ax, &ay, 0, 0, float64(t.layout.boxW), float64(t.layout.h))
+
+	left, top, _, _ := t.padding()
+	t.cache.X = t.X - ax - float64(left)
+	t.cache.Y = t.Y - ay - float64(top)
+}
+
+// Draw draws the Text onto the Canvas, taking its Camera into account.
+func (t *Text) Draw(canvas *sdlkit.Canvas) {
+	if err := t.Update(); err != nil {
+		canvas.CatchErr(err)
+	}
+	if len(t.layout.glyphs) == 0 {
+		return
+	}
+
+	t.updateCachePosition()
+	t.cache.Draw(canvas)
+}
+
+// Render renders the Text at its position, ignoring any Camera.
+func (t *Text) Render(ren *sdl.Renderer) error {
+	if err := t.Update(); err != nil {
+		return err
+	}
+	if len(t.layout.glyphs) == 0 {
+		return nil
+	}
+
+	t.updateCachePosition()
+	return t.cache.Render(ren)
+}
+
+// Destroy destroys the texture the Text is cached on. It does not destroy the
+// font.
+func (t *Text) Destroy() error { return t.cache.Destroy() }
+
+// HandleRenderEvent forwards the sdl.RenderEvent to the Text's cache.
+func (t *Text) HandleRenderEvent(e *sdl.RenderEvent) error {
+	return t.cache.HandleRenderEvent(e)
+}
+
+// drawGlyphs draws all glyphs of the layout onto the cache's texture.
+func (t *Text) drawGlyphs(canvas *sdlkit.Canvas) {
+	left, top, _, _ := t.padding()
+	mod := make(glyphColorMod, 2)
+
+	if t.shadowColor.A != 0 && (t.shadow[0] != 0 || t.shadow[1] != 0) {
+		t.drawGlyphsPass(canvas, mod, left+t.shadow[0], top+t.shadow[1], &t.shadowColor)
+	}
+	if t.outline > 0 {
+		o := t.outline
+		for dy := -o; dy <= o; dy++ {
+			for dx := -o; dx <= o; dx++ {
+				if dx != 0 || dy != 0 {
+					t.drawGlyphsPass(canvas, mod, left+dx, top+dy, &t.outlineColor)
+				}
+			}
+		}
+	}
+
+	t.drawGlyphsPass(canvas, mod, left, top, nil)
+	canvas.CatchErr(mod.reset())
+}
+
+func (t *Text) drawGlyphsPass(canvas *sdlkit.Canvas, mod glyphColorMod, offsetX, offsetY int32, color *sdl.Color) {
+	for _, g := range t.layout.glyphs {
+		if g.Texture == nil || g.Location.W == 0 {
+			continue
+		}
+
+		col := g.color
+		if color != nil {
+			col = *color
+		}
+
+		canvas.CatchErr(mod.set(g.Texture, col))
+		canvas.DrawTextureEx(g.Texture, &g.Location,
+			sdl.Rect{
+				X: offsetX + g.x + g.OffsetX,
+				Y: offsetY + g.y + g.OffsetY,
+				W: g.Location.W,
+				H: g.Location.H,
+			},
+			0,
+			sdl.Point{},
+			sdl.FLIP_NONE,
+		)
+	}
+}
+
+// glyphColorMod keeps track of the color mods set on glyph textures, so they
+// are only changed when needed and can be reset afterwards.
+type glyphColorMod map[*sdl.Texture]sdl.Color
+
+func (m glyphColorMod) set(tx *sdl.Texture, color sdl.Color) error {
+	if cur, ok := m[tx]; ok && cur == color {
+		return nil
+	}
+
+	m[tx] = color
+	if err := tx.SetColorMod(color.R, color.G, color.B); err != nil {
+		return err
+	}
+	return tx.SetAlphaMod(color.A)
+}
+
+func (m glyphColorMod) reset() error {
+	var err error
+	for tx := range m {
+		errors.Append(&err,
+			tx.SetColorMod(0xff, 0xff, 0xff),
+			tx.SetAlphaMod(0xff),
+		)
+		delete(m, tx)
+	}
+	return err
+}
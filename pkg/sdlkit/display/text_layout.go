@@ -0,0 +1,241 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package display
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/go-pogo/errors"
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/colors"
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom/align"
+)
+
+// textSpan is a part of a text which is drawn in a single color.
+type textSpan struct {
+	text  string
+	color sdl.Color
+}
+
+// parseMarkup splits s into textSpans using inline color markup. Text between
+// `[color=#rrggbb]` (or `#rrggbbaa`) and `[/color]` is colored accordingly,
+// tags can be nested. All other text uses color def. Use `[[` to write a
+// literal `[`. Malformed tags are kept as literal text.
+func parseMarkup(s string, def sdl.Color) []textSpan {
+	var spans []textSpan
+	var buf strings.Builder
+
+	stack := []sdl.Color{def}
+	flush := func() {
+		if buf.Len() != 0 {
+			spans = append(spans, textSpan{text: buf.String(), color: stack[len(stack)-1]})
+			buf.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		if s[i] != '[' {
+			j := strings.IndexByte(s[i:], '[')
+			if j < 0 {
+				buf.WriteString(s[i:])
+				break
+			}
+
+			buf.WriteString(s[i : i+j])
+			i += j
+			continue
+		}
+		if strings.HasPrefix(s[i:], "[[") {
+			buf.WriteByte('[')
+			i += 2
+			continue
+		}
+
+		end := strings.IndexByte(s[i:], ']')
+		if end < 0 {
+			buf.WriteString(s[i:])
+			break
+		}
+
+		tag := s[i+1 : i+end]
+		if tag == "/color" && len(stack) > 1 {
+			flush()
+			stack = stack[:len(stack)-1]
+			i += end + 1
+			continue
+		}
+		if strings.HasPrefix(tag, "color=") {
+			if col, err := colors.ParseHex(tag[6:]); err == nil {
+				flush()
+				stack = append(stack, col)
+				i += end + 1
+				continue
+			}
+		}
+
+		buf.WriteByte('[')
+		i++
+	}
+
+	flush()
+	return spans
+}
+
+// textGlyph is a positioned sdlkit.Glyph within a textLayout.
+type textGlyph struct {
+	sdlkit.Glyph
+	r     rune
+	x, y  int32 // position relative to the top left of the layout
+	color sdl.Color
+}
+
+type textLine struct {
+	start, end int // glyph indexes
+	width      int32
+}
+
+type textLayout struct {
+	glyphs []textGlyph
+	lines  []textLine
+
+	// w is the width of the widest line, h is the height of all lines.
+	w, h int32
+	// boxW is the width lines are aligned within.
+	boxW int32
+}
+
+// layoutText positions the glyphs of all spans. Lines are wrapped at
+// whitespace when they exceed maxWidth, words which are wider than maxWidth
+// are wrapped at the character that exceeds it. A maxWidth of 0 disables
+// wrapping. Lines are horizontally aligned according to the provided
+// alignment. Missing glyphs are skipped and their errors returned.
+func layoutText(font sdlkit.GlyphFont, spans []textSpan, maxWidth, lineSpacing int32, to align.Alignment) (textLayout, error) {
+	var l textLayout
+	var err error
+	var penX int32
+	var prev rune
+
+	lineStart := 0
+	breakAt := -1 // first glyph after the last whitespace of the current line
+
+	for _, span := range spans {
+		for _, r := range span.text {
+			if r == '\n' {
+				l.endLine(lineStart, len(l.glyphs))
+				lineStart = len(l.glyphs)
+				penX, prev, breakAt = 0, 0, -1
+				continue
+			}
+
+			g, glyphErr := font.Glyph(r)
+			if glyphErr != nil {
+				errors.Append(&err, glyphErr)
+				continue
+			}
+
+			var kern int32
+			if prev != 0 {
+				kern = font.Kerning(prev, r)
+			}
+
+			space := unicode.IsSpace(r)
+			if maxWidth > 0 && !space && lineStart < len(l.glyphs) &&
+				penX+kern+g.OffsetX+g.Location.W > maxWidth {
+				move := len(l.glyphs)
+				if breakAt > lineStart {
+					move = breakAt
+				}
+
+				l.endLine(lineStart, move)
+				lineStart, breakAt = move, -1
+
+				if move == len(l.glyphs) {
+					penX, kern = 0, 0
+				} else {
+					// move the already positioned part of the word to the
+					// start of the new line
+					shift := l.glyphs[move].x
+					for i := move; i < len(l.glyphs); i++ {
+						l.glyphs[i].x -= shift
+					}
+					penX -= shift
+				}
+			}
+
+			l.glyphs = append(l.glyphs, textGlyph{
+				Glyph: g,
+				r:     r,
+				x:     penX + kern,
+				color: span.color,
+			})
+
+			penX += kern + g.Advance
+			prev = r
+			if space {
+				breakAt = len(l.glyphs)
+			}
+		}
+	}
+	if lineStart < len(l.glyphs) || len(l.lines) != 0 {
+		l.endLine(lineStart, len(l.glyphs))
+	}
+
+	l.boxW = l.w
+	if maxWidth > l.boxW {
+		l.boxW = maxWidth
+	}
+
+	lineHeight := font.LineHeight()
+	if n := int32(len(l.lines)); n != 0 {
+		l.h = (n * lineHeight) + ((n - 1) * lineSpacing)
+	}
+
+	for i, line := range l.lines {
+		var x, y float64
+		align.Values(to, &x, &y, 0, 0, float64(l.boxW-line.width), 0)
+
+		top := int32(i) * (lineHeight + lineSpacing)
+		for j := line.start; j < line.end; j++ {
+			l.glyphs[j].x += int32(x)
+			l.glyphs[j].y = top
+		}
+	}
+
+	return l, err
+}
+
+// endLine adds a line containing the glyphs from start up to end. Trailing
+// whitespace does not count towards the line's width.
+func (l *textLayout) endLine(start, end int) {
+	line := textLine{start: start, end: end}
+	for i := end - 1; i >= start; i-- {
+		g := l.glyphs[i]
+		if unicode.IsSpace(g.r) {
+			continue
+		}
+
+		line.width = g.x + g.OffsetX + g.Location.W
+		if adv := g.x + g.Advance; adv > line.width {
+			line.width = adv
+		}
+		break
+	}
+
+	if line.width > l.w {
+		l.w = line.width
+	}
+	l.lines = append(l.lines, line)
+}
+
+// MeasureText returns the width and height of text when drawn with font.
+// Lines wider than maxWidth are wrapped, a maxWidth of 0 disables wrapping.
+// Inline color markup does not count towards the text's size.
+func MeasureText(font sdlkit.GlyphFont, text string, maxWidth, lineSpacing int32) (w, h int32, err error) {
+	l, err := layoutText(font, parseMarkup(text, sdl.Color{}), maxWidth, lineSpacing, 0)
+	return l.w, l.h, err
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package display
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom/align"
+)
+
+// monoFont is a GlyphFont where all glyphs are 10 pixels wide.
+type monoFont struct{}
+
+func (monoFont) Glyph(r rune) (sdlkit.Glyph, error) {
+	var g sdlkit.Glyph
+	g.Advance = 10
+	if r != ' ' {
+		g.Location = sdl.Rect{W: 10, H: 20}
+	}
+	return g, nil
+}
+
+func (monoFont) Kerning(_, _ rune) int32 { return 0 }
+func (monoFont) LineHeight() int32       { return 20 }
+
+func TestParseMarkup(t *testing.T) {
+	red := sdl.Color{R: 0xff, A: 0xff}
+	blue := sdl.Color{B: 0xff, A: 0x80}
+	def := sdl.Color{R: 1, G: 2, B: 3, A: 4}
+
+	tests := map[string]struct {
+		input string
+		want  []textSpan
+	}{
+		"plain": {
+			input: "hello",
+			want:  []textSpan{{"hello", def}},
+		},
+		"color": {
+			input: "a[color=#ff0000]b[/color]c",
+			want:  []textSpan{{"a", def}, {"b", red}, {"c", def}},
+		},
+		"nested": {
+			input: "[color=#ff0000]a[color=#0000ff80]b[/color]c[/color]",
+			want:  []textSpan{{"a", red}, {"b", blue}, {"c", red}},
+		},
+		"escaped": {
+			input: "[[color=#ff0000]",
+			want:  []textSpan{{"[color=#ff0000]", def}},
+		},
+		"invalid": {
+			input: "[color=red]a[/color]",
+			want:  []textSpan{{"[color=red]a[/color]", def}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, parseMarkup(tc.input, def))
+		})
+	}
+}
+
+func TestMeasureText(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		maxWidth int32
+		wantW    int32
+		wantH    int32
+	}{
+		"empty":          {"", 0, 0, 0},
+		"single line":    {"abc", 0, 30, 20},
+		"newlines":       {"abc\nab\n", 0, 30, 3*20 + 2*5},
+		"word wrap":      {"abc abc abc", 75, 70, 2*20 + 5},
+		"trailing space": {"ab   ", 0, 20, 20},
+		"long word":      {"abcdefgh", 35, 30, 3*20 + 2*5},
+		"markup":         {"[color=#ff0000]ab[/color]c", 0, 30, 20},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			w, h, err := MeasureText(monoFont{}, tc.input, tc.maxWidth, 5)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantW, w, "width")
+			assert.Equal(t, tc.wantH, h, "height")
+		})
+	}
+}
+
+func TestLayoutText_Alignment(t *testing.T) {
+	spans := []textSpan{{text: "abcd ab"}}
+	tests := map[align.Alignment][2]int32{
+		align.ToLeft:   {0, 0},
+		align.ToCenter: {0, 10},
+		align.ToRight:  {0, 20},
+	}
+
+	for to, want := range tests {
+		l, err := layoutText(monoFont{}, spans, 40, 0, to)
+		assert.NoError(t, err)
+		if assert.Len(t, l.lines, 2) {
+			assert.Equal(t, want[0], l.glyphs[l.lines[0].start].x)
+			assert.Equal(t, want[1], l.glyphs[l.lines[1].start].x)
+			assert.Equal(t, int32(20), l.glyphs[l.lines[1].start].y)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package display
 
 import (
+	"math"
+
+	"github.com/go-pogo/errors"
 	"github.com/veandco/go-sdl2/sdl"
 
 	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
@@ -13,17 +16,24 @@ const (
 	StretchFit StretchMode = iota
 	// Tile inside the target's area, starting at the top left.
 	StretchTile
+	// Keep the original size, at the top left of the target's area.
+	StretchKeep
+	// Keep the original size, centered within the target's area.
+	StretchKeepCentered
+	// Scale to fit the target's area while maintaining the aspect ratio, at
+	// the top left of the target's area.
+	StretchKeepAspect
+	// Scale to fit the target's area while maintaining the aspect ratio,
+	// centered within the target's area.
+	StretchKeepAspectCentered
+	// Scale so the shorter side fits the target's area while maintaining the
+	// aspect ratio. The other side is centered and clipped.
+	StretchKeepAspectCovered
+
+	// Scale to fit the target's size, same as StretchFit.
+	StretchScale = StretchFit
 )
 
-// STRETCH_SCALE_ON_EXPAND = 0 --- Scale to fit the node's bounding rectangle, only if expand is true. Default stretch_mode, for backwards compatibility. Until you set expand to true, the texture will behave like STRETCH_KEEP.
-// STRETCH_SCALE = 1 --- Scale to fit the node's bounding rectangle.
-// STRETCH_TILE = 2 --- Tile inside the node's bounding rectangle.
-// STRETCH_KEEP = 3 --- The texture keeps its original size and stays in the bounding rectangle's top-left corner.
-// STRETCH_KEEP_CENTERED = 4 --- The texture keeps its original size and stays centered in the node's bounding rectangle.
-// STRETCH_KEEP_ASPECT = 5 --- Scale the texture to fit the node's bounding rectangle, but maintain the texture's aspect ratio.
-// STRETCH_KEEP_ASPECT_CENTERED = 6 --- Scale the texture to fit the node's bounding rectangle, center it and maintain its aspect ratio.
-// STRETCH_KEEP_ASPECT_COVERED = 7 --- Scale the texture so that the shorter side fits the bounding rectangle. The other side clips to the node's limits.
-
 type Tile struct {
 	X, Y,
 	W, H float64
@@ -33,10 +43,14 @@ type Tile struct {
 	clip sdlkit.TextureClip
 }
 
+// NewTile creates a Tile with the original size of clip. A trimmed or rotated
+// clip can not be drawn with StretchTile, nor with a StretchMode which clips
+// part of it. Drawing it that way adds an error to the Canvas instead.
 func NewTile(clip sdlkit.TextureClip) *Tile {
+	w, h := clip.Size()
 	return &Tile{
-		W:    float64(clip.Location.W),
-		H:    float64(clip.Location.H),
+		W:    w,
+		H:    h,
 		clip: clip,
 	}
 }
@@ -57,97 +71,159 @@ func (s *Tile) SetY(y float64) { s.Y = y }
 func (s *Tile) Clip() sdlkit.TextureClip { return s.clip }
 
 func (s *Tile) Draw(canvas *sdlkit.Canvas) {
-	switch s.StretchMode {
-	case StretchTile:
-		drawStretchTile(canvas, s.clip, sdl.Rect{
-			X: int32(s.X - (s.W / 2)),
-			Y: int32(s.Y - (s.H / 2)),
-			W: int32(s.W),
-			H: int32(s.H),
-		})
-
-	case StretchFit:
-		fallthrough
+	dest := sdl.Rect{
+		X: int32(s.X - (s.W / 2)),
+		Y: int32(s.Y - (s.H / 2)),
+		W: int32(s.W),
+		H: int32(s.H),
+	}
+
+	if s.StretchMode == StretchTile {
+		drawStretchTile(canvas, s.clip, dest)
+		return
+	}
+
+	if !isTransformedClip(s.clip) {
+		src, dst := stretchRects(s.StretchMode, s.clip.Location, dest)
+		if dst.W > 0 && dst.H > 0 {
+			canvas.DrawTexture(s.clip.Texture, &src, dst)
+		}
+		return
+	}
+
+	// the clip transform can only draw the whole original frame
+	w, h := s.clip.Size()
+	loc := sdl.Rect{W: int32(w), H: int32(h)}
+	src, dst := stretchRects(s.StretchMode, loc, dest)
+	if src != loc {
+		canvas.CatchErr(errTransformedClip("Tile"))
+		return
+	}
+	if dst.W > 0 && dst.H > 0 {
+		canvas.DrawTextureClip(s.clip, dst)
+	}
+}
+
+func isTransformedClip(clip sdlkit.TextureClip) bool {
+	return clip.IsTrimmed() || clip.IsRotated()
+}
+
+// errTransformedClip returns the error for when only a part of a trimmed or
+// rotated TextureClip should be drawn. Such a part can not be taken from its
+// Location.
+func errTransformedClip(kind string) error {
+	return errors.Newf("display: %s can not draw part of a trimmed or rotated TextureClip", kind)
+}
+
+// stretchRects returns the source and destination rects to draw loc within
+// dest, according to mode. StretchTile is not supported.
+func stretchRects(mode StretchMode, loc, dest sdl.Rect) (sdl.Rect, sdl.Rect) {
+	if loc.W <= 0 || loc.H <= 0 {
+		return loc, sdl.Rect{}
+	}
+
+	sx := float64(dest.W) / float64(loc.W)
+	sy := float64(dest.H) / float64(loc.H)
+
+	switch mode {
+	case StretchKeep:
+		return placeRect(loc, dest, 1, false)
+	case StretchKeepCentered:
+		return placeRect(loc, dest, 1, true)
+	case StretchKeepAspect:
+		return placeRect(loc, dest, math.Min(sx, sy), false)
+	case StretchKeepAspectCentered:
+		return placeRect(loc, dest, math.Min(sx, sy), true)
+	case StretchKeepAspectCovered:
+		return placeRect(loc, dest, math.Max(sx, sy), true)
 	default:
-		canvas.DrawTextureClip(s.clip, sdl.Rect{
-			X: int32(s.X - (s.W / 2)),
-			Y: int32(s.Y - (s.H / 2)),
-			W: int32(s.W),
-			H: int32(s.H),
-		})
+		return loc, dest
 	}
 }
 
+// placeRect places loc, scaled by scale, within dest. It is either aligned to
+// the top left or centered. The parts which fall outside dest are clipped,
+// both from the destination and source rects.
+func placeRect(loc, dest sdl.Rect, scale float64, centered bool) (sdl.Rect, sdl.Rect) {
+	if scale <= 0 {
+		return loc, sdl.Rect{}
+	}
+
+	w, h := float64(loc.W)*scale, float64(loc.H)*scale
+	var x, y float64
+	if centered {
+		x = (float64(dest.W) - w) / 2
+		y = (float64(dest.H) - h) / 2
+	}
+
+	// visible part, relative to dest
+	x0, y0 := math.Max(x, 0), math.Max(y, 0)
+	x1, y1 := math.Min(x+w, float64(dest.W)), math.Min(y+h, float64(dest.H))
+	if x1 <= x0 || y1 <= y0 {
+		return loc, sdl.Rect{}
+	}
+
+	src := sdl.Rect{
+		X: loc.X + int32(math.Round((x0-x)/scale)),
+		Y: loc.Y + int32(math.Round((y0-y)/scale)),
+		W: int32(math.Round((x1 - x0) / scale)),
+		H: int32(math.Round((y1 - y0) / scale)),
+	}
+	dst := sdl.Rect{
+		X: dest.X + int32(math.Round(x0)),
+		Y: dest.Y + int32(math.Round(y0)),
+		W: int32(math.Round(x1 - x0)),
+		H: int32(math.Round(y1 - y0)),
+	}
+	return src, dst
+}
+
+// drawStretchTile tiles the clip inside dest, starting at its top left. Only
+// the tiles which are visible to the Canvas' Camera are drawn. Trimmed or
+// rotated clips are not supported.
 func drawStretchTile(canvas *sdlkit.Canvas, clip sdlkit.TextureClip, dest sdl.Rect) {
-	nx := (dest.W / clip.Location.W) * clip.Location.W
-	ny := (dest.H / clip.Location.H) * clip.Location.H
-
-	// remaining
-	rw := dest.W - nx
-	rh := dest.H - ny
-
-	var x, y int32
-	for ; y < ny; y += clip.Location.H {
-		for x = 0; x < nx; x += clip.Location.W {
-			canvas.DrawTextureClip(clip, sdl.Rect{
-				X: x + dest.X,
-				Y: y + dest.Y,
-				W: clip.Location.W,
-				H: clip.Location.H,
-			})
-		}
+	if isTransformedClip(clip) {
+		canvas.CatchErr(errTransformedClip("StretchTile"))
+		return
+	}
 
-		if rw != 0 {
-			canvas.DrawTexture(clip.Texture,
-				&sdl.Rect{
-					X: clip.Location.X,
-					Y: clip.Location.Y,
-					W: rw,
-					H: clip.Location.H,
-				},
-				sdl.Rect{
-					X: x + dest.X,
-					Y: y + dest.Y,
-					W: rw,
-					H: clip.Location.H,
-				},
-			)
-		}
+	view := dest
+	if cam := canvas.Camera(); cam.IsEnabled() {
+		view = cam.View()
 	}
 
-	if rh != 0 {
-		for x = 0; x < nx; x += clip.Location.W {
-			canvas.DrawTexture(clip.Texture,
-				&sdl.Rect{
-					X: clip.Location.X,
-					Y: clip.Location.Y,
-					W: clip.Location.W,
-					H: rh,
-				},
-				sdl.Rect{
-					X: x + dest.X,
-					Y: y + dest.Y,
-					W: clip.Location.W,
-					H: rh,
-				},
-			)
-		}
+	eachStretchTile(clip.Location, dest, view, func(src, dst sdl.Rect) {
+		canvas.DrawTexture(clip.Texture, &src, dst)
+	})
+}
+
+// eachStretchTile calls fn with the source and destination rect of each tile
+// of loc inside dest which overlaps view. The tiles are aligned to the top
+// left of dest, tiles at the edges of dest or view are clipped.
+func eachStretchTile(loc, dest, view sdl.Rect, fn func(src, dst sdl.Rect)) {
+	if loc.W <= 0 || loc.H <= 0 {
+		return
+	}
+
+	area, ok := dest.Intersect(&view)
+	if !ok {
+		return
+	}
 
-		if rw != 0 {
-			canvas.DrawTexture(clip.Texture,
-				&sdl.Rect{
-					X: clip.Location.X,
-					Y: clip.Location.Y,
-					W: rw,
-					H: rh,
-				},
-				sdl.Rect{
-					X: x + dest.X,
-					Y: y + dest.Y,
-					W: rw,
-					H: rh,
-				},
-			)
+	// start at the first tile which overlaps the visible area
+	x0 := dest.X + ((area.X-dest.X)/loc.W)*loc.W
+	y0 := dest.Y + ((area.Y-dest.Y)/loc.H)*loc.H
+
+	for y := y0; y < area.Y+area.H; y += loc.H {
+		for x := x0; x < area.X+area.W; x += loc.W {
+			tile := sdl.Rect{X: x, Y: y, W: loc.W, H: loc.H}
+			dst, _ := tile.Intersect(&area)
+			fn(sdl.Rect{
+				X: loc.X + dst.X - x,
+				Y: loc.Y + dst.Y - y,
+				W: dst.W,
+				H: dst.H,
+			}, dst)
 		}
 	}
 }
@@ -0,0 +1,77 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package display
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func TestStretchRects(t *testing.T) {
+	loc := sdl.Rect{X: 10, Y: 20, W: 40, H: 20}
+	dest := sdl.Rect{X: 100, Y: 100, W: 100, H: 80}
+
+	tests := map[StretchMode][2]sdl.Rect{
+		StretchFit:                {loc, dest},
+		StretchKeep:               {loc, {X: 100, Y: 100, W: 40, H: 20}},
+		StretchKeepCentered:       {loc, {X: 130, Y: 130, W: 40, H: 20}},
+		StretchKeepAspect:         {loc, {X: 100, Y: 100, W: 100, H: 50}},
+		StretchKeepAspectCentered: {loc, {X: 100, Y: 115, W: 100, H: 50}},
+		// scaled by 4 to 160x80, of which 100x80 is visible
+		StretchKeepAspectCovered: {{X: 18, Y: 20, W: 25, H: 20}, dest},
+	}
+
+	for mode, want := range tests {
+		src, dst := stretchRects(mode, loc, dest)
+		assert.Equal(t, want[0], src, "src of mode %d", mode)
+		assert.Equal(t, want[1], dst, "dest of mode %d", mode)
+	}
+}
+
+func TestStretchRects_clip(t *testing.T) {
+	loc := sdl.Rect{W: 40, H: 20}
+	dest := sdl.Rect{X: 5, Y: 5, W: 20, H: 10}
+
+	src, dst := stretchRects(StretchKeep, loc, dest)
+	assert.Equal(t, sdl.Rect{W: 20, H: 10}, src)
+	assert.Equal(t, dest, dst)
+
+	src, dst = stretchRects(StretchKeepCentered, loc, dest)
+	assert.Equal(t, sdl.Rect{X: 10, Y: 5, W: 20, H: 10}, src)
+	assert.Equal(t, dest, dst)
+}
+
+func TestEachStretchTile(t *testing.T) {
+	type pair struct{ src, dst sdl.Rect }
+	collect := func(loc, dest, view sdl.Rect) []pair {
+		var res []pair
+		eachStretchTile(loc, dest, view, func(src, dst sdl.Rect) {
+			res = append(res, pair{src, dst})
+		})
+		return res
+	}
+
+	loc := sdl.Rect{X: 100, Y: 0, W: 10, H: 10}
+	dest := sdl.Rect{X: 0, Y: 0, W: 25, H: 15}
+
+	assert.Equal(t, []pair{
+		{sdl.Rect{X: 100, W: 10, H: 10}, sdl.Rect{X: 0, W: 10, H: 10}},
+		{sdl.Rect{X: 100, W: 10, H: 10}, sdl.Rect{X: 10, W: 10, H: 10}},
+		{sdl.Rect{X: 100, W: 5, H: 10}, sdl.Rect{X: 20, W: 5, H: 10}},
+		{sdl.Rect{X: 100, W: 10, H: 5}, sdl.Rect{X: 0, Y: 10, W: 10, H: 5}},
+		{sdl.Rect{X: 100, W: 10, H: 5}, sdl.Rect{X: 10, Y: 10, W: 10, H: 5}},
+		{sdl.Rect{X: 100, W: 5, H: 5}, sdl.Rect{X: 20, Y: 10, W: 5, H: 5}},
+	}, collect(loc, dest, dest))
+
+	// only the tiles within view are drawn, clipped at its edges
+	assert.Equal(t, []pair{
+		{sdl.Rect{X: 104, Y: 3, W: 6, H: 2}, sdl.Rect{X: 14, Y: 3, W: 6, H: 2}},
+		{sdl.Rect{X: 100, Y: 3, W: 1, H: 2}, sdl.Rect{X: 20, Y: 3, W: 1, H: 2}},
+	}, collect(loc, dest, sdl.Rect{X: 14, Y: 3, W: 7, H: 2}))
+
+	assert.Empty(t, collect(loc, dest, sdl.Rect{X: 30, Y: 30, W: 10, H: 10}))
+}
@@ -0,0 +1,292 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package display
+
+import (
+	"math"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+type GridOrientation uint8
+
+const (
+	GridOrthogonal GridOrientation = iota
+	// GridIsometric is a diamond shaped isometric grid. The top corner of
+	// cell 0, 0 is at Height*TileW/2, so the left corner of the last row is
+	// at the origin, like in Tiled.
+	GridIsometric
+	// GridStaggered is an isometric grid where every other row (or column)
+	// is shifted by half a tile, resulting in a rectangular map.
+	GridStaggered
+	// GridHexagonal is a staggered grid of hexagons.
+	GridHexagonal
+)
+
+// StaggerAxis determines which rows or columns of a staggered or hexagonal
+// grid are shifted.
+type StaggerAxis uint8
+
+const (
+	// StaggerY shifts every other row horizontally. Hexagons have a pointy
+	// top.
+	StaggerY StaggerAxis = iota
+	// StaggerX shifts every other column vertically. Hexagons have a flat
+	// top.
+	StaggerX
+)
+
+// StaggerIndex determines if the odd or even rows (or columns) are shifted.
+type StaggerIndex uint8
+
+const (
+	StaggerOdd StaggerIndex = iota
+	StaggerEven
+)
+
+// GridCell is the position of a cell within a Grid.
+type GridCell struct {
+	X, Y int32
+}
+
+// Grid describes the layout of the cells of a TileMap. Its zero values for
+// StaggerAxis and StaggerIndex match the defaults of Tiled.
+type Grid struct {
+	Orientation GridOrientation
+	// TileW and TileH are the size of the bounding box of a cell.
+	TileW, TileH int32
+
+	StaggerAxis  StaggerAxis
+	StaggerIndex StaggerIndex
+	// HexSideLength is the length of the flat sides of a hexagon, along the
+	// StaggerAxis.
+	HexSideLength int32
+	// Height is the amount of rows of an isometric map, which determines the
+	// position of cell 0, 0. NewGridTileMap sets it when it is 0.
+	Height int32
+}
+
+// isoOriginX returns the x position of the top corner of cell 0, 0 of an
+// isometric grid.
+func (g Grid) isoOriginX() float64 {
+	return float64(g.Height) * float64(g.TileW) / 2
+}
+
+// staggered indicates if row or column i is shifted.
+func (g Grid) staggered(i int32) bool {
+	return (i&1 == 1) != (g.StaggerIndex == StaggerEven)
+}
+
+// staggerMetrics returns the lengths of the flat sides of a hexagon, and the
+// distance between shifted columns and rows.
+func (g Grid) staggerMetrics() (sideX, sideY, colW, rowH int32) {
+	if g.Orientation == GridHexagonal {
+		if g.StaggerAxis == StaggerX {
+			sideX = g.HexSideLength
+		} else {
+			sideY = g.HexSideLength
+		}
+	}
+
+	colW = (g.TileW-sideX)/2 + sideX
+	rowH = (g.TileH-sideY)/2 + sideY
+	return
+}
+
+// CellRect returns the bounding box of cell x, y, relative to the origin of
+// the Grid.
+func (g Grid) CellRect(x, y int32) sdl.Rect {
+	res := sdl.Rect{W: g.TileW, H: g.TileH}
+	switch g.Orientation {
+	case GridIsometric:
+		// halve the tile size before truncating, so odd sizes and negative
+		// cells are rounded the same way
+		hw, hh := float64(g.TileW)/2, float64(g.TileH)/2
+		res.X = int32(math.Floor(float64(x-y-1)*hw + g.isoOriginX()))
+		res.Y = int32(math.Floor(float64(x+y) * hh))
+
+	case GridStaggered, GridHexagonal:
+		sideX, sideY, colW, rowH := g.staggerMetrics()
+		if g.StaggerAxis == StaggerX {
+			res.X = x * colW
+			res.Y = y * (g.TileH + sideY)
+			if g.staggered(x) {
+				res.Y += rowH
+			}
+		} else {
+			res.X = x * (g.TileW + sideX)
+			res.Y = y * rowH
+			if g.staggered(y) {
+				res.X += colW
+			}
+		}
+
+	default:
+		res.X = x * g.TileW
+		res.Y = y * g.TileH
+	}
+	return res
+}
+
+// CellCenter returns the center of cell x, y, relative to the origin of the
+// Grid.
+func (g Grid) CellCenter(x, y int32) (float64, float64) {
+	r := g.CellRect(x, y)
+	return float64(r.X) + float64(r.W)/2, float64(r.Y) + float64(r.H)/2
+}
+
+// WorldToCell returns the cell which contains position x, y, relative to the
+// origin of the Grid.
+func (g Grid) WorldToCell(x, y float64) (int32, int32) {
+	if g.TileW <= 0 || g.TileH <= 0 {
+		return 0, 0
+	}
+
+	tw, th := float64(g.TileW), float64(g.TileH)
+	switch g.Orientation {
+	case GridIsometric:
+		tx, ty := (x-g.isoOriginX())/(tw/2), y/(th/2)
+		return int32(math.Floor((ty + tx) / 2)), int32(math.Floor((ty - tx) / 2))
+
+	case GridStaggered, GridHexagonal:
+		sideX, sideY, colW, rowH := g.staggerMetrics()
+
+		var cx, cy int32
+		if g.StaggerAxis == StaggerX {
+			cx = int32(math.Floor(x / float64(colW)))
+			sy := y
+			if g.staggered(cx) {
+				sy -= float64(rowH)
+			}
+			cy = int32(math.Floor(sy / float64(g.TileH+sideY)))
+		} else {
+			cy = int32(math.Floor(y / float64(rowH)))
+			sx := x
+			if g.staggered(cy) {
+				sx -= float64(colW)
+			}
+			cx = int32(math.Floor(sx / float64(g.TileW+sideX)))
+		}
+
+		// the shape of a cell is not a rectangle, the position may be
+		// within one of the cells around the approximated cell
+		for ny := cy - 1; ny <= cy+1; ny++ {
+			for nx := cx - 1; nx <= cx+1; nx++ {
+				if g.inStaggeredCell(nx, ny, x, y) {
+					return nx, ny
+				}
+			}
+		}
+		return cx, cy
+
+	default:
+		return int32(math.Floor(x / tw)), int32(math.Floor(y / th))
+	}
+}
+
+// inStaggeredCell indicates if position x, y is within the diamond or hexagon
+// shape of cell cx, cy.
+func (g Grid) inStaggeredCell(cx, cy int32, x, y float64) bool {
+	r := g.CellRect(cx, cy)
+	hw, hh := float64(r.W)/2, float64(r.H)/2
+	dx := math.Abs(x - float64(r.X) - hw)
+	dy := math.Abs(y - float64(r.Y) - hh)
+	if dx > hw || dy > hh {
+		return false
+	}
+
+	sideX, sideY, _, _ := g.staggerMetrics()
+	if g.StaggerAxis == StaggerX {
+		return dx <= hw-(hw-float64(sideX)/2)*dy/hh
+	}
+	return dy <= hh-(hh-float64(sideY)/2)*dx/hw
+}
+
+// Neighbours returns the cells which share an edge with cell x, y. The
+// returned cells may be outside the bounds of a TileMap.
+func (g Grid) Neighbours(x, y int32) []GridCell {
+	switch g.Orientation {
+	case GridStaggered, GridHexagonal:
+		hex := g.Orientation == GridHexagonal
+		if g.StaggerAxis == StaggerX {
+			// shifted columns are moved down
+			dy := int32(-1)
+			if g.staggered(x) {
+				dy = 0
+			}
+			res := []GridCell{
+				{x - 1, y + dy}, {x + 1, y + dy},
+				{x - 1, y + dy + 1}, {x + 1, y + dy + 1},
+			}
+			if hex {
+				res = append(res, GridCell{x, y - 1}, GridCell{x, y + 1})
+			}
+			return res
+		}
+
+		// shifted rows are moved to the right
+		dx := int32(-1)
+		if g.staggered(y) {
+			dx = 0
+		}
+		res := []GridCell{
+			{x + dx, y - 1}, {x + dx + 1, y - 1},
+			{x + dx, y + 1}, {x + dx + 1, y + 1},
+		}
+		if hex {
+			res = append(res, GridCell{x - 1, y}, GridCell{x + 1, y})
+		}
+		return res
+
+	default:
+		return []GridCell{{x, y - 1}, {x + 1, y}, {x, y + 1}, {x - 1, y}}
+	}
+}
+
+// eachCell calls fn for each cell within columns [x0, x1) and rows [y0, y1),
+// ordered from the top of the screen to the bottom, so tiles which are taller
+// than their cell correctly overlap the cells behind them.
+func (g Grid) eachCell(x0, y0, x1, y1 int32, fn func(x, y int32)) {
+	if x0 >= x1 || y0 >= y1 {
+		return
+	}
+
+	switch {
+	case g.Orientation == GridIsometric:
+		// cells on the same diagonal share the same screen row
+		for s := x0 + y0; s <= x1+y1-2; s++ {
+			x, end := s-(y1-1), s-y0
+			if x < x0 {
+				x = x0
+			}
+			if end > x1-1 {
+				end = x1 - 1
+			}
+			for ; x <= end; x++ {
+				fn(x, s-x)
+			}
+		}
+
+	case g.StaggerAxis == StaggerX &&
+		(g.Orientation == GridStaggered || g.Orientation == GridHexagonal):
+		// shifted columns are lower than the columns next to them
+		for y := y0; y < y1; y++ {
+			for _, shifted := range [2]bool{false, true} {
+				for x := x0; x < x1; x++ {
+					if g.staggered(x) == shifted {
+						fn(x, y)
+					}
+				}
+			}
+		}
+
+	default:
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				fn(x, y)
+			}
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package display
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+var testGrids = map[string]Grid{
+	"orthogonal": {TileW: 16, TileH: 16},
+	"isometric":  {Orientation: GridIsometric, TileW: 64, TileH: 32},
+	"isometric map": {
+		Orientation: GridIsometric,
+		TileW:       64,
+		TileH:       32,
+		Height:      3,
+	},
+	"isometric odd": {Orientation: GridIsometric, TileW: 31, TileH: 15},
+	"staggered":     {Orientation: GridStaggered, TileW: 64, TileH: 32},
+	"pointy":        {Orientation: GridHexagonal, TileW: 28, TileH: 32, HexSideLength: 16},
+	"flat": {
+		Orientation:   GridHexagonal,
+		TileW:         32,
+		TileH:         28,
+		StaggerAxis:   StaggerX,
+		StaggerIndex:  StaggerEven,
+		HexSideLength: 16,
+	},
+}
+
+func TestGrid_CellRect(t *testing.T) {
+	tests := map[string]map[GridCell]sdl.Rect{
+		"orthogonal": {
+			{0, 0}: {X: 0, Y: 0, W: 16, H: 16},
+			{2, 1}: {X: 32, Y: 16, W: 16, H: 16},
+		},
+		"isometric": {
+			{0, 0}: {X: -32, Y: 0, W: 64, H: 32},
+			{2, 1}: {X: 0, Y: 48, W: 64, H: 32},
+		},
+		"isometric map": {
+			// map of 3 rows, the left corner of row 2 is at the origin
+			{0, 0}: {X: 64, Y: 0, W: 64, H: 32},
+			{0, 2}: {X: 0, Y: 32, W: 64, H: 32},
+		},
+		"isometric odd": {
+			{0, 0}: {X: -16, Y: 0, W: 31, H: 15},
+			{0, 1}: {X: -31, Y: 7, W: 31, H: 15},
+			{1, 0}: {X: 0, Y: 7, W: 31, H: 15},
+		},
+		"staggered": {
+			{0, 1}: {X: 32, Y: 16, W: 64, H: 32},
+			{1, 2}: {X: 64, Y: 32, W: 64, H: 32},
+		},
+		"pointy": {
+			{0, 1}: {X: 14, Y: 24, W: 28, H: 32},
+			{2, 2}: {X: 56, Y: 48, W: 28, H: 32},
+		},
+		"flat": {
+			{0, 0}: {X: 0, Y: 14, W: 32, H: 28},
+			{1, 0}: {X: 24, Y: 0, W: 32, H: 28},
+		},
+	}
+
+	for name, cells := range tests {
+		for cell, want := range cells {
+			assert.Equal(t, want, testGrids[name].CellRect(cell.X, cell.Y), "%s %v", name, cell)
+		}
+	}
+}
+
+func TestGrid_WorldToCell(t *testing.T) {
+	for name, grid := range testGrids {
+		t.Run(name, func(t *testing.T) {
+			for y := int32(-3); y <= 3; y++ {
+				for x := int32(-3); x <= 3; x++ {
+					cx, cy := grid.CellCenter(x, y)
+					gx, gy := grid.WorldToCell(cx, cy)
+					assert.Equal(t, GridCell{x, y}, GridCell{gx, gy})
+				}
+			}
+		})
+	}
+
+	t.Run("corners", func(t *testing.T) {
+		// positions within the bounding box of cell 0, 0, but outside its
+		// shape
+		x, y := testGrids["staggered"].WorldToCell(2, 2)
+		assert.Equal(t, GridCell{-1, -1}, GridCell{x, y})
+
+		x, y = testGrids["pointy"].WorldToCell(1, 1)
+		assert.Equal(t, GridCell{-1, -1}, GridCell{x, y})
+	})
+}
+
+func TestGrid_Neighbours(t *testing.T) {
+	tests := map[string]map[GridCell][]GridCell{
+		"isometric": {
+			{2, 1}: {{2, 0}, {3, 1}, {2, 2}, {1, 1}},
+		},
+		"staggered": {
+			{1, 1}: {{1, 0}, {2, 0}, {1, 2}, {2, 2}},
+			{1, 2}: {{0, 1}, {1, 1}, {0, 3}, {1, 3}},
+		},
+		"pointy": {
+			{1, 1}: {{1, 0}, {2, 0}, {1, 2}, {2, 2}, {0, 1}, {2, 1}},
+		},
+		"flat": {
+			{1, 1}: {{0, 0}, {2, 0}, {0, 1}, {2, 1}, {1, 0}, {1, 2}},
+		},
+	}
+
+	for name, cells := range tests {
+		for cell, want := range cells {
+			assert.Equal(t, want, testGrids[name].Neighbours(cell.X, cell.Y), "%s %v", name, cell)
+		}
+	}
+}
+
+func TestGrid_eachCell(t *testing.T) {
+	tests := map[string]struct {
+		x1, y1 int32
+		want   []GridCell
+	}{
+		"isometric": {2, 2, []GridCell{{0, 0}, {0, 1}, {1, 0}, {1, 1}}},
+		"flat":      {3, 1, []GridCell{{1, 0}, {0, 0}, {2, 0}}},
+		"pointy":    {2, 2, []GridCell{{0, 0}, {1, 0}, {0, 1}, {1, 1}}},
+	}
+
+	for name, tc := range tests {
+		var have []GridCell
+		testGrids[name].eachCell(0, 0, tc.x1, tc.y1, func(x, y int32) {
+			have = append(have, GridCell{x, y})
+		})
+		assert.Equal(t, tc.want, have, name)
+	}
+}
+
+func TestTileMap_Neighbours(t *testing.T) {
+	tm := NewGridTileMap(2, 2, testGrids["isometric"])
+	tm.X = 100
+
+	assert.Equal(t, []GridCell{{1, 0}, {0, 1}}, tm.Neighbours(0, 0))
+
+	x, y := tm.CellToWorld(1, 0)
+	assert.Equal(t, [2]float64{132, 32}, [2]float64{x, y})
+
+	cx, cy := tm.WorldToCell(x, y)
+	assert.Equal(t, GridCell{1, 0}, GridCell{cx, cy})
+}
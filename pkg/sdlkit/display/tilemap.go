@@ -1,40 +1,501 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
 package display
 
 import (
+	"math"
+
 	"github.com/go-pogo/errors"
 	"github.com/veandco/go-sdl2/sdl"
 
 	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom"
+)
+
+// TileID is the global id of a tile within a TileMap. Its highest bits are
+// used as flags to flip the tile, the same way Tiled stores them.
+type TileID uint32
+
+const (
+	TileFlipH TileID = 0x80000000
+	TileFlipV TileID = 0x40000000
+	// TileFlipD flips the tile over its diagonal, from top left to bottom
+	// right.
+	TileFlipD TileID = 0x20000000
+
+	tileFlipHex   TileID = 0x10000000 // hexagonal 120 degree rotation, unsupported
+	tileFlagsMask        = TileFlipH | TileFlipV | TileFlipD | tileFlipHex
+)
+
+// GID returns the global id of the tile without its flip flags.
+func (id TileID) GID() uint32 { return uint32(id &^ tileFlagsMask) }
+
+// IsEmpty indicates if there is no tile.
+func (id TileID) IsEmpty() bool { return id.GID() == 0 }
+
+// Orientation returns the rotation in degrees and sdl.RendererFlip which are
+// needed to draw the tile with its flip flags applied.
+func (id TileID) Orientation() (float64, sdl.RendererFlip) {
+	h, v := id&TileFlipH != 0, id&TileFlipV != 0
+	if id&TileFlipD == 0 {
+		var flip sdl.RendererFlip
+		if h {
+			flip |= sdl.FLIP_HORIZONTAL
+		}
+		if v {
+			flip |= sdl.FLIP_VERTICAL
+		}
+		return 0, flip
+	}
+
+	// a diagonal flip equals a rotation of 90 degrees and a vertical flip
+	switch {
+	case h && v:
+		return 90, sdl.FLIP_HORIZONTAL
+	case h:
+		return 90, sdl.FLIP_NONE
+	case v:
+		return 270, sdl.FLIP_NONE
+	default:
+		return 90, sdl.FLIP_VERTICAL
+	}
+}
+
+// Tileset maps a range of global tile ids, starting at FirstGID, to the
+// locations within Atlas.
+type Tileset struct {
+	Name     string
+	FirstGID uint32
+	Atlas    *sdlkit.TextureAtlas
+	// TileW and TileH are the maximum size of the tiles within Atlas.
+	TileW, TileH int32
+}
+
+// TileLayer is a grid of TileIDs.
+type TileLayer struct {
+	Name    string
+	Opacity float64
+	Visible bool
+	// OffsetX and OffsetY are added to the position of each tile.
+	OffsetX, OffsetY float64
+
+	Properties map[string]string
+
+	width, height int32
+	tiles         []TileID
+}
+
+func NewTileLayer(name string, w, h int32) *TileLayer {
+	return &TileLayer{
+		Name:    name,
+		Opacity: 1,
+		Visible: true,
+		width:   w,
+		height:  h,
+		tiles:   make([]TileID, w*h),
+	}
+}
+
+// Size returns the number of columns and rows of the TileLayer.
+func (l *TileLayer) Size() (int32, int32) { return l.width, l.height }
+
+// Tiles returns all TileIDs, row by row.
+func (l *TileLayer) Tiles() []TileID { return l.tiles }
+
+func (l *TileLayer) InBounds(x, y int32) bool {
+	return x >= 0 && y >= 0 && x < l.width && y < l.height
+}
+
+// At returns the TileID at column x and row y. Positions outside the
+// TileLayer are empty.
+func (l *TileLayer) At(x, y int32) TileID {
+	if !l.InBounds(x, y) {
+		return 0
+	}
+	return l.tiles[y*l.width+x]
+}
+
+func (l *TileLayer) Set(x, y int32, id TileID) {
+	if l.InBounds(x, y) {
+		l.tiles[y*l.width+x] = id
+	}
+}
+
+type ObjectShape uint8
+
+const (
+	ShapeRect ObjectShape = iota
+	ShapePoint
+	ShapeEllipse
+	ShapePolygon
+	ShapePolyline
 )
 
+// MapObject is an object placed on a TileMap, eg. a spawn point or collision
+// area. Its position and size are in pixels, relative to the TileMap.
+type MapObject struct {
+	ID         int
+	Name, Type string
+
+	X, Y, W, H float64
+	Rotation   float64 // in degrees, clockwise
+	Visible    bool
+
+	Shape ObjectShape
+	// Points contains the points of a polygon or polyline, relative to X
+	// and Y.
+	Points []geom.Point
+	// GID is the tile of a tile object, which is aligned to the bottom left
+	// of the object.
+	GID TileID
+
+	Properties map[string]string
+}
+
+// ObjectLayer is a list of MapObjects. ObjectLayers are not drawn, they are
+// meant to be used by game code, eg. to spawn entities.
+type ObjectLayer struct {
+	Name       string
+	Opacity    float64
+	Visible    bool
+	Objects    []*MapObject
+	Properties map[string]string
+}
+
+// Object returns the first MapObject with name.
+func (l *ObjectLayer) Object(name string) *MapObject {
+	for _, obj := range l.Objects {
+		if obj.Name == name {
+			return obj
+		}
+	}
+	return nil
+}
+
+// ObjectsOfType returns all MapObjects of type typ.
+func (l *ObjectLayer) ObjectsOfType(typ string) []*MapObject {
+	var res []*MapObject
+	for _, obj := range l.Objects {
+		if obj.Type == typ {
+			res = append(res, obj)
+		}
+	}
+	return res
+}
+
+// TileMap is a grid of tiles with one or more TileLayers, which are drawn in
+// order. The origin of its Grid is positioned at X, Y.
 type TileMap struct {
-	atlas *sdlkit.TextureAtlas
-	tiles []sdl.Rect
+	X, Y float64
+
+	Properties map[string]string
+
+	width, height int32
+	grid          Grid
+	tilesets      []*Tileset
+	layers        []*TileLayer
+	objects       []*ObjectLayer
+}
+
+// NewTileMap creates a new orthogonal TileMap of w columns and h rows, with
+// cells of tileW x tileH pixels.
+func NewTileMap(w, h, tileW, tileH int32, tilesets ...*Tileset) *TileMap {
+	return NewGridTileMap(w, h, Grid{TileW: tileW, TileH: tileH}, tilesets...)
 }
 
-func NewTileMap(atlas *sdlkit.TextureAtlas) (*TileMap, error) {
-	if !atlas.IsUniform() {
-		return nil, errors.Newf("display: TileMap only works with a uniform TextureAtlas")
+// NewGridTileMap creates a new TileMap of w columns and h rows, with its cells
+// laid out according to grid. The Height of grid is set to h when it is 0.
+func NewGridTileMap(w, h int32, grid Grid, tilesets ...*Tileset) *TileMap {
+	if grid.Height == 0 {
+		grid.Height = h
 	}
 
-	return &TileMap{
-		atlas: atlas,
-	}, nil
+	tm := &TileMap{
+		width:  w,
+		height: h,
+		grid:   grid,
+	}
+	for _, ts := range tilesets {
+		tm.AddTileset(ts)
+	}
+	return tm
 }
 
-func (tm *TileMap) Render(ren *sdl.Renderer) error {
-	texture := tm.atlas.Texture()
-	clip, _ := tm.atlas.GetFomIndex(20)
-	var x, y int32
-	for ; y < 6; y++ {
-		for x = 0; x < 11; x++ {
-			ren.Copy(texture, &clip.Location, &sdl.Rect{
-				X: clip.Location.W * x,
-				Y: clip.Location.H * y,
-				W: clip.Location.W,
-				H: clip.Location.H,
-			})
+// Size returns the number of columns and rows of the TileMap.
+func (tm *TileMap) Size() (int32, int32) { return tm.width, tm.height }
+
+// TileSize returns the size of a cell in pixels.
+func (tm *TileMap) TileSize() (int32, int32) { return tm.grid.TileW, tm.grid.TileH }
+
+func (tm *TileMap) Grid() Grid { return tm.grid }
+
+// CellToWorld returns the center of cell x, y in world coordinates.
+func (tm *TileMap) CellToWorld(x, y int32) (float64, float64) {
+	cx, cy := tm.grid.CellCenter(x, y)
+	return tm.X + cx, tm.Y + cy
+}
+
+// WorldToCell returns the cell which contains world position x, y. The
+// returned cell may be outside the bounds of the TileMap.
+func (tm *TileMap) WorldToCell(x, y float64) (int32, int32) {
+	return tm.grid.WorldToCell(x-tm.X, y-tm.Y)
+}
+
+// Neighbours returns the cells within the bounds of the TileMap which share
+// an edge with cell x, y.
+func (tm *TileMap) Neighbours(x, y int32) []GridCell {
+	res := tm.grid.Neighbours(x, y)
+	n := 0
+	for _, c := range res {
+		if c.X >= 0 && c.X < tm.width && c.Y >= 0 && c.Y < tm.height {
+			res[n] = c
+			n++
+		}
+	}
+	return res[:n]
+}
+
+func (tm *TileMap) Tilesets() []*Tileset { return tm.tilesets }
+
+// AddTileset adds the Tileset while keeping all Tilesets ordered by their
+// FirstGID.
+func (tm *TileMap) AddTileset(ts *Tileset) {
+	i := len(tm.tilesets)
+	tm.tilesets = append(tm.tilesets, ts)
+	for ; i > 0 && tm.tilesets[i-1].FirstGID > ts.FirstGID; i-- {
+		tm.tilesets[i] = tm.tilesets[i-1]
+	}
+	tm.tilesets[i] = ts
+}
+
+// Tileset returns the Tileset which contains the tile with global id gid.
+func (tm *TileMap) Tileset(gid uint32) *Tileset {
+	for i := len(tm.tilesets) - 1; i >= 0; i-- {
+		if tm.tilesets[i].FirstGID <= gid {
+			return tm.tilesets[i]
+		}
+	}
+	return nil
+}
+
+// Tile returns the TextureClip of the tile with id.
+func (tm *TileMap) Tile(id TileID) (sdlkit.TextureClip, error) {
+	gid := id.GID()
+	ts := tm.Tileset(gid)
+	if gid == 0 || ts == nil {
+		return sdlkit.TextureClip{}, errors.Newf("display: unknown tile id `%d` in TileMap", gid)
+	}
+
+	return ts.Atlas.GetFromIndex(int(gid - ts.FirstGID))
+}
+
+func (tm *TileMap) Layers() []*TileLayer { return tm.layers }
+
+// AddLayer adds a new, empty TileLayer with the size of the TileMap on top of
+// the existing layers.
+func (tm *TileMap) AddLayer(name string) *TileLayer {
+	l := NewTileLayer(name, tm.width, tm.height)
+	tm.layers = append(tm.layers, l)
+	return l
+}
+
+// Layer returns the first TileLayer with name.
+func (tm *TileMap) Layer(name string) *TileLayer {
+	for _, l := range tm.layers {
+		if l.Name == name {
+			return l
+		}
+	}
+	return nil
+}
+
+func (tm *TileMap) ObjectLayers() []*ObjectLayer { return tm.objects }
+
+func (tm *TileMap) AddObjectLayer(l *ObjectLayer) { tm.objects = append(tm.objects, l) }
+
+// ObjectLayer returns the first ObjectLayer with name.
+func (tm *TileMap) ObjectLayer(name string) *ObjectLayer {
+	for _, l := range tm.objects {
+		if l.Name == name {
+			return l
 		}
 	}
 	return nil
 }
+
+// maxTileSize returns the size of the largest tile of all Tilesets. Tiles may
+// be rotated, so both width and height are the same.
+func (tm *TileMap) maxTileSize() int32 {
+	var size int32
+	for _, ts := range tm.tilesets {
+		if ts.TileW > size {
+			size = ts.TileW
+		}
+		if ts.TileH > size {
+			size = ts.TileH
+		}
+	}
+	return size
+}
+
+// overlap returns the number of cells tiles may extend beyond their own cell,
+// when they are larger than the TileMap's cells.
+func (tm *TileMap) overlap() (int32, int32) {
+	size := tm.maxTileSize()
+	tw, th := tm.grid.TileW, tm.grid.TileH
+
+	var x, y int32
+	if size > tw {
+		x = (size - tw + tw - 1) / tw
+	}
+	if size > th {
+		y = (size - th + th - 1) / th
+	}
+	return x, y
+}
+
+// VisibleRange returns the range of columns [x0, x1) and rows [y0, y1) of the
+// TileLayer which are visible to the Camera. When the Camera is nil or
+// disabled the full range is returned. For non orthogonal grids the range may
+// include cells which are not visible.
+func (tm *TileMap) VisibleRange(cam *sdlkit.Camera, l *TileLayer) (x0, y0, x1, y1 int32) {
+	x0, y0, x1, y1 = 0, 0, l.width, l.height
+	if !cam.IsEnabled() || tm.grid.TileW <= 0 || tm.grid.TileH <= 0 {
+		return
+	}
+
+	view := cam.View()
+	left := float64(view.X) - tm.X - l.OffsetX
+	top := float64(view.Y) - tm.Y - l.OffsetY
+	if tm.grid.Orientation != GridOrthogonal {
+		return tm.visibleGridRange(left, top, float64(view.W), float64(view.H), l)
+	}
+
+	tw, th := float64(tm.grid.TileW), float64(tm.grid.TileH)
+
+	// tiles are aligned to the bottom left of their cell, larger tiles
+	// overlap the cells above and to the right of them
+	ox, oy := tm.overlap()
+	x0 = clampCell(int32(math.Floor(left/tw))-ox, l.width)
+	y0 = clampCell(int32(math.Floor(top/th)), l.height)
+	x1 = clampCell(int32(math.Floor((left+float64(view.W))/tw))+1, l.width)
+	y1 = clampCell(int32(math.Floor((top+float64(view.H))/th))+1+oy, l.height)
+	return
+}
+
+// visibleGridRange returns the range of cells which contains all cells
+// overlapping the view at left, top, relative to the origin of the Grid.
+func (tm *TileMap) visibleGridRange(left, top, w, h float64, l *TileLayer) (x0, y0, x1, y1 int32) {
+	// tiles are aligned to the bottom left of their cell, larger tiles
+	// overlap the area above and to the right of their cell
+	right, bottom := left+w, top+h
+	if size := tm.maxTileSize(); size > 0 {
+		if d := float64(size - tm.grid.TileW); d > 0 {
+			left -= d
+		}
+		if d := float64(size - tm.grid.TileH); d > 0 {
+			bottom += d
+		}
+	}
+
+	x0, y0 = tm.grid.WorldToCell(left, top)
+	x1, y1 = x0, y0
+	for _, p := range [3][2]float64{{right, top}, {left, bottom}, {right, bottom}} {
+		x, y := tm.grid.WorldToCell(p[0], p[1])
+		if x < x0 {
+			x0 = x
+		} else if x > x1 {
+			x1 = x
+		}
+		if y < y0 {
+			y0 = y
+		} else if y > y1 {
+			y1 = y
+		}
+	}
+
+	// the bounding boxes of staggered cells overlap their neighbours
+	return clampCell(x0-1, l.width), clampCell(y0-1, l.height),
+		clampCell(x1+2, l.width), clampCell(y1+2, l.height)
+}
+
+func clampCell(i, n int32) int32 {
+	if i < 0 {
+		return 0
+	}
+	if i > n {
+		return n
+	}
+	return i
+}
+
+// Draw draws all visible TileLayers in order. Only the tiles which are
+// visible to the Canvas' Camera are drawn.
+func (tm *TileMap) Draw(canvas *sdlkit.Canvas) {
+	for _, l := range tm.layers {
+		if l.Visible && l.Opacity > 0 {
+			tm.drawLayer(canvas, l)
+		}
+	}
+}
+
+func (tm *TileMap) drawLayer(canvas *sdlkit.Canvas, l *TileLayer) {
+	if l.Opacity < 1 {
+		restore := make([]func() error, 0, len(tm.tilesets))
+		defer func() {
+			for i := len(restore) - 1; i >= 0; i-- {
+				canvas.CatchErr(restore[i]())
+			}
+		}()
+
+		alpha := uint8(l.Opacity * 0xff)
+		for _, ts := range tm.tilesets {
+			tx := ts.Atlas.Texture()
+			prev, err := tx.GetAlphaMod()
+			if err != nil {
+				canvas.CatchErr(err)
+				continue
+			}
+
+			canvas.CatchErr(tx.SetAlphaMod(alpha))
+			restore = append(restore, func() error { return tx.SetAlphaMod(prev) })
+		}
+	}
+
+	ox, oy := int32(tm.X+l.OffsetX), int32(tm.Y+l.OffsetY)
+	x0, y0, x1, y1 := tm.VisibleRange(canvas.Camera(), l)
+	tm.grid.eachCell(x0, y0, x1, y1, func(x, y int32) {
+		id := l.tiles[y*l.width+x]
+		if id.IsEmpty() {
+			return
+		}
+
+		clip, err := tm.Tile(id)
+		if err != nil {
+			canvas.CatchErr(err)
+			return
+		}
+
+		// size of the original frame, DrawTextureClipEx undoes its trim
+		// and rotation within the tileset's texture
+		sw, sh := clip.Size()
+		w, h := int32(sw), int32(sh)
+		fw, fh := w, h // size of the tile after it is flipped
+		if id&TileFlipD != 0 {
+			fw, fh = h, w
+		}
+
+		cell := tm.grid.CellRect(x, y)
+		cx := ox + cell.X + fw/2
+		cy := oy + cell.Y + cell.H - fh/2
+		deg, flip := id.Orientation()
+		canvas.DrawTextureClipEx(clip,
+			sdl.Rect{X: cx - w/2, Y: cy - h/2, W: w, H: h},
+			deg,
+			sdl.Point{X: w / 2, Y: h / 2},
+			flip,
+		)
+	})
+}
@@ -0,0 +1,272 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package display
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom"
+)
+
+const testTmx = `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.5" orientation="orthogonal" renderorder="right-down" width="3" height="2" tilewidth="16" tileheight="16" infinite="0">
+ <properties>
+  <property name="music" value="level1.ogg"/>
+ </properties>
+ <tileset firstgid="1" source="tiles/terrain.tsx"/>
+ <tileset firstgid="5" name="props" tilewidth="16" tileheight="32" tilecount="2" columns="2" margin="1" spacing="2">
+  <image source="props.png" width="37" height="34"/>
+ </tileset>
+ <layer id="1" name="ground" width="3" height="2">
+  <data encoding="csv">
+1,2,3,
+4,0,2147483649
+</data>
+ </layer>
+ <objectgroup id="2" name="spawns" opacity="0.5">
+  <object id="1" name="player1" type="spawn" x="24" y="8">
+   <point/>
+  </object>
+  <object id="2" class="tree" x="40" y="8" width="16" height="16">
+   <ellipse/>
+  </object>
+  <object id="3" x="0" y="0">
+   <properties>
+    <property name="solid" type="bool" value="true"/>
+   </properties>
+   <polygon points="0,0 16,0 8,12.5"/>
+  </object>
+ </objectgroup>
+ <group id="3" name="decor" opacity="0.5" offsetx="4" visible="0">
+  <layer id="4" name="props" width="3" height="2" opacity="0.5">
+   <data encoding="base64" compression="zlib">%s</data>
+  </layer>
+ </group>
+</map>`
+
+const testTsx = `<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.5" name="terrain" tilewidth="16" tileheight="16" tilecount="4" columns="2">
+ <image source="terrain.png" width="32" height="32"/>
+</tileset>`
+
+const testTmj = `{
+ "orientation": "orthogonal",
+ "width": 3,
+ "height": 2,
+ "tilewidth": 16,
+ "tileheight": 16,
+ "infinite": false,
+ "properties": [{"name": "level", "type": "int", "value": 2}],
+ "tilesets": [
+  {"firstgid": 1, "source": "tiles/terrain.tsx"},
+  {"firstgid": 5, "name": "props", "tilewidth": 16, "tileheight": 32, "tilecount": 2, "columns": 2, "margin": 1, "spacing": 2, "image": "props.png"}
+ ],
+ "layers": [
+  {"type": "tilelayer", "name": "ground", "width": 3, "height": 2, "opacity": 1, "visible": true, "data": [1, 2, 3, 4, 0, 2147483649]},
+  {"type": "objectgroup", "name": "spawns", "opacity": 0.5, "visible": true, "objects": [
+   {"id": 1, "name": "player1", "type": "spawn", "x": 24, "y": 8, "point": true},
+   {"id": 2, "class": "tree", "x": 40, "y": 8, "width": 16, "height": 16, "ellipse": true},
+   {"id": 3, "x": 0, "y": 0, "properties": [{"name": "solid", "type": "bool", "value": true}], "polygon": [{"x": 0, "y": 0}, {"x": 16, "y": 0}, {"x": 8, "y": 12.5}]}
+  ]},
+  {"type": "group", "name": "decor", "opacity": 0.5, "offsetx": 4, "visible": false, "layers": [
+   {"type": "tilelayer", "name": "props", "width": 3, "height": 2, "opacity": 0.5, "visible": true, "encoding": "base64", "compression": "zlib", "data": "%s"}
+  ]}
+ ]
+}`
+
+func encodeTestTiles(ids ...TileID) string {
+	var raw bytes.Buffer
+	for _, id := range ids {
+		_ = binary.Write(&raw, binary.LittleEndian, uint32(id))
+	}
+
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	_, _ = w.Write(raw.Bytes())
+	_ = w.Close()
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func loadTestTiledMap(t *testing.T, file, data string) *TileMap {
+	files := map[string]string{
+		"maps/" + file:           data,
+		"maps/tiles/terrain.tsx": testTsx,
+	}
+
+	var textures []string
+	tm, err := loadTiledMap("maps/"+file,
+		func(file string) ([]byte, error) {
+			data, ok := files[file]
+			if !ok {
+				return nil, os.ErrNotExist
+			}
+			return []byte(data), nil
+		},
+		func(file string) (*sdl.Texture, error) {
+			textures = append(textures, file)
+			return nil, nil
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"maps/tiles/terrain.png", "maps/props.png"}, textures)
+	return tm
+}
+
+func TestLoadTiledMap(t *testing.T) {
+	props := encodeTestTiles(0, 5, 0, 0, 6|TileFlipD, 0)
+	tests := map[string]string{
+		"test.tmx": testTmx,
+		"test.tmj": testTmj,
+	}
+
+	for file, data := range tests {
+		t.Run(file, func(t *testing.T) {
+			tm := loadTestTiledMap(t, file, fmt.Sprintf(data, props))
+			if tm == nil {
+				return
+			}
+
+			w, h := tm.Size()
+			assert.Equal(t, [2]int32{3, 2}, [2]int32{w, h})
+			assert.Len(t, tm.Tilesets(), 2)
+			assert.Len(t, tm.Layers(), 2)
+			assert.NotEmpty(t, tm.Properties)
+
+			ground := tm.Layer("ground")
+			if assert.NotNil(t, ground) {
+				assert.Equal(t, []TileID{1, 2, 3, 4, 0, 1 | TileFlipH}, ground.Tiles())
+				assert.Equal(t, 1.0, ground.Opacity)
+				assert.True(t, ground.Visible)
+			}
+
+			decor := tm.Layer("props")
+			if assert.NotNil(t, decor) {
+				assert.Equal(t, TileID(6|TileFlipD), decor.At(1, 1))
+				assert.Equal(t, 0.25, decor.Opacity)
+				assert.Equal(t, 4.0, decor.OffsetX)
+				assert.False(t, decor.Visible)
+			}
+
+			clip, err := tm.Tile(6 | TileFlipV)
+			assert.NoError(t, err)
+			assert.Equal(t, sdl.Rect{X: 19, Y: 1, W: 16, H: 32}, clip.Location)
+
+			clip, err = tm.Tile(4)
+			assert.NoError(t, err)
+			assert.Equal(t, sdl.Rect{X: 16, Y: 16, W: 16, H: 16}, clip.Location)
+
+			spawns := tm.ObjectLayer("spawns")
+			if !assert.NotNil(t, spawns) {
+				return
+			}
+
+			assert.Equal(t, 0.5, spawns.Opacity)
+			assert.Len(t, spawns.Objects, 3)
+
+			player := spawns.Object("player1")
+			if assert.NotNil(t, player) {
+				assert.Equal(t, ShapePoint, player.Shape)
+				assert.Equal(t, 24.0, player.X)
+				assert.True(t, player.Visible)
+			}
+
+			trees := spawns.ObjectsOfType("tree")
+			if assert.Len(t, trees, 1) {
+				assert.Equal(t, ShapeEllipse, trees[0].Shape)
+			}
+
+			poly := spawns.Objects[2]
+			assert.Equal(t, ShapePolygon, poly.Shape)
+			assert.Equal(t, []geom.Point{{X: 0, Y: 0}, {X: 16, Y: 0}, {X: 8, Y: 12.5}}, poly.Points)
+			assert.Equal(t, "true", poly.Properties["solid"])
+		})
+	}
+}
+
+func TestLoadTiledMap_errors(t *testing.T) {
+	read := func(data string) readFunc {
+		return func(string) ([]byte, error) { return []byte(data), nil }
+	}
+	texture := func(string) (*sdl.Texture, error) { return nil, nil }
+
+	tests := map[string]string{
+		"orientation": `<map orientation="oblique" width="1" height="1" tilewidth="16" tileheight="16"/>`,
+		"stagger":     `<map orientation="hexagonal" staggeraxis="z" width="1" height="1" tilewidth="16" tileheight="16"/>`,
+		"infinite":    `<map orientation="orthogonal" infinite="1" width="1" height="1" tilewidth="16" tileheight="16"/>`,
+		"size":        `<map orientation="orthogonal" width="2" height="1" tilewidth="16" tileheight="16"><layer name="a" width="2" height="1"><data encoding="csv">1</data></layer></map>`,
+		"compressed":  `<map orientation="orthogonal" width="1" height="1" tilewidth="16" tileheight="16"><layer name="a" width="1" height="1"><data encoding="base64" compression="zstd">AQAAAA==</data></layer></map>`,
+	}
+
+	for name, data := range tests {
+		_, err := loadTiledMap("test.tmx", read(data), texture)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestTileID_Orientation(t *testing.T) {
+	tests := map[TileID]struct {
+		deg  float64
+		flip sdl.RendererFlip
+	}{
+		0:                                 {0, sdl.FLIP_NONE},
+		TileFlipH:                         {0, sdl.FLIP_HORIZONTAL},
+		TileFlipV:                         {0, sdl.FLIP_VERTICAL},
+		TileFlipH | TileFlipV:             {0, sdl.FLIP_HORIZONTAL | sdl.FLIP_VERTICAL},
+		TileFlipD:                         {90, sdl.FLIP_VERTICAL},
+		TileFlipD | TileFlipH:             {90, sdl.FLIP_NONE},
+		TileFlipD | TileFlipV:             {270, sdl.FLIP_NONE},
+		TileFlipD | TileFlipH | TileFlipV: {90, sdl.FLIP_HORIZONTAL},
+	}
+
+	for id, want := range tests {
+		deg, flip := (id | 7).Orientation()
+		assert.Equal(t, want.deg, deg, "%x", uint32(id))
+		assert.Equal(t, want.flip, flip, "%x", uint32(id))
+		assert.Equal(t, uint32(7), (id | 7).GID())
+	}
+}
+
+func TestTileMap_VisibleRange(t *testing.T) {
+	tm := NewTileMap(10, 10, 16, 16, &Tileset{FirstGID: 1, TileW: 16, TileH: 16})
+	layer := tm.AddLayer("ground")
+
+	x0, y0, x1, y1 := tm.VisibleRange(nil, layer)
+	assert.Equal(t, [4]int32{0, 0, 10, 10}, [4]int32{x0, y0, x1, y1})
+
+	cam := sdlkit.NewCamera(20, 40, 32, 16)
+	x0, y0, x1, y1 = tm.VisibleRange(cam, layer)
+	assert.Equal(t, [4]int32{1, 2, 4, 4}, [4]int32{x0, y0, x1, y1})
+
+	// tiles which are taller than a cell overlap the row above
+	tm.AddTileset(&Tileset{FirstGID: 2, TileW: 16, TileH: 32})
+	x0, y0, x1, y1 = tm.VisibleRange(cam, layer)
+	assert.Equal(t, [4]int32{0, 2, 4, 5}, [4]int32{x0, y0, x1, y1})
+
+	tm.X = 100
+	x0, y0, x1, y1 = tm.VisibleRange(cam, layer)
+	assert.Equal(t, [4]int32{0, 2, 0, 5}, [4]int32{x0, y0, x1, y1})
+}
+
+func TestLoadTiledMap_grid(t *testing.T) {
+	data := `<map orientation="hexagonal" staggeraxis="x" staggerindex="even" hexsidelength="16" width="1" height="1" tilewidth="32" tileheight="28"/>`
+	tm, err := loadTiledMap("test.tmx",
+		func(string) ([]byte, error) { return []byte(data), nil },
+		func(string) (*sdl.Texture, error) { return nil, nil },
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, testGrids["flat"], tm.Grid())
+}
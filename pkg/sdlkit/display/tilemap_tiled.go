@@ -0,0 +1,565 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package display
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/go-pogo/errors"
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom"
+)
+
+// LoadTiledMap loads an orthogonal map created with Tiled, in either the
+// .tmx (XML) or .tmj (JSON) format, including its external tilesets. Tile
+// layers within groups are flattened, object layers are available via
+// TileMap.ObjectLayers.
+func LoadTiledMap(loader *sdlkit.AssetsLoader, file string) (*TileMap, error) {
+	return loadTiledMap(file, loader.Read, loader.Texture)
+}
+
+// LoadTiledMapBundle loads the Tiled map like LoadTiledMap, but loads the
+// images of its tilesets as part of the sdlkit.AssetBundle. This way textures
+// which are already loaded by the bundle are reused, and released when the
+// bundle is unloaded.
+func LoadTiledMapBundle(loader *sdlkit.AssetsLoader, bundle *sdlkit.AssetBundle, file string) (*TileMap, error) {
+	return loadTiledMap(file, loader.Read, bundle.TextureFile)
+}
+
+type (
+	readFunc    func(file string) ([]byte, error)
+	textureFunc func(file string) (*sdl.Texture, error)
+)
+
+func loadTiledMap(file string, read readFunc, texture textureFunc) (*TileMap, error) {
+	data, err := read(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var x tiledMap
+	if err = unmarshalTiled(file, data, &x); err != nil {
+		return nil, err
+	}
+	if x.Infinite {
+		return nil, errors.Newf("display: infinite map `%s` is not supported", file)
+	}
+
+	grid, err := x.grid()
+	if err != nil {
+		return nil, errors.Wrapf(err, "display: invalid map `%s`", file)
+	}
+
+	tm := NewGridTileMap(x.Width, x.Height, grid)
+	tm.Properties = x.Properties
+
+	dir := path.Dir(file)
+	for _, ts := range x.Tilesets {
+		tsDir := dir
+		if ts.Source != "" {
+			src := path.Join(dir, ts.Source)
+			if data, err = read(src); err != nil {
+				return nil, err
+			}
+
+			firstGID := ts.FirstGID
+			ts = tiledTileset{}
+			if err = unmarshalTiled(src, data, &ts); err != nil {
+				return nil, err
+			}
+
+			ts.FirstGID = firstGID
+			tsDir = path.Dir(src)
+		}
+		if ts.Image.Source == "" {
+			return nil, errors.Newf("display: tileset `%s` without a single image is not supported", ts.Name)
+		}
+
+		tx, err := texture(path.Join(tsDir, ts.Image.Source))
+		if err != nil {
+			return nil, err
+		}
+
+		set, err := newTiledTileset(ts, tx)
+		if err != nil {
+			return nil, err
+		}
+		tm.AddTileset(set)
+	}
+
+	prepareTiledLayers(x.Layers)
+	if err = tm.addTiledLayers(x.Layers, tiledGroup{opacity: 1, visible: true}); err != nil {
+		return nil, err
+	}
+	return tm, nil
+}
+
+// unmarshalTiled decodes data as XML or JSON, depending on the extension of
+// file.
+func unmarshalTiled(file string, data []byte, v interface{}) error {
+	switch path.Ext(file) {
+	case ".tmx", ".tsx":
+		return errors.Trace(xml.Unmarshal(data, v))
+	case ".tmj", ".tsj", ".json":
+		return errors.Trace(json.Unmarshal(data, v))
+	default:
+		return errors.Newf("display: unknown Tiled file format `%s`", file)
+	}
+}
+
+// tiledMap is the description of a Tiled map. Its fields are tagged for both
+// the XML and JSON formats.
+type tiledMap struct {
+	Orientation string `xml:"orientation,attr" json:"orientation"`
+	Width       int32  `xml:"width,attr" json:"width"`
+	Height      int32  `xml:"height,attr" json:"height"`
+	TileWidth   int32  `xml:"tilewidth,attr" json:"tilewidth"`
+	TileHeight  int32  `xml:"tileheight,attr" json:"tileheight"`
+	Infinite    bool   `xml:"infinite,attr" json:"infinite"`
+	// StaggerAxis, StaggerIndex and HexSideLength are only used by
+	// staggered and hexagonal maps.
+	StaggerAxis   string `xml:"staggeraxis,attr" json:"staggeraxis"`
+	StaggerIndex  string `xml:"staggerindex,attr" json:"staggerindex"`
+	HexSideLength int32  `xml:"hexsidelength,attr" json:"hexsidelength"`
+
+	Tilesets   []tiledTileset  `xml:"tileset" json:"tilesets"`
+	Properties tiledProperties `xml:"properties" json:"properties"`
+	// Layers contains all layer elements, in order, when decoding XML.
+	Layers []tiledLayer `xml:",any" json:"layers"`
+}
+
+// grid returns the Grid which matches the orientation of the map.
+func (x tiledMap) grid() (Grid, error) {
+	grid := Grid{
+		TileW:         x.TileWidth,
+		TileH:         x.TileHeight,
+		HexSideLength: x.HexSideLength,
+	}
+
+	switch x.Orientation {
+	case "orthogonal":
+		grid.Orientation = GridOrthogonal
+	case "isometric":
+		grid.Orientation = GridIsometric
+	case "staggered":
+		grid.Orientation = GridStaggered
+	case "hexagonal":
+		grid.Orientation = GridHexagonal
+	default:
+		return grid, errors.Newf("unsupported orientation `%s`", x.Orientation)
+	}
+
+	switch x.StaggerAxis {
+	case "", "y":
+		grid.StaggerAxis = StaggerY
+	case "x":
+		grid.StaggerAxis = StaggerX
+	default:
+		return grid, errors.Newf("unknown stagger axis `%s`", x.StaggerAxis)
+	}
+
+	switch x.StaggerIndex {
+	case "", "odd":
+		grid.StaggerIndex = StaggerOdd
+	case "even":
+		grid.StaggerIndex = StaggerEven
+	default:
+		return grid, errors.Newf("unknown stagger index `%s`", x.StaggerIndex)
+	}
+	return grid, nil
+}
+
+type tiledTileset struct {
+	FirstGID   uint32     `xml:"firstgid,attr" json:"firstgid"`
+	Source     string     `xml:"source,attr" json:"source"`
+	Name       string     `xml:"name,attr" json:"name"`
+	TileWidth  int32      `xml:"tilewidth,attr" json:"tilewidth"`
+	TileHeight int32      `xml:"tileheight,attr" json:"tileheight"`
+	TileCount  int32      `xml:"tilecount,attr" json:"tilecount"`
+	Columns    int32      `xml:"columns,attr" json:"columns"`
+	Margin     int32      `xml:"margin,attr" json:"margin"`
+	Spacing    int32      `xml:"spacing,attr" json:"spacing"`
+	Image      tiledImage `xml:"image" json:"image"`
+}
+
+type tiledImage struct {
+	Source string `xml:"source,attr"`
+}
+
+// UnmarshalJSON decodes the image path, which is a plain string in JSON.
+func (img *tiledImage) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &img.Source)
+}
+
+// newTiledTileset creates a Tileset with a TextureAtlas which contains the
+// locations of all tiles, in order.
+func newTiledTileset(ts tiledTileset, tx *sdl.Texture) (*Tileset, error) {
+	if ts.Columns < 1 || ts.TileWidth < 1 || ts.TileHeight < 1 {
+		return nil, errors.Newf("display: invalid tile grid of tileset `%s`", ts.Name)
+	}
+
+	atlas := sdlkit.NewTextureAtlas(tx, nil)
+	for i := int32(0); i < ts.TileCount; i++ {
+		atlas.Add("", sdl.Rect{
+			X: ts.Margin + (i%ts.Columns)*(ts.TileWidth+ts.Spacing),
+			Y: ts.Margin + (i/ts.Columns)*(ts.TileHeight+ts.Spacing),
+			W: ts.TileWidth,
+			H: ts.TileHeight,
+		})
+	}
+
+	return &Tileset{
+		Name:     ts.Name,
+		FirstGID: ts.FirstGID,
+		Atlas:    atlas,
+		TileW:    ts.TileWidth,
+		TileH:    ts.TileHeight,
+	}, nil
+}
+
+type tiledLayer struct {
+	XMLName xml.Name `json:"-"`
+	// Type is either "tilelayer", "objectgroup", "imagelayer" or "group".
+	Type        string          `xml:"-" json:"type"`
+	Name        string          `xml:"name,attr" json:"name"`
+	Width       int32           `xml:"width,attr" json:"width"`
+	Height      int32           `xml:"height,attr" json:"height"`
+	Opacity     *float64        `xml:"opacity,attr" json:"opacity"`
+	Visible     *bool           `xml:"visible,attr" json:"visible"`
+	OffsetX     float64         `xml:"offsetx,attr" json:"offsetx"`
+	OffsetY     float64         `xml:"offsety,attr" json:"offsety"`
+	Encoding    string          `xml:"-" json:"encoding"`
+	Compression string          `xml:"-" json:"compression"`
+	Data        tiledData       `xml:"data" json:"data"`
+	Objects     []tiledObject   `xml:"object" json:"objects"`
+	Properties  tiledProperties `xml:"properties" json:"properties"`
+	// Layers contains the child layers of a group.
+	Layers []tiledLayer `xml:",any" json:"layers"`
+}
+
+// prepareTiledLayers sets the fields which are stored differently in the XML
+// and JSON formats.
+func prepareTiledLayers(layers []tiledLayer) {
+	for i := range layers {
+		l := &layers[i]
+		if l.Type == "" {
+			switch l.XMLName.Local {
+			case "layer":
+				l.Type = "tilelayer"
+			default:
+				l.Type = l.XMLName.Local
+			}
+		}
+		if l.Data.Encoding == "" {
+			l.Data.Encoding, l.Data.Compression = l.Encoding, l.Compression
+		}
+
+		prepareTiledLayers(l.Layers)
+	}
+}
+
+// tiledGroup contains the combined properties of the parent groups of a
+// layer.
+type tiledGroup struct {
+	opacity          float64
+	visible          bool
+	offsetX, offsetY float64
+}
+
+func (g tiledGroup) child(l tiledLayer) tiledGroup {
+	if l.Opacity != nil {
+		g.opacity *= *l.Opacity
+	}
+	if l.Visible != nil {
+		g.visible = g.visible && *l.Visible
+	}
+
+	g.offsetX += l.OffsetX
+	g.offsetY += l.OffsetY
+	return g
+}
+
+func (tm *TileMap) addTiledLayers(layers []tiledLayer, parent tiledGroup) error {
+	for _, l := range layers {
+		g := parent.child(l)
+		switch l.Type {
+		case "group":
+			if err := tm.addTiledLayers(l.Layers, g); err != nil {
+				return err
+			}
+
+		case "tilelayer":
+			tiles, err := l.Data.decode()
+			if err != nil {
+				return errors.Wrapf(err, "display: invalid data of layer `%s`", l.Name)
+			}
+			if int32(len(tiles)) != l.Width*l.Height {
+				return errors.Newf("display: layer `%s` has %d tiles, expected %d", l.Name, len(tiles), l.Width*l.Height)
+			}
+
+			tm.layers = append(tm.layers, &TileLayer{
+				Name:       l.Name,
+				Opacity:    g.opacity,
+				Visible:    g.visible,
+				OffsetX:    g.offsetX,
+				OffsetY:    g.offsetY,
+				Properties: l.Properties,
+				width:      l.Width,
+				height:     l.Height,
+				tiles:      tiles,
+			})
+
+		case "objectgroup":
+			ol := &ObjectLayer{
+				Name:       l.Name,
+				Opacity:    g.opacity,
+				Visible:    g.visible,
+				Objects:    make([]*MapObject, 0, len(l.Objects)),
+				Properties: l.Properties,
+			}
+			for _, o := range l.Objects {
+				obj := o.mapObject()
+				obj.X += g.offsetX
+				obj.Y += g.offsetY
+				ol.Objects = append(ol.Objects, obj)
+			}
+			tm.objects = append(tm.objects, ol)
+		}
+	}
+	return nil
+}
+
+// tiledData contains the tiles of a layer, either as encoded text, a list of
+// tile elements (XML) or a list of ids (JSON).
+type tiledData struct {
+	Encoding    string `xml:"encoding,attr"`
+	Compression string `xml:"compression,attr"`
+	Text        string `xml:",chardata"`
+	Tiles       []struct {
+		GID uint32 `xml:"gid,attr"`
+	} `xml:"tile"`
+
+	ids []uint32
+}
+
+// UnmarshalJSON decodes either a list of ids or a base64 encoded string.
+func (d *tiledData) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		return json.Unmarshal(data, &d.ids)
+	}
+	return json.Unmarshal(data, &d.Text)
+}
+
+func (d tiledData) decode() ([]TileID, error) {
+	if d.ids != nil {
+		res := make([]TileID, len(d.ids))
+		for i, id := range d.ids {
+			res[i] = TileID(id)
+		}
+		return res, nil
+	}
+
+	switch d.Encoding {
+	case "":
+		res := make([]TileID, len(d.Tiles))
+		for i, t := range d.Tiles {
+			res[i] = TileID(t.GID)
+		}
+		return res, nil
+
+	case "csv":
+		fields := strings.Split(strings.TrimSpace(d.Text), ",")
+		res := make([]TileID, 0, len(fields))
+		for _, f := range fields {
+			id, err := strconv.ParseUint(strings.TrimSpace(f), 10, 32)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			res = append(res, TileID(id))
+		}
+		return res, nil
+
+	case "base64":
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(d.Text))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+
+		var r io.Reader
+		switch d.Compression {
+		case "":
+		case "zlib":
+			r, err = zlib.NewReader(bytes.NewReader(raw))
+		case "gzip":
+			r, err = gzip.NewReader(bytes.NewReader(raw))
+		default:
+			return nil, errors.Newf("display: unsupported compression `%s`", d.Compression)
+		}
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if r != nil {
+			if raw, err = ioutil.ReadAll(r); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if len(raw)%4 != 0 {
+			return nil, errors.Newf("display: invalid data length %d", len(raw))
+		}
+
+		res := make([]TileID, len(raw)/4)
+		for i := range res {
+			res[i] = TileID(binary.LittleEndian.Uint32(raw[i*4:]))
+		}
+		return res, nil
+
+	default:
+		return nil, errors.Newf("display: unsupported encoding `%s`", d.Encoding)
+	}
+}
+
+type tiledObject struct {
+	ID       int             `xml:"id,attr" json:"id"`
+	Name     string          `xml:"name,attr" json:"name"`
+	Type     string          `xml:"type,attr" json:"type"`
+	Class    string          `xml:"class,attr" json:"class"`
+	X        float64         `xml:"x,attr" json:"x"`
+	Y        float64         `xml:"y,attr" json:"y"`
+	Width    float64         `xml:"width,attr" json:"width"`
+	Height   float64         `xml:"height,attr" json:"height"`
+	Rotation float64         `xml:"rotation,attr" json:"rotation"`
+	GID      uint32          `xml:"gid,attr" json:"gid"`
+	Visible  *bool           `xml:"visible,attr" json:"visible"`
+	Point    tiledFlag       `xml:"point" json:"point"`
+	Ellipse  tiledFlag       `xml:"ellipse" json:"ellipse"`
+	Polygon  tiledPoints     `xml:"polygon" json:"polygon"`
+	Polyline tiledPoints     `xml:"polyline" json:"polyline"`
+	Props    tiledProperties `xml:"properties" json:"properties"`
+}
+
+func (o tiledObject) mapObject() *MapObject {
+	obj := &MapObject{
+		ID:         o.ID,
+		Name:       o.Name,
+		Type:       o.Type,
+		X:          o.X,
+		Y:          o.Y,
+		W:          o.Width,
+		H:          o.Height,
+		Rotation:   o.Rotation,
+		Visible:    o.Visible == nil || *o.Visible,
+		GID:        TileID(o.GID),
+		Properties: o.Props,
+	}
+	if obj.Type == "" {
+		obj.Type = o.Class
+	}
+
+	switch {
+	case bool(o.Point):
+		obj.Shape = ShapePoint
+	case bool(o.Ellipse):
+		obj.Shape = ShapeEllipse
+	case o.Polygon != nil:
+		obj.Shape = ShapePolygon
+		obj.Points = o.Polygon
+	case o.Polyline != nil:
+		obj.Shape = ShapePolyline
+		obj.Points = o.Polyline
+	}
+	return obj
+}
+
+// tiledFlag is true when its (empty) XML element is present.
+type tiledFlag bool
+
+func (f *tiledFlag) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*f = true
+	return d.Skip()
+}
+
+// tiledPoints decodes the points of a polygon or polyline. In XML these are
+// stored as a "x,y x,y" attribute.
+type tiledPoints []geom.Point
+
+func (p *tiledPoints) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var x struct {
+		Points string `xml:"points,attr"`
+	}
+	if err := d.DecodeElement(&x, &start); err != nil {
+		return err
+	}
+
+	fields := strings.Fields(x.Points)
+	*p = make(tiledPoints, 0, len(fields))
+	for _, f := range fields {
+		var pt geom.Point
+		if _, err := fmt.Sscanf(f, "%g,%g", &pt.X, &pt.Y); err != nil {
+			return errors.Newf("display: invalid point `%s`", f)
+		}
+		*p = append(*p, pt)
+	}
+	return nil
+}
+
+// tiledProperties contains the custom properties of a map, layer or object.
+// All values are stored as strings.
+type tiledProperties map[string]string
+
+func (p *tiledProperties) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var x struct {
+		Props []struct {
+			Name  string `xml:"name,attr"`
+			Value string `xml:"value,attr"`
+			Text  string `xml:",chardata"` // multiline strings
+		} `xml:"property"`
+	}
+	if err := d.DecodeElement(&x, &start); err != nil {
+		return err
+	}
+
+	*p = make(tiledProperties, len(x.Props))
+	for _, prop := range x.Props {
+		if prop.Value == "" {
+			prop.Value = prop.Text
+		}
+		(*p)[prop.Name] = prop.Value
+	}
+	return nil
+}
+
+func (p *tiledProperties) UnmarshalJSON(data []byte) error {
+	var x []struct {
+		Name  string      `json:"name"`
+		Value interface{} `json:"value"`
+	}
+	if err := json.Unmarshal(data, &x); err != nil {
+		return err
+	}
+
+	*p = make(tiledProperties, len(x))
+	for _, prop := range x {
+		if prop.Value != nil {
+			(*p)[prop.Name] = fmt.Sprint(prop.Value)
+		} else {
+			(*p)[prop.Name] = ""
+		}
+	}
+	return nil
+}
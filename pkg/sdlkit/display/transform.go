@@ -5,12 +5,34 @@
 package display
 
 import (
+	"github.com/go-pogo/errors"
 	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/colors"
 )
 
 type ColorTransform struct {
+	// Tint is multiplied with the colors of the texture. Its alpha value is
+	// ignored, use TextureTransform.Alpha instead.
+	Tint sdl.Color
+	// Flash is added to the colors of the texture using a second, additive,
+	// pass. Its alpha value determines the strength of the flash, an alpha of
+	// 0 disables it.
+	Flash sdl.Color
+}
+
+func (ct *ColorTransform) Reset() {
+	ct.Tint = colors.White
+	ct.Flash = sdl.Color{}
 }
 
+func (ct *ColorTransform) IsTinted() bool {
+	return ct.Tint.R != 0xff || ct.Tint.G != 0xff || ct.Tint.B != 0xff
+}
+
+func (ct *ColorTransform) IsFlashing() bool { return ct.Flash.A != 0 }
+
 type TextureTransform struct {
 	Rotation,
 	ScaleX, ScaleY,
@@ -21,13 +43,71 @@ type TextureTransform struct {
 }
 
 func (tt *TextureTransform) Reset() {
-	tt.Alpha = 1
+	tt.Alpha = 0xff
 	tt.BlendMode = sdl.BLENDMODE_BLEND
 	tt.Flip = sdl.FLIP_NONE
 }
 
 func ResetTextureDisplay(td *TextureTransform) {
-	td.Alpha = 1
-	td.BlendMode = sdl.BLENDMODE_BLEND
-	td.Flip = sdl.FLIP_NONE
+	td.Reset()
+}
+
+// drawTransformedClip draws the TextureClip with the ColorTransform and the
+// Alpha and BlendMode of the TextureTransform applied. Because textures are
+// shared, eg. through a TextureAtlas, the texture's color mod, alpha mod and
+// blend mode are restored afterwards.
+func drawTransformedClip(canvas *sdlkit.Canvas, clip sdlkit.TextureClip, dest sdl.Rect, deg float64, origin sdl.Point, ct *ColorTransform, tt *TextureTransform) {
+	tx := clip.Texture
+	if tx == nil {
+		return
+	}
+
+	r, g, b, err := sdlkit.TextureColorMod(tx)
+	if err != nil {
+		canvas.CatchErr(err)
+		return
+	}
+	alpha, err := tx.GetAlphaMod()
+	if err != nil {
+		canvas.CatchErr(err)
+		return
+	}
+	blend, err := tx.GetBlendMode()
+	if err != nil {
+		canvas.CatchErr(err)
+		return
+	}
+
+	tinted := ct.IsTinted()
+	if tinted {
+		canvas.CatchErr(tx.SetColorMod(ct.Tint.R, ct.Tint.G, ct.Tint.B))
+	}
+	if tt.Alpha != alpha {
+		canvas.CatchErr(tx.SetAlphaMod(tt.Alpha))
+	}
+	if tt.BlendMode != blend {
+		canvas.CatchErr(tx.SetBlendMode(tt.BlendMode))
+	}
+
+	canvas.DrawTextureClipEx(clip, dest, deg, origin, tt.Flip)
+
+	if ct.IsFlashing() {
+		canvas.CatchErr(
+			tx.SetColorMod(ct.Flash.R, ct.Flash.G, ct.Flash.B),
+			tx.SetAlphaMod(uint8(uint16(ct.Flash.A)*uint16(tt.Alpha)/0xff)),
+			tx.SetBlendMode(sdl.BLENDMODE_ADD),
+		)
+		canvas.DrawTextureClipEx(clip, dest, deg, origin, tt.Flip)
+		tinted = true
+	}
+
+	var resetErr error
+	if tinted {
+		errors.Append(&resetErr, tx.SetColorMod(r, g, b))
+	}
+	errors.Append(&resetErr,
+		tx.SetAlphaMod(alpha),
+		tx.SetBlendMode(blend),
+	)
+	canvas.CatchErr(resetErr)
 }
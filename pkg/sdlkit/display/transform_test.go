@@ -0,0 +1,29 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package display
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func TestSprite_Reset(t *testing.T) {
+	var s Sprite
+	s.Tint = sdl.Color{R: 0xff, G: 0x80, B: 0x80, A: 0xff}
+	s.Flash = sdl.Color{R: 0xff, G: 0xff, B: 0xff, A: 0x40}
+	s.Alpha = 0x20
+	s.BlendMode = sdl.BLENDMODE_ADD
+
+	assert.True(t, s.IsTinted())
+	assert.True(t, s.IsFlashing())
+
+	s.Reset()
+	assert.False(t, s.IsTinted())
+	assert.False(t, s.IsFlashing())
+	assert.Equal(t, uint8(0xff), s.Alpha)
+	assert.Equal(t, sdl.BLENDMODE_BLEND, s.BlendMode)
+}
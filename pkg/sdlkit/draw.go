@@ -47,3 +47,16 @@ func (l *Layer) Prepend(d ...Renderable) {
 }
 
 func (l Layer) Render(ren *sdl.Renderer) error { return Render(ren, l...) }
+
+// Draw draws the Renderables which implement Drawable onto the Canvas, taking
+// its Camera into account. All others are rendered using the Canvas'
+// sdl.Renderer.
+func (l Layer) Draw(canvas *Canvas) {
+	for _, r := range l {
+		if d, ok := r.(Drawable); ok {
+			d.Draw(canvas)
+		} else {
+			canvas.Render(r)
+		}
+	}
+}
@@ -11,6 +11,29 @@ import (
 
 type FontsMap map[string]*sdlttf.Font
 
+// Destroy closes the sdlttf.Font with name.
+func (f FontsMap) Destroy(name string) {
+	font := f[name]
+	if font == nil {
+		return
+	}
+
+	f[name] = nil
+	font.Close()
+}
+
+// DestroyAll closes all sdlttf.Fonts within the FontsMap.
+func (f FontsMap) DestroyAll() {
+	for n, font := range f {
+		if font == nil {
+			continue
+		}
+
+		font.Close()
+		f[n] = nil
+	}
+}
+
 func OpenFontFromMem(data []byte, size int) (*sdlttf.Font, error) {
 	src, err := sdl.RWFromMem(data)
 	if err != nil {
@@ -28,3 +51,28 @@ func OpenFontIndexFromMem(data []byte, size, index int) (*sdlttf.Font, error) {
 
 	return sdlttf.OpenFontIndexRW(src, 1, size, index)
 }
+
+// Glyph is a single character of a GlyphFont which can be drawn using its
+// TextureClip.
+type Glyph struct {
+	TextureClip
+
+	// OffsetX and OffsetY are the distances from the pen position, at the top
+	// of the line, to the top left corner of the TextureClip.
+	OffsetX, OffsetY int32
+
+	// Advance is the horizontal distance the pen position moves after drawing
+	// the Glyph.
+	Advance int32
+}
+
+// GlyphFont is a font which provides its characters as Glyphs. It is used to
+// measure, layout and draw text.
+type GlyphFont interface {
+	// Glyph returns the Glyph of rune r.
+	Glyph(r rune) (Glyph, error)
+	// Kerning returns the horizontal adjustment between runes prev and r.
+	Kerning(prev, r rune) int32
+	// LineHeight returns the distance between the top of two lines of text.
+	LineHeight() int32
+}
@@ -0,0 +1,263 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sdlkit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"strconv"
+	"strings"
+
+	"github.com/go-pogo/errors"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// BitmapFont is a GlyphFont which uses pre-rendered glyphs from one or more
+// TextureAtlas pages, eg. an AngelCode BMFont.
+type BitmapFont struct {
+	pages      []*TextureAtlas
+	glyphs     map[rune]Glyph
+	kerning    map[[2]rune]int32
+	lineHeight int32
+	base       int32
+}
+
+func NewBitmapFont(lineHeight, base int32, pages ...*TextureAtlas) *BitmapFont {
+	return &BitmapFont{
+		pages:      pages,
+		glyphs:     make(map[rune]Glyph, 96),
+		kerning:    make(map[[2]rune]int32),
+		lineHeight: lineHeight,
+		base:       base,
+	}
+}
+
+// Pages returns the TextureAtlas pages which contain the glyphs.
+func (f *BitmapFont) Pages() []*TextureAtlas { return f.pages }
+
+func (f *BitmapFont) LineHeight() int32 { return f.lineHeight }
+
+// Base returns the distance from the top of a line to the baseline of its
+// glyphs.
+func (f *BitmapFont) Base() int32 { return f.base }
+
+// AddGlyph adds the Glyph of rune r to the BitmapFont.
+func (f *BitmapFont) AddGlyph(r rune, g Glyph) { f.glyphs[r] = g }
+
+// SetKerning sets the horizontal adjustment between runes first and second.
+func (f *BitmapFont) SetKerning(first, second rune, amount int32) {
+	f.kerning[[2]rune{first, second}] = amount
+}
+
+func (f *BitmapFont) HasGlyph(r rune) bool {
+	_, ok := f.glyphs[r]
+	return ok
+}
+
+func (f *BitmapFont) Glyph(r rune) (Glyph, error) {
+	g, ok := f.glyphs[r]
+	if !ok {
+		return Glyph{}, errors.Newf("sdlkit: unknown glyph `%c` (%d) in BitmapFont", r, r)
+	}
+	return g, nil
+}
+
+func (f *BitmapFont) Kerning(prev, r rune) int32 {
+	return f.kerning[[2]rune{prev, r}]
+}
+
+// Destroy destroys all pages of the BitmapFont.
+func (f *BitmapFont) Destroy() error {
+	unwatchAsset(f)
+
+	var err error
+	for _, page := range f.pages {
+		errors.Append(&err, page.Destroy())
+	}
+	return err
+}
+
+// bmFont is the description of an AngelCode BMFont, as read from a text or
+// XML .fnt file.
+type bmFont struct {
+	Common struct {
+		LineHeight int32 `xml:"lineHeight,attr"`
+		Base       int32 `xml:"base,attr"`
+	} `xml:"common"`
+
+	Pages    []bmFontPage    `xml:"pages>page"`
+	Chars    []bmFontChar    `xml:"chars>char"`
+	Kernings []bmFontKerning `xml:"kernings>kerning"`
+}
+
+type bmFontPage struct {
+	ID   int    `xml:"id,attr"`
+	File string `xml:"file,attr"`
+}
+
+type bmFontChar struct {
+	ID       rune  `xml:"id,attr"`
+	X        int32 `xml:"x,attr"`
+	Y        int32 `xml:"y,attr"`
+	W        int32 `xml:"width,attr"`
+	H        int32 `xml:"height,attr"`
+	XOffset  int32 `xml:"xoffset,attr"`
+	YOffset  int32 `xml:"yoffset,attr"`
+	XAdvance int32 `xml:"xadvance,attr"`
+	Page     int   `xml:"page,attr"`
+}
+
+type bmFontKerning struct {
+	First  rune  `xml:"first,attr"`
+	Second rune  `xml:"second,attr"`
+	Amount int32 `xml:"amount,attr"`
+}
+
+// parseBMFont parses the data of a .fnt file in either the text or XML
+// format.
+func parseBMFont(data []byte) (bmFont, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+		var x bmFont
+		err := xml.Unmarshal(data, &x)
+		return x, errors.Trace(err)
+	}
+
+	return parseBMFontText(data)
+}
+
+// parseBMFontText parses the text format of a .fnt file. Each line starts
+// with a tag, followed by key=value pairs.
+func parseBMFontText(data []byte) (bmFont, error) {
+	var res bmFont
+	var err error
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for ln := 1; scanner.Scan(); ln++ {
+		tag, attrs := parseBMFontLine(scanner.Text())
+		num := func(key string) int32 {
+			v, ok := attrs[key]
+			if !ok {
+				return 0
+			}
+
+			i, e := strconv.ParseInt(v, 10, 32)
+			if e != nil {
+				errors.Append(&err, errors.Newf("sdlkit: invalid value `%s` for %s on line %d", v, key, ln))
+			}
+			return int32(i)
+		}
+
+		switch tag {
+		case "common":
+			res.Common.LineHeight = num("lineHeight")
+			res.Common.Base = num("base")
+
+		case "page":
+			res.Pages = append(res.Pages, bmFontPage{
+				ID:   int(num("id")),
+				File: attrs["file"],
+			})
+
+		case "char":
+			res.Chars = append(res.Chars, bmFontChar{
+				ID:       num("id"),
+				X:        num("x"),
+				Y:        num("y"),
+				W:        num("width"),
+				H:        num("height"),
+				XOffset:  num("xoffset"),
+				YOffset:  num("yoffset"),
+				XAdvance: num("xadvance"),
+				Page:     int(num("page")),
+			})
+
+		case "kerning":
+			res.Kernings = append(res.Kernings, bmFontKerning{
+				First:  num("first"),
+				Second: num("second"),
+				Amount: num("amount"),
+			})
+		}
+	}
+
+	errors.Append(&err, scanner.Err())
+	return res, err
+}
+
+// parseBMFontLine splits a line into its tag and key=value pairs. Values may
+// be quoted, in which case they can contain spaces.
+func parseBMFontLine(line string) (string, map[string]string) {
+	line = strings.TrimSpace(line)
+	i := strings.IndexByte(line, ' ')
+	if i < 0 {
+		return line, nil
+	}
+
+	tag := line[:i]
+	attrs := make(map[string]string, 10)
+	for line = strings.TrimSpace(line[i:]); line != ""; line = strings.TrimSpace(line) {
+		eq := strings.IndexByte(line, '=')
+		if eq < 0 {
+			break
+		}
+
+		key := line[:eq]
+		line = line[eq+1:]
+
+		var val string
+		if strings.HasPrefix(line, `"`) {
+			line = line[1:]
+			end := strings.IndexByte(line, '"')
+			if end < 0 {
+				val, line = line, ""
+			} else {
+				val, line = line[:end], line[end+1:]
+			}
+		} else {
+			end := strings.IndexByte(line, ' ')
+			if end < 0 {
+				end = len(line)
+			}
+			val = line[:end]
+			line = line[end:]
+		}
+
+		attrs[key] = val
+	}
+	return tag, attrs
+}
+
+// newBitmapFont creates a BitmapFont from the description of a BMFont. Each
+// char is added to the TextureAtlas page it is located on, with its rune as
+// name.
+func newBitmapFont(desc bmFont, pages []*TextureAtlas) (*BitmapFont, error) {
+	font := NewBitmapFont(desc.Common.LineHeight, desc.Common.Base, pages...)
+	for _, char := range desc.Chars {
+		if char.Page < 0 || char.Page >= len(pages) {
+			return nil, errors.Newf("sdlkit: unknown page %d for char %d in BitmapFont", char.Page, char.ID)
+		}
+
+		page := pages[char.Page]
+		loc := sdl.Rect{X: char.X, Y: char.Y, W: char.W, H: char.H}
+		if loc.W > 0 && loc.H > 0 {
+			page.Add(string(char.ID), loc)
+		}
+
+		font.AddGlyph(char.ID, Glyph{
+			TextureClip: TextureClip{
+				Texture:  page.texture,
+				Location: loc,
+			},
+			OffsetX: char.XOffset,
+			OffsetY: char.YOffset,
+			Advance: char.XAdvance,
+		})
+	}
+	for _, k := range desc.Kernings {
+		font.SetKerning(k.First, k.Second, k.Amount)
+	}
+	return font, nil
+}
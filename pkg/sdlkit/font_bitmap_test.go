@@ -0,0 +1,105 @@
+package sdlkit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+const bmFontText = `info face="Pixel Font" size=16 bold=0 italic=0 charset="" unicode=1 padding=0,0,0,0 spacing=1,1
+common lineHeight=18 base=14 scaleW=128 scaleH=128 pages=2 packed=0
+page id=0 file="pixel_0.png"
+page id=1 file="pixel 1.png"
+chars count=3
+char id=32   x=0     y=0     width=0     height=0     xoffset=0     yoffset=14    xadvance=4     page=0  chnl=15
+char id=65   x=1     y=2     width=7     height=10    xoffset=-1    yoffset=4     xadvance=8     page=0  chnl=15
+char id=86   x=10    y=2     width=8     height=10    xoffset=0     yoffset=4     xadvance=8     page=1  chnl=15
+kernings count=1
+kerning first=65  second=86  amount=-1
+`
+
+const bmFontXml = `<?xml version="1.0"?>
+<font>
+  <info face="Pixel Font" size="16" bold="0" italic="0" charset="" unicode="1" padding="0,0,0,0" spacing="1,1"/>
+  <common lineHeight="18" base="14" scaleW="128" scaleH="128" pages="2" packed="0"/>
+  <pages>
+    <page id="0" file="pixel_0.png" />
+    <page id="1" file="pixel 1.png" />
+  </pages>
+  <chars count="3">
+    <char id="32" x="0" y="0" width="0" height="0" xoffset="0" yoffset="14" xadvance="4" page="0" chnl="15" />
+    <char id="65" x="1" y="2" width="7" height="10" xoffset="-1" yoffset="4" xadvance="8" page="0" chnl="15" />
+    <char id="86" x="10" y="2" width="8" height="10" xoffset="0" yoffset="4" xadvance="8" page="1" chnl="15" />
+  </chars>
+  <kernings count="1">
+    <kerning first="65" second="86" amount="-1" />
+  </kernings>
+</font>
+`
+
+func TestParseBMFont(t *testing.T) {
+	var want bmFont
+	want.Common.LineHeight = 18
+	want.Common.Base = 14
+	want.Pages = []bmFontPage{
+		{ID: 0, File: "pixel_0.png"},
+		{ID: 1, File: "pixel 1.png"},
+	}
+	want.Chars = []bmFontChar{
+		{ID: ' ', YOffset: 14, XAdvance: 4},
+		{ID: 'A', X: 1, Y: 2, W: 7, H: 10, XOffset: -1, YOffset: 4, XAdvance: 8},
+		{ID: 'V', X: 10, Y: 2, W: 8, H: 10, YOffset: 4, XAdvance: 8, Page: 1},
+	}
+	want.Kernings = []bmFontKerning{{First: 'A', Second: 'V', Amount: -1}}
+
+	tests := map[string]string{
+		"text": bmFontText,
+		"xml":  bmFontXml,
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			have, err := parseBMFont([]byte(data))
+			assert.NoError(t, err)
+			assert.Equal(t, want, have)
+		})
+	}
+}
+
+func TestParseBMFont_InvalidValue(t *testing.T) {
+	_, err := parseBMFont([]byte("common lineHeight=abc base=14\n"))
+	assert.Error(t, err)
+}
+
+func TestNewBitmapFont(t *testing.T) {
+	desc, err := parseBMFont([]byte(bmFontText))
+	assert.NoError(t, err)
+
+	pages := []*TextureAtlas{NewTextureAtlas(nil, nil), NewTextureAtlas(nil, nil)}
+	font, err := newBitmapFont(desc, pages)
+	assert.NoError(t, err)
+
+	assert.Equal(t, int32(18), font.LineHeight())
+	assert.Equal(t, int32(14), font.Base())
+	assert.Equal(t, int32(-1), font.Kerning('A', 'V'))
+	assert.Equal(t, int32(0), font.Kerning('V', 'A'))
+
+	g, err := font.Glyph('A')
+	assert.NoError(t, err)
+	assert.Equal(t, sdl.Rect{X: 1, Y: 2, W: 7, H: 10}, g.Location)
+	assert.Equal(t, int32(-1), g.OffsetX)
+	assert.Equal(t, int32(4), g.OffsetY)
+	assert.Equal(t, int32(8), g.Advance)
+
+	// glyphs without size are not added to their page
+	assert.Equal(t, 1, pages[0].Len())
+	assert.True(t, pages[0].HasName("A"))
+	assert.True(t, pages[1].HasName("V"))
+
+	_, err = font.Glyph('B')
+	assert.Error(t, err)
+
+	desc.Chars[0].Page = 2
+	_, err = newBitmapFont(desc, pages)
+	assert.Error(t, err)
+}
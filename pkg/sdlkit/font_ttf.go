@@ -0,0 +1,184 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sdlkit
+
+import (
+	"unicode"
+
+	"github.com/go-pogo/errors"
+	"github.com/veandco/go-sdl2/sdl"
+	sdlttf "github.com/veandco/go-sdl2/ttf"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/colors"
+)
+
+// GlyphPageSize is the width and height of the textures TrueTypeFont uses to
+// cache its rendered glyphs.
+var GlyphPageSize int32 = 512
+
+// TrueTypeFont is a GlyphFont which renders the glyphs of a sdlttf.Font on
+// demand and caches them in one or more TextureAtlas pages. Glyphs are
+// rendered in white so they can be colored using a texture's color mod.
+type TrueTypeFont struct {
+	font    *sdlttf.Font
+	ren     *sdl.Renderer
+	pages   []*TextureAtlas
+	glyphs  map[rune]Glyph
+	kerning map[[2]rune]int32
+
+	// shelf packing position within the last page
+	penX, penY, rowH int32
+}
+
+func NewTrueTypeFont(ren *sdl.Renderer, font *sdlttf.Font) *TrueTypeFont {
+	return &TrueTypeFont{
+		font:    font,
+		ren:     ren,
+		glyphs:  make(map[rune]Glyph, 96),
+		kerning: make(map[[2]rune]int32),
+	}
+}
+
+// Font returns the underlying sdlttf.Font.
+func (f *TrueTypeFont) Font() *sdlttf.Font { return f.font }
+
+// Pages returns the TextureAtlas pages which contain the cached glyphs.
+func (f *TrueTypeFont) Pages() []*TextureAtlas { return f.pages }
+
+func (f *TrueTypeFont) LineHeight() int32 { return int32(f.font.LineSkip()) }
+
+// Glyph returns the Glyph of rune r. When r is not yet cached, it is rendered
+// and added to a glyph page.
+func (f *TrueTypeFont) Glyph(r rune) (Glyph, error) {
+	if g, ok := f.glyphs[r]; ok {
+		return g, nil
+	}
+
+	metrics, err := f.font.GlyphMetrics(r)
+	if err != nil {
+		return Glyph{}, errors.Trace(err)
+	}
+
+	g := Glyph{Advance: int32(metrics.Advance)}
+	if unicode.IsSpace(r) {
+		// whitespace only moves the pen position
+		f.glyphs[r] = g
+		return g, nil
+	}
+
+	sf, err := f.font.RenderUTF8Blended(string(r), colors.White)
+	if err != nil {
+		return Glyph{}, errors.Trace(err)
+	}
+	defer sf.Free()
+
+	conv, err := sf.ConvertFormat(sdl.PIXELFORMAT_ARGB8888, 0)
+	if err != nil {
+		return Glyph{}, errors.Trace(err)
+	}
+	defer conv.Free()
+
+	page, loc, err := f.alloc(conv.W, conv.H)
+	if err != nil {
+		return Glyph{}, err
+	}
+	if err = page.texture.Update(&loc, conv.Pixels(), int(conv.Pitch)); err != nil {
+		return Glyph{}, errors.Trace(err)
+	}
+
+	page.Add(string(r), loc)
+	g.Texture = page.texture
+	g.Location = loc
+
+	f.glyphs[r] = g
+	return g, nil
+}
+
+// Kerning returns the kerning between prev and r. It is calculated from the
+// difference between the rendered size of both runes together and their
+// individual advances.
+func (f *TrueTypeFont) Kerning(prev, r rune) int32 {
+	if !f.font.GetKerning() {
+		return 0
+	}
+
+	pair := [2]rune{prev, r}
+	if k, ok := f.kerning[pair]; ok {
+		return k
+	}
+
+	var k int32
+	if w, _, err := f.font.SizeUTF8(string(pair[:])); err == nil {
+		a, errA := f.Glyph(prev)
+		b, errB := f.Glyph(r)
+		if errA == nil && errB == nil {
+			k = int32(w) - a.Advance - b.Advance
+		}
+	}
+
+	f.kerning[pair] = k
+	return k
+}
+
+// alloc reserves an area of w by h pixels within the last glyph page. A new
+// page is created when the glyph does not fit.
+func (f *TrueTypeFont) alloc(w, h int32) (*TextureAtlas, sdl.Rect, error) {
+	if w > GlyphPageSize || h > GlyphPageSize {
+		return nil, sdl.Rect{}, errors.Newf("sdlkit: glyph of %dx%d does not fit in a glyph page", w, h)
+	}
+
+	if len(f.pages) != 0 && f.penX+w > GlyphPageSize {
+		// continue on the next row
+		f.penX = 0
+		f.penY += f.rowH + 1
+		f.rowH = 0
+	}
+	if len(f.pages) == 0 || f.penY+h > GlyphPageSize {
+		tx, err := f.ren.CreateTexture(
+			sdl.PIXELFORMAT_ARGB8888,
+			sdl.TEXTUREACCESS_STATIC,
+			GlyphPageSize,
+			GlyphPageSize,
+		)
+		if err != nil {
+			return nil, sdl.Rect{}, errors.Trace(err)
+		}
+		if err = tx.SetBlendMode(sdl.BLENDMODE_BLEND); err != nil {
+			return nil, sdl.Rect{}, errors.Trace(err)
+		}
+
+		f.pages = append(f.pages, NewTextureAtlas(tx, nil))
+		f.penX, f.penY, f.rowH = 0, 0, 0
+	}
+
+	loc := sdl.Rect{X: f.penX, Y: f.penY, W: w, H: h}
+	f.penX += w + 1
+	if h > f.rowH {
+		f.rowH = h
+	}
+
+	return f.pages[len(f.pages)-1], loc, nil
+}
+
+// Destroy destroys all glyph pages. It does not close the underlying
+// sdlttf.Font.
+func (f *TrueTypeFont) Destroy() error {
+	unwatchAsset(f)
+	return f.destroyPages()
+}
+
+// destroyPages destroys all glyph pages, after which the glyphs are rendered
+// again when needed.
+func (f *TrueTypeFont) destroyPages() error {
+	var err error
+	for _, page := range f.pages {
+		errors.Append(&err, page.Destroy())
+	}
+
+	f.pages = nil
+	f.glyphs = make(map[rune]Glyph, 96)
+	f.penX, f.penY, f.rowH = 0, 0, 0
+	return err
+}
@@ -106,3 +106,63 @@ func (t Transform) Matrix() Matrix {
 		t.TranslateY,
 	)
 }
+
+// TranslationMatrix creates a Matrix which moves the target by x and y.
+func TranslationMatrix(x, y float64) Matrix {
+	return Matrix{1, 0, x, 0, 1, y, 0, 0, 1}
+}
+
+// Multiply returns the product of Matrix m and n. Applying the result to a
+// target is the same as first applying n and then m.
+func (m Matrix) Multiply(n Matrix) Matrix {
+	var res Matrix
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			i := row * 3
+			res[i+col] = m[i]*n[col] + m[i+1]*n[3+col] + m[i+2]*n[6+col]
+		}
+	}
+	return res
+}
+
+// Invert returns the inverse of Matrix m, which reverses its transformation.
+// It returns false when m cannot be inverted.
+func (m Matrix) Invert() (Matrix, bool) {
+	a, c, tx := m[ME_A], m[ME_C], m[ME_TX]
+	b, d, ty := m[ME_B], m[ME_D], m[ME_TY]
+
+	det := a*d - b*c
+	if det == 0 {
+		return Matrix{}, false
+	}
+
+	return TransformMatrix(
+		d/det,
+		-b/det,
+		-c/det,
+		a/det,
+		(c*ty-d*tx)/det,
+		(b*tx-a*ty)/det,
+	), true
+}
+
+// TransformXY applies Matrix m to the point at x and y.
+func (m Matrix) TransformXY(x, y float64) (float64, float64) {
+	return (x * m[ME_A]) + (y * m[ME_C]) + m[ME_TX],
+		(x * m[ME_B]) + (y * m[ME_D]) + m[ME_TY]
+}
+
+// Decompose returns the rotation, in radians, and scale of Matrix m. It
+// assumes m does not contain any skew. A mirrored Matrix results in a negative
+// scaleY.
+func (m Matrix) Decompose() (rotation, scaleX, scaleY float64) {
+	a, b := m[ME_A], m[ME_B]
+	scaleX = math.Sqrt(a*a + b*b)
+	if scaleX == 0 {
+		return 0, 0, 0
+	}
+
+	rotation = math.Atan2(b, a)
+	scaleY = (a*m[ME_D] - b*m[ME_C]) / scaleX
+	return
+}
@@ -5,6 +5,7 @@
 package geom
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -15,3 +16,41 @@ func TestScaleMatrix(t *testing.T) {
 	matrix := ScaleMatrix(x, y)
 	assert.Equal(t, Matrix{x, 0, 0, 0, y, 0, 0, 0, 1}, matrix)
 }
+
+func TestMatrix_Multiply(t *testing.T) {
+	m := TranslationMatrix(10, 20).Multiply(ScaleMatrix(2, 3))
+	x, y := m.TransformXY(1, 1)
+	assert.Equal(t, 12.0, x)
+	assert.Equal(t, 23.0, y)
+
+	assert.Equal(t, m, m.Multiply(IdentityMatrix()))
+	assert.Equal(t, m, IdentityMatrix().Multiply(m))
+}
+
+func TestMatrix_Invert(t *testing.T) {
+	m := TranslationMatrix(rng.Float64()*100, rng.Float64()*100).
+		Multiply(RotationMatrix(rng.Float64() * math.Pi)).
+		Multiply(ScaleMatrix(1+rng.Float64(), 1+rng.Float64()))
+
+	inv, ok := m.Invert()
+	assert.True(t, ok)
+
+	x, y := rng.Float64()*100, rng.Float64()*100
+	wx, wy := m.TransformXY(x, y)
+	lx, ly := inv.TransformXY(wx, wy)
+	assert.InDelta(t, x, lx, 1e-9)
+	assert.InDelta(t, y, ly, 1e-9)
+
+	_, ok = ScaleMatrix(0, 1).Invert()
+	assert.False(t, ok)
+}
+
+func TestMatrix_Decompose(t *testing.T) {
+	rot := rng.Float64() * math.Pi
+	sx, sy := 1+rng.Float64(), -1-rng.Float64()
+
+	r, x, y := RotationMatrix(rot).Multiply(ScaleMatrix(sx, sy)).Decompose()
+	assert.InDelta(t, rot, r, 1e-9)
+	assert.InDelta(t, sx, x, 1e-9)
+	assert.InDelta(t, sy, y, 1e-9)
+}
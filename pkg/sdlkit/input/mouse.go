@@ -23,6 +23,14 @@ const (
 	TrackAllMouseButtons = TrackMouseBtnLeft | TrackMouseBtnRight | TrackMouseBtnMiddle | TrackMouseBtnX1 | TrackMouseBtnX2
 )
 
+// WorldConverter converts logical positions to positions in the world, eg.
+// *sdlkit.Camera.
+type WorldConverter interface {
+	LogicalToWorld(x, y float64) (float64, float64)
+}
+
+// MouseState keeps track of the mouse. Its position is the logical position
+// within the Stage, as reported by SDL.
 type MouseState struct {
 	X, Y float64
 	BtnLeft,
@@ -70,6 +78,14 @@ func (ms *MouseState) GetY() float64 {
 	return res
 }
 
+// WorldPos returns the position of the mouse in the world, as seen by cam.
+func (ms *MouseState) WorldPos(cam WorldConverter) (float64, float64) {
+	ms.mutex.RLock()
+	x, y := ms.X, ms.Y
+	ms.mutex.RUnlock()
+	return cam.LogicalToWorld(x, y)
+}
+
 func (ms *MouseState) HandleMouseButtonEvent(e *sdl.MouseButtonEvent) error {
 	switch e.Button {
 	case sdl.BUTTON_LEFT:
@@ -135,6 +151,15 @@ func (btn *MouseBtnState) GetY() float64 {
 	return res
 }
 
+// WorldPos returns the position in the world, as seen by cam, where the
+// button was last pressed or released.
+func (btn *MouseBtnState) WorldPos(cam WorldConverter) (float64, float64) {
+	btn.mutex.RLock()
+	x, y := btn.X, btn.Y
+	btn.mutex.RUnlock()
+	return cam.LogicalToWorld(x, y)
+}
+
 func (btn *MouseBtnState) updateMouseBtnState(e *sdl.MouseButtonEvent) {
 	btn.mutex.Lock()
 	btn.X = float64(e.X)
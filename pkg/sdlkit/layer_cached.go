@@ -0,0 +1,191 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sdlkit
+
+import (
+	"github.com/go-pogo/errors"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// CachedLayer draws the Renderables of its Layer onto a render target
+// texture. This texture is only redrawn when the CachedLayer is marked dirty
+// or resized, rendering the CachedLayer itself is a single texture copy.
+// Renderables which implement Drawable are drawn onto a Canvas, wrap a
+// Drawable which is not a Renderable with DrawableFunc to add it.
+// Register the CachedLayer with an event.Manager so it can recover from
+// sdl.RENDER_TARGETS_RESET and sdl.RENDER_DEVICE_RESET events.
+type CachedLayer struct {
+	Layer
+
+	// X and Y indicate the position of the top left corner of the
+	// CachedLayer.
+	X, Y float64
+
+	canvas  *Canvas
+	texture *sdl.Texture
+	size    [2]int32
+	dirty   bool
+}
+
+// NewCachedLayer creates a new CachedLayer with the provided size. The
+// Renderables are drawn relative to the top left corner of the CachedLayer, using
+// a Canvas of its own so the state of the provided Canvas is left untouched.
+func NewCachedLayer(canvas *Canvas, w, h int32, r ...Renderable) *CachedLayer {
+	return &CachedLayer{
+		Layer:  r,
+		canvas: NewCanvas(canvas.Renderer()),
+		size:   [2]int32{w, h},
+		dirty:  true,
+	}
+}
+
+// Texture returns the sdl.Texture the CachedLayer is drawn on. It returns nil
+// when the CachedLayer has not been drawn yet.
+func (cl *CachedLayer) Texture() *sdl.Texture { return cl.texture }
+
+func (cl *CachedLayer) Width() int32  { return cl.size[0] }
+func (cl *CachedLayer) Height() int32 { return cl.size[1] }
+
+func (cl *CachedLayer) GetX() float64  { return cl.X }
+func (cl *CachedLayer) GetY() float64  { return cl.Y }
+func (cl *CachedLayer) SetX(x float64) { cl.X = x }
+func (cl *CachedLayer) SetY(y float64) { cl.Y = y }
+
+// IsDirty indicates if the CachedLayer is redrawn on the next Update.
+func (cl *CachedLayer) IsDirty() bool { return cl.dirty }
+
+// MarkDirty marks the CachedLayer so its Renderables are redrawn on the next
+// Update. Call it whenever the state of any of the Renderables has changed.
+func (cl *CachedLayer) MarkDirty() { cl.dirty = true }
+
+func (cl *CachedLayer) Clear() {
+	cl.Layer.Clear()
+	cl.dirty = true
+}
+
+func (cl *CachedLayer) Append(r ...Renderable) {
+	cl.Layer.Append(r...)
+	cl.dirty = true
+}
+
+func (cl *CachedLayer) Prepend(r ...Renderable) {
+	cl.Layer.Prepend(r...)
+	cl.dirty = true
+}
+
+// Resize changes the size of the CachedLayer. Its texture is recreated and
+// redrawn on the next Update.
+func (cl *CachedLayer) Resize(w, h int32) error {
+	if cl.size[0] == w && cl.size[1] == h {
+		return nil
+	}
+
+	cl.size[0], cl.size[1] = w, h
+	cl.dirty = true
+	return cl.destroyTexture()
+}
+
+// Update redraws the Renderables onto the CachedLayer's texture when it is
+// dirty. A new texture is created when none exists.
+func (cl *CachedLayer) Update() error {
+	if !cl.dirty && cl.texture != nil {
+		return nil
+	}
+	if cl.texture == nil {
+		tx, err := cl.canvas.Renderer().CreateTexture(
+			sdl.PIXELFORMAT_RGBA8888,
+			sdl.TEXTUREACCESS_TARGET,
+			cl.size[0],
+			cl.size[1],
+		)
+		if err != nil {
+			return errors.Trace(err)
+		}
+
+		cl.texture = tx
+	}
+
+	// the private canvas has no camera, so the renderables are drawn relative
+	// to the CachedLayer instead of the world; Done restores the render
+	// target that was set before the update
+	canvas := cl.canvas
+	if err := canvas.SetTarget(cl.texture); err != nil {
+		return errors.Trace(err)
+	}
+
+	ren := canvas.Renderer()
+	canvas.catchErr(
+		ren.SetDrawColor(0, 0, 0, 0),
+		ren.Clear(),
+	)
+	cl.Layer.Draw(canvas)
+
+	err := canvas.Done()
+	errors.Append(&err, cl.texture.SetBlendMode(sdl.BLENDMODE_BLEND))
+	if err != nil {
+		return err
+	}
+
+	cl.dirty = false
+	return nil
+}
+
+func (cl *CachedLayer) dest() sdl.Rect {
+	return sdl.Rect{
+		X: int32(cl.X),
+		Y: int32(cl.Y),
+		W: cl.size[0],
+		H: cl.size[1],
+	}
+}
+
+// Render updates the CachedLayer when needed and copies its texture to the
+// sdl.Renderer. The Canvas' Camera is ignored.
+func (cl *CachedLayer) Render(ren *sdl.Renderer) error {
+	if err := cl.Update(); err != nil {
+		return err
+	}
+
+	dest := cl.dest()
+	return ren.Copy(cl.texture, nil, &dest)
+}
+
+// Draw updates the CachedLayer when needed and draws its texture onto the
+// Canvas, taking the Canvas' Camera into account.
+func (cl *CachedLayer) Draw(canvas *Canvas) {
+	if err := cl.Update(); err != nil {
+		canvas.catchErr(err)
+		return
+	}
+
+	canvas.DrawTextureEx(cl.texture, nil, cl.dest(), 0, sdl.Point{}, sdl.FLIP_NONE)
+}
+
+// HandleRenderEvent marks the CachedLayer dirty when the render targets are
+// reset, or recreates its texture when the render device is reset.
+func (cl *CachedLayer) HandleRenderEvent(e *sdl.RenderEvent) error {
+	switch e.Type {
+	case sdl.RENDER_TARGETS_RESET:
+		cl.dirty = true
+	case sdl.RENDER_DEVICE_RESET:
+		// the texture is no longer valid and cannot be reused
+		cl.texture = nil
+		cl.dirty = true
+	}
+	return nil
+}
+
+func (cl *CachedLayer) destroyTexture() error {
+	if cl.texture == nil {
+		return nil
+	}
+
+	err := cl.texture.Destroy()
+	cl.texture = nil
+	return errors.Trace(err)
+}
+
+// Destroy destroys the CachedLayer's texture.
+func (cl *CachedLayer) Destroy() error { return cl.destroyTexture() }
@@ -0,0 +1,73 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pak
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/go-pogo/errors"
+)
+
+// Checksum returns the hex encoded SHA-256 checksum of the file with name.
+func Checksum(fsys fs.FS, name string) (string, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", errors.Trace(err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Checksums returns the checksums of all files within fsys, except the ones
+// for which skip returns true.
+func Checksums(fsys fs.FS, skip func(name string) bool) (map[string]string, error) {
+	res := make(map[string]string)
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || (skip != nil && skip(name)) {
+			return err
+		}
+
+		res[name], err = Checksum(fsys, name)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Verify verifies the files within fsys against checksums, which contains the
+// hex encoded SHA-256 checksum of each file by name. The returned error
+// contains all missing and mismatching files.
+func Verify(fsys fs.FS, checksums map[string]string) error {
+	names := make([]string, 0, len(checksums))
+	for name := range checksums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var err error
+	for _, name := range names {
+		sum, sumErr := Checksum(fsys, name)
+		if sumErr != nil {
+			errors.Append(&err, errors.Wrapf(sumErr, "pak: unable to verify `%s`", name))
+			continue
+		}
+		if !strings.EqualFold(sum, checksums[name]) {
+			errors.Append(&err, errors.Newf("pak: checksum mismatch of `%s`", name))
+		}
+	}
+	return err
+}
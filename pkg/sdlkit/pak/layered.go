@@ -0,0 +1,223 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pak
+
+import (
+	"io"
+	"io/fs"
+	"sort"
+
+	"github.com/go-pogo/errors"
+)
+
+// LayeredFS combines several filesystems into one. A file within a layer
+// overrides the file with the same name within all layers below it, which
+// makes it possible to distribute mods and patches as separate archives.
+// Directories contain the merged entries of all layers.
+type LayeredFS struct {
+	// layers are in order of priority, the last layer is on top
+	layers []fs.FS
+}
+
+var (
+	_ fs.ReadFileFS = new(LayeredFS)
+	_ fs.ReadDirFS  = new(LayeredFS)
+	_ fs.StatFS     = new(LayeredFS)
+)
+
+// NewLayeredFS creates a LayeredFS with layers in order of priority, the
+// first layer has the lowest priority.
+func NewLayeredFS(layers ...fs.FS) *LayeredFS {
+	return &LayeredFS{layers: layers}
+}
+
+// OpenLayers opens the archives with names and layers them in order of
+// priority, the first archive has the lowest priority. The archives are
+// closed when the LayeredFS is closed.
+func OpenLayers(names ...string) (*LayeredFS, error) {
+	l := &LayeredFS{layers: make([]fs.FS, 0, len(names))}
+	for _, name := range names {
+		a, err := Open(name)
+		if err != nil {
+			errors.Append(&err, l.Close())
+			return nil, err
+		}
+
+		l.layers = append(l.layers, a)
+	}
+	return l, nil
+}
+
+// Push adds fsys as a new top layer, which overrides all existing layers.
+func (l *LayeredFS) Push(fsys fs.FS) { l.layers = append(l.layers, fsys) }
+
+// Len returns the amount of layers.
+func (l *LayeredFS) Len() int { return len(l.layers) }
+
+// Layer returns the index of the layer which provides the file with name, or
+// -1 when it does not exist within any layer.
+func (l *LayeredFS) Layer(name string) int {
+	for i := len(l.layers) - 1; i >= 0; i-- {
+		if _, err := fs.Stat(l.layers[i], name); err == nil {
+			return i
+		}
+	}
+	return -1
+}
+
+// VerifyLayers verifies each layer which contains the file with name against
+// its own checksums, as returned by sums for the contents of that file. The
+// checksums of a layer are verified against the layer and the layers below
+// it, so a patch may list the unchanged files of the layers it is applied to,
+// while a mod which overrides files does not fail the verification of the
+// layers below it. Layers without the file are not verified.
+func (l *LayeredFS) VerifyLayers(name string, sums func(data []byte) (map[string]string, error)) error {
+	var err error
+	for i, fsys := range l.layers {
+		data, readErr := fs.ReadFile(fsys, name)
+		if readErr != nil {
+			if !errors.Is(readErr, fs.ErrNotExist) {
+				errors.Append(&err, errors.Wrapf(readErr, "pak: unable to read `%s` of layer %d", name, i))
+			}
+			continue
+		}
+
+		checksums, sumsErr := sums(data)
+		if sumsErr != nil {
+			errors.Append(&err, errors.Wrapf(sumsErr, "pak: invalid checksums in `%s` of layer %d", name, i))
+			continue
+		}
+		errors.Append(&err, Verify(NewLayeredFS(l.layers[:i+1]...), checksums))
+	}
+	return err
+}
+
+// find calls fn for each layer, from the top down, until it returns an error
+// which does not indicate the file does not exist within the layer.
+func (l *LayeredFS) find(op, name string, fn func(fsys fs.FS) error) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	for i := len(l.layers) - 1; i >= 0; i-- {
+		err := fn(l.layers[i])
+		if err == nil || !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+}
+
+// Open opens the file with name from the top most layer which contains it.
+// A directory lists the merged entries of all layers.
+func (l *LayeredFS) Open(name string) (f fs.File, err error) {
+	err = l.find("open", name, func(fsys fs.FS) (err error) {
+		f, err = fsys.Open(name)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := f.Stat()
+	if err != nil || !fi.IsDir() {
+		return f, err
+	}
+
+	entries, err := l.ReadDir(name)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &layeredDir{File: f, entries: entries}, nil
+}
+
+func (l *LayeredFS) ReadFile(name string) (data []byte, err error) {
+	err = l.find("read", name, func(fsys fs.FS) (err error) {
+		data, err = fs.ReadFile(fsys, name)
+		return err
+	})
+	return data, err
+}
+
+func (l *LayeredFS) Stat(name string) (fi fs.FileInfo, err error) {
+	err = l.find("stat", name, func(fsys fs.FS) (err error) {
+		fi, err = fs.Stat(fsys, name)
+		return err
+	})
+	return fi, err
+}
+
+// ReadDir returns the merged entries of directory name of all layers, sorted
+// by name. An entry within a higher layer replaces the entry with the same
+// name of lower layers.
+func (l *LayeredFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	var found bool
+	entries := make(map[string]fs.DirEntry)
+	for _, fsys := range l.layers {
+		list, err := fs.ReadDir(fsys, name)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+
+		found = true
+		for _, e := range list {
+			entries[e.Name()] = e
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	res := make([]fs.DirEntry, 0, len(entries))
+	for _, e := range entries {
+		res = append(res, e)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name() < res[j].Name() })
+	return res, nil
+}
+
+// Close closes all layers which implement io.Closer.
+func (l *LayeredFS) Close() error {
+	var err error
+	for _, fsys := range l.layers {
+		if c, ok := fsys.(io.Closer); ok {
+			errors.Append(&err, c.Close())
+		}
+	}
+	return err
+}
+
+// layeredDir is a directory of a LayeredFS. It is read from the top most
+// layer, but lists the merged entries of all layers.
+type layeredDir struct {
+	fs.File
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *layeredDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rest) {
+		n = len(rest)
+	}
+
+	d.offset += n
+	return rest[:n], nil
+}
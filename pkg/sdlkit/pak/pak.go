@@ -0,0 +1,56 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package pak provides read-only filesystems which are backed by asset
+// archives, and which can be layered so mods and patches override the files
+// of the base game.
+package pak
+
+import (
+	"archive/zip"
+	"io"
+	"io/fs"
+
+	"github.com/go-pogo/errors"
+)
+
+// Archive is an fs.ReadFileFS of the files within a zip archive. A pak file is
+// a zip archive with a different extension.
+type Archive struct {
+	zr     *zip.Reader
+	closer io.Closer
+}
+
+// Open opens the archive with name.
+func Open(name string) (*Archive, error) {
+	rc, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "pak: unable to open archive `%s`", name)
+	}
+
+	return &Archive{zr: &rc.Reader, closer: rc}, nil
+}
+
+// NewArchive reads an archive of size from r, eg. an archive which is
+// embedded in the binary.
+func NewArchive(r io.ReaderAt, size int64) (*Archive, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	return &Archive{zr: zr}, nil
+}
+
+func (a *Archive) Open(name string) (fs.File, error) { return a.zr.Open(name) }
+
+func (a *Archive) ReadFile(name string) ([]byte, error) { return fs.ReadFile(a.zr, name) }
+
+// Close closes the archive when it is opened using Open.
+func (a *Archive) Close() error {
+	if a.closer == nil {
+		return nil
+	}
+	return a.closer.Close()
+}
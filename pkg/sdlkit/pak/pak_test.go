@@ -0,0 +1,175 @@
+package pak
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestArchive(t *testing.T, files map[string]string) *Archive {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for name, data := range files {
+		assert.NoError(t, w.Add(name, []byte(data)))
+	}
+	assert.NoError(t, w.Close())
+
+	a, err := NewArchive(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	return a
+}
+
+func TestWriter_Add(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	assert.NoError(t, w.Add("a.txt", nil))
+	assert.Error(t, w.Add("a.txt", nil))
+	assert.Error(t, w.Add("../b.txt", nil))
+	assert.Error(t, w.Add("/c.txt", nil))
+	assert.Equal(t, 1, w.Len())
+}
+
+func TestArchive(t *testing.T) {
+	a := newTestArchive(t, map[string]string{
+		"manifest.yaml":   "bundles: {}",
+		"sprites/a.png":   "png",
+		"sprites/b.json":  "{}",
+		"fonts/title.ttf": "ttf",
+	})
+
+	data, err := a.ReadFile("sprites/a.png")
+	assert.NoError(t, err)
+	assert.Equal(t, "png", string(data))
+
+	_, err = a.ReadFile("missing.png")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+
+	assert.NoError(t, fstest.TestFS(a, "manifest.yaml", "sprites/a.png", "sprites/b.json", "fonts/title.ttf"))
+}
+
+func TestLayeredFS(t *testing.T) {
+	base := newTestArchive(t, map[string]string{
+		"sprites/a.png": "base a",
+		"sprites/b.png": "base b",
+	})
+	patch := newTestArchive(t, map[string]string{
+		"sprites/b.png": "patch b",
+	})
+	mod := fstest.MapFS{
+		"sprites/c.png": {Data: []byte("mod c")},
+	}
+
+	l := NewLayeredFS(base, patch)
+	l.Push(mod)
+	assert.Equal(t, 3, l.Len())
+
+	for name, want := range map[string]string{
+		"sprites/a.png": "base a",
+		"sprites/b.png": "patch b",
+		"sprites/c.png": "mod c",
+	} {
+		data, err := l.ReadFile(name)
+		assert.NoError(t, err)
+		assert.Equal(t, want, string(data))
+	}
+
+	assert.Equal(t, 0, l.Layer("sprites/a.png"))
+	assert.Equal(t, 1, l.Layer("sprites/b.png"))
+	assert.Equal(t, -1, l.Layer("sprites/d.png"))
+
+	_, err := l.ReadFile("sprites/d.png")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+
+	entries, err := l.ReadDir("sprites")
+	assert.NoError(t, err)
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name()
+	}
+	assert.Equal(t, []string{"a.png", "b.png", "c.png"}, names)
+
+	assert.NoError(t, fstest.TestFS(l, "sprites/a.png", "sprites/b.png", "sprites/c.png"))
+	assert.NoError(t, l.Close())
+}
+
+func TestLayeredFS_VerifyLayers(t *testing.T) {
+	sum := func(data string) string {
+		h := sha256.Sum256([]byte(data))
+		return hex.EncodeToString(h[:])
+	}
+	// the manifest of a layer contains the checksums of all its files, and
+	// those of the layers below it
+	manifest := func(files ...string) string {
+		var buf strings.Builder
+		for i := 0; i < len(files); i += 2 {
+			buf.WriteString(files[i] + " " + sum(files[i+1]) + "\n")
+		}
+		return buf.String()
+	}
+	parse := func(data []byte) (map[string]string, error) {
+		res := make(map[string]string)
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			parts := strings.Fields(line)
+			if len(parts) != 2 {
+				return nil, errors.New("invalid line")
+			}
+			res[parts[0]] = parts[1]
+		}
+		return res, nil
+	}
+
+	base := newTestArchive(t, map[string]string{
+		"a.png":    "base a",
+		"b.png":    "base b",
+		"sums.txt": manifest("a.png", "base a", "b.png", "base b"),
+	})
+	patch := newTestArchive(t, map[string]string{
+		"b.png":    "patch b",
+		"sums.txt": manifest("a.png", "base a", "b.png", "patch b"),
+	})
+	// a mod without checksums, which overrides a file of the layers below it
+	mod := fstest.MapFS{
+		"a.png": {Data: []byte("mod a")},
+	}
+
+	l := NewLayeredFS(base, patch, mod)
+	assert.NoError(t, l.VerifyLayers("sums.txt", parse))
+	assert.Error(t, Verify(l, map[string]string{"a.png": sum("base a")}),
+		"verifying the merged layers fails because of the mod")
+
+	l = NewLayeredFS(base, fstest.MapFS{
+		"b.png":    {Data: []byte("corrupt b")},
+		"sums.txt": {Data: []byte(manifest("b.png", "patch b"))},
+	})
+	err := l.VerifyLayers("sums.txt", parse)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch of `b.png`")
+}
+
+func TestVerify(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.txt": {Data: []byte("hello")},
+		"b.txt": {Data: []byte("world")},
+	}
+
+	sums, err := Checksums(fsys, func(name string) bool { return name == "b.txt" })
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"a.txt": "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+	}, sums)
+	assert.NoError(t, Verify(fsys, sums))
+
+	sums["b.txt"] = sums["a.txt"]
+	sums["c.txt"] = sums["a.txt"]
+	err = Verify(fsys, sums)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch of `b.txt`")
+	assert.Contains(t, err.Error(), "unable to verify `c.txt`")
+}
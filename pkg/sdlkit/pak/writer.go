@@ -0,0 +1,79 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pak
+
+import (
+	"archive/zip"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/go-pogo/errors"
+)
+
+// Writer writes files to a new archive.
+type Writer struct {
+	zw    *zip.Writer
+	names map[string]struct{}
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		zw:    zip.NewWriter(w),
+		names: make(map[string]struct{}),
+	}
+}
+
+// Add adds a file with name and data to the archive. Image files, which are
+// already compressed, are stored as is.
+func (w *Writer) Add(name string, data []byte) error {
+	if !fs.ValidPath(name) || name == "." {
+		return errors.Newf("pak: invalid file name `%s`", name)
+	}
+	if _, ok := w.names[name]; ok {
+		return errors.Newf("pak: duplicate file `%s`", name)
+	}
+
+	method := zip.Deflate
+	switch strings.ToLower(path.Ext(name)) {
+	case ".png", ".jpg", ".jpeg", ".webp", ".ogg", ".mp3":
+		method = zip.Store
+	}
+
+	f, err := w.zw.CreateHeader(&zip.FileHeader{Name: name, Method: method})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if _, err = f.Write(data); err != nil {
+		return errors.Trace(err)
+	}
+
+	w.names[name] = struct{}{}
+	return nil
+}
+
+// AddFS adds all files of fsys to the archive, except the ones for which skip
+// returns true.
+func (w *Writer) AddFS(fsys fs.FS, skip func(name string) bool) error {
+	return fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || (skip != nil && skip(name)) {
+			return err
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		return w.Add(name, data)
+	})
+}
+
+// Len returns the amount of files added to the archive.
+func (w *Writer) Len() int { return len(w.names) }
+
+// Close finishes writing the archive. It does not close the underlying
+// io.Writer.
+func (w *Writer) Close() error { return errors.Trace(w.zw.Close()) }
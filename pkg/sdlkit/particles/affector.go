@@ -0,0 +1,126 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package particles
+
+import (
+	"math"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom"
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/physics"
+)
+
+// Affector changes a Particle each update, after it has moved.
+type Affector interface {
+	Affect(p *Particle, dt float64)
+}
+
+type AffectorFunc func(p *Particle, dt float64)
+
+func (fn AffectorFunc) Affect(p *Particle, dt float64) { fn(p, dt) }
+
+// Gravity accelerates particles with X and Y pixels per second squared.
+type Gravity struct {
+	X, Y float64
+}
+
+func (g Gravity) Affect(p *Particle, dt float64) {
+	p.VX += g.X * dt
+	p.VY += g.Y * dt
+}
+
+// Drag slows particles down, Factor is the part of the velocity which is lost
+// per second.
+type Drag struct {
+	Factor float64
+}
+
+func (d Drag) Affect(p *Particle, dt float64) {
+	f := 1 - d.Factor*dt
+	if f < 0 {
+		f = 0
+	}
+
+	p.VX *= f
+	p.VY *= f
+}
+
+// Attractor pulls particles towards X, Y with Strength pixels per second
+// squared. A negative Strength pushes particles away. When Radius is larger
+// than 0, only particles within the radius are affected.
+type Attractor struct {
+	X, Y     float64
+	Strength float64
+	Radius   float64
+}
+
+func (a Attractor) Affect(p *Particle, dt float64) {
+	dx, dy := a.X-p.X, a.Y-p.Y
+	d := math.Hypot(dx, dy)
+	if d < 1 || (a.Radius > 0 && d > a.Radius) {
+		return
+	}
+
+	f := a.Strength * dt / d
+	p.VX += dx * f
+	p.VY += dy * f
+}
+
+// Collide bounces particles off physics.Colliders. Bounce is the part of the
+// velocity which is kept after a collision. When Kill is true particles are
+// killed on collision instead.
+type Collide struct {
+	Colliders []physics.Collider
+	Bounce    float64
+	Kill      bool
+}
+
+func (c *Collide) Affect(p *Particle, dt float64) {
+	for _, col := range c.Colliders {
+		if !col.HitTest(p.X, p.Y) {
+			continue
+		}
+		if c.Kill {
+			p.Kill()
+			return
+		}
+
+		// move back to the position before the collision
+		px, py := p.X-p.VX*dt, p.Y-p.VY*dt
+		nx, ny := collisionNormal(col, px, py)
+
+		// reflect the velocity along the normal
+		dot := p.VX*nx + p.VY*ny
+		if dot < 0 {
+			p.VX = (p.VX - 2*dot*nx) * c.Bounce
+			p.VY = (p.VY - 2*dot*ny) * c.Bounce
+		}
+		p.X, p.Y = px, py
+		return
+	}
+}
+
+// collisionNormal returns the normal of the Collider's surface nearest to the
+// position x, y outside of it.
+func collisionNormal(col physics.Collider, x, y float64) (float64, float64) {
+	if c, ok := col.Shape().(*geom.Circle); ok {
+		dx, dy := x-c.X, y-c.Y
+		if d := math.Hypot(dx, dy); d > 0 {
+			return dx / d, dy / d
+		}
+		return 0, -1
+	}
+
+	b := col.Bounds()
+	switch {
+	case x < b.TopLeft.X:
+		return -1, 0
+	case x >= b.BottomRight.X:
+		return 1, 0
+	case y >= b.BottomRight.Y:
+		return 0, 1
+	default:
+		return 0, -1
+	}
+}
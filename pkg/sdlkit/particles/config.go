@@ -0,0 +1,285 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package particles
+
+import (
+	"bytes"
+	"encoding/json"
+	"path"
+
+	"github.com/go-pogo/errors"
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/colors"
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom"
+)
+
+// EmitterConfig describes an Emitter, eg. as read from a JSON data file.
+// Ranges are either a single number or a [min, max] pair. Curves are either a
+// list of values or a list of [t, value] pairs, colors are hex strings.
+type EmitterConfig struct {
+	Capacity  int         `json:"capacity"`
+	Shape     ShapeConfig `json:"shape"`
+	Rate      float64     `json:"rate"`
+	BurstSize int         `json:"burst"`
+	AutoStart bool        `json:"autostart"`
+
+	Lifetime  *Range `json:"lifetime"`
+	Speed     *Range `json:"speed"`
+	Direction *Range `json:"direction"`
+	Rotation  *Range `json:"rotation"`
+	Spin      *Range `json:"spin"`
+	Size      *Range `json:"size"`
+
+	SizeCurve     Curve      `json:"sizeCurve"`
+	AlphaCurve    Curve      `json:"alphaCurve"`
+	RotationCurve Curve      `json:"rotationCurve"`
+	Colors        ColorCurve `json:"colors"`
+
+	Affectors []AffectorConfig `json:"affectors"`
+
+	// Texture is the file of the texture, relative to the data file.
+	Texture   string `json:"texture"`
+	Primitive string `json:"primitive"`
+	Blend     string `json:"blend"`
+}
+
+type ShapeConfig struct {
+	Type   string       `json:"type"` // point, circle, rect or polygon
+	Radius float64      `json:"radius"`
+	W      float64      `json:"w"`
+	H      float64      `json:"h"`
+	Edge   bool         `json:"edge"`
+	Points [][2]float64 `json:"points"`
+}
+
+// AffectorConfig describes an Affector. Collide affectors cannot be described
+// because they depend on the colliders within a game.
+type AffectorConfig struct {
+	Type     string  `json:"type"` // gravity, drag or attractor
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	Factor   float64 `json:"factor"`
+	Strength float64 `json:"strength"`
+	Radius   float64 `json:"radius"`
+}
+
+func ParseEmitterConfig(data []byte) (EmitterConfig, error) {
+	var cfg EmitterConfig
+	err := json.Unmarshal(data, &cfg)
+	return cfg, errors.Trace(err)
+}
+
+// LoadEmitter loads an EmitterConfig from a JSON file and creates a new
+// Emitter from it, including its texture.
+func LoadEmitter(loader *sdlkit.AssetsLoader, file string) (*Emitter, error) {
+	data, err := loader.Read(file)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := ParseEmitterConfig(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var tc sdlkit.TextureClip
+	if cfg.Texture != "" {
+		tc, err = loader.TextureClip(path.Join(path.Dir(file), cfg.Texture))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return NewEmitterFromConfig(cfg, tc)
+}
+
+// NewEmitterFromConfig creates a new Emitter from cfg, which draws its
+// particles using texture when it is not empty.
+func NewEmitterFromConfig(cfg EmitterConfig, texture sdlkit.TextureClip) (*Emitter, error) {
+	if cfg.Capacity <= 0 {
+		return nil, errors.Newf("particles: capacity must be larger than 0")
+	}
+
+	e := NewEmitter(cfg.Capacity)
+	e.Rate = cfg.Rate
+	e.BurstSize = cfg.BurstSize
+	e.SizeCurve = cfg.SizeCurve
+	e.AlphaCurve = cfg.AlphaCurve
+	e.RotationCurve = cfg.RotationCurve
+	e.Colors = cfg.Colors
+	e.Texture = texture
+
+	for _, r := range []struct {
+		dst *Range
+		src *Range
+	}{
+		{&e.Lifetime, cfg.Lifetime},
+		{&e.Speed, cfg.Speed},
+		{&e.Direction, cfg.Direction},
+		{&e.Rotation, cfg.Rotation},
+		{&e.Spin, cfg.Spin},
+		{&e.Size, cfg.Size},
+	} {
+		if r.src != nil {
+			*r.dst = *r.src
+		}
+	}
+
+	var err error
+	if e.Shape, err = cfg.Shape.shape(); err != nil {
+		return nil, err
+	}
+
+	for _, ac := range cfg.Affectors {
+		a, err := ac.affector()
+		if err != nil {
+			return nil, err
+		}
+		e.Affectors = append(e.Affectors, a)
+	}
+
+	switch cfg.Primitive {
+	case "", "circle":
+		e.Primitive = DrawCircles
+	case "square":
+		e.Primitive = DrawSquares
+	default:
+		return nil, errors.Newf("particles: unknown primitive `%s`", cfg.Primitive)
+	}
+
+	switch cfg.Blend {
+	case "", "blend":
+		e.BlendMode = sdl.BLENDMODE_BLEND
+	case "none":
+		e.BlendMode = sdl.BLENDMODE_NONE
+	case "add":
+		e.BlendMode = sdl.BLENDMODE_ADD
+	case "mod":
+		e.BlendMode = sdl.BLENDMODE_MOD
+	default:
+		return nil, errors.Newf("particles: unknown blend mode `%s`", cfg.Blend)
+	}
+
+	if cfg.AutoStart {
+		e.Start()
+	}
+	return e, nil
+}
+
+func (sc ShapeConfig) shape() (Shape, error) {
+	switch sc.Type {
+	case "", "point":
+		return PointShape{}, nil
+	case "circle":
+		return CircleShape{Radius: sc.Radius, Edge: sc.Edge}, nil
+	case "rect":
+		return RectShape{W: sc.W, H: sc.H, Edge: sc.Edge}, nil
+	case "polygon":
+		pts := make([]geom.Point, len(sc.Points))
+		for i, pt := range sc.Points {
+			pts[i] = geom.Point{X: pt[0], Y: pt[1]}
+		}
+		return PolygonEdgeShape{Points: pts}, nil
+	default:
+		return nil, errors.Newf("particles: unknown shape `%s`", sc.Type)
+	}
+}
+
+func (ac AffectorConfig) affector() (Affector, error) {
+	switch ac.Type {
+	case "gravity":
+		return Gravity{X: ac.X, Y: ac.Y}, nil
+	case "drag":
+		return Drag{Factor: ac.Factor}, nil
+	case "attractor":
+		return Attractor{X: ac.X, Y: ac.Y, Strength: ac.Strength, Radius: ac.Radius}, nil
+	default:
+		return nil, errors.Newf("particles: unknown affector `%s`", ac.Type)
+	}
+}
+
+// UnmarshalJSON decodes either a single number or a [min, max] pair.
+func (r *Range) UnmarshalJSON(data []byte) error {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		var v float64
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+
+		*r = Fixed(v)
+		return nil
+	}
+
+	var x [2]float64
+	if err := json.Unmarshal(data, &x); err != nil {
+		return err
+	}
+
+	r.Min, r.Max = x[0], x[1]
+	return nil
+}
+
+// UnmarshalJSON decodes either a list of values, which are evenly spread
+// over time, or a list of [t, value] pairs.
+func (c *Curve) UnmarshalJSON(data []byte) error {
+	var values []float64
+	if err := json.Unmarshal(data, &values); err == nil {
+		*c = NewCurve(values...)
+		return nil
+	}
+
+	var keys [][2]float64
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return err
+	}
+
+	*c = make(Curve, len(keys))
+	for i, k := range keys {
+		(*c)[i] = Key{T: k[0], V: k[1]}
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes either a list of hex colors, which are evenly spread
+// over time, or a list of [t, color] pairs.
+func (c *ColorCurve) UnmarshalJSON(data []byte) error {
+	var hex []string
+	if err := json.Unmarshal(data, &hex); err == nil {
+		cols := make([]sdl.Color, len(hex))
+		for i, h := range hex {
+			if cols[i], err = colors.ParseHex(h); err != nil {
+				return err
+			}
+		}
+
+		*c = NewColorCurve(cols...)
+		return nil
+	}
+
+	var keys [][2]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return err
+	}
+
+	*c = make(ColorCurve, len(keys))
+	for i, k := range keys {
+		var h string
+		if err := json.Unmarshal(k[0], &(*c)[i].T); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(k[1], &h); err != nil {
+			return err
+		}
+
+		col, err := colors.ParseHex(h)
+		if err != nil {
+			return err
+		}
+		(*c)[i].Color = col
+	}
+	return nil
+}
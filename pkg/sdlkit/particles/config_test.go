@@ -0,0 +1,81 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package particles
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
+)
+
+const testEmitterJson = `{
+ "capacity": 200,
+ "shape": {"type": "circle", "radius": 8, "edge": true},
+ "rate": 30,
+ "burst": 50,
+ "lifetime": [0.5, 1.5],
+ "speed": 120,
+ "direction": [-120, -60],
+ "sizeCurve": [1, 0.5, 0],
+ "alphaCurve": [[0, 1], [0.8, 1], [1, 0]],
+ "colors": ["#ffcc00", "#ff0000"],
+ "affectors": [
+  {"type": "gravity", "y": 200},
+  {"type": "drag", "factor": 0.5}
+ ],
+ "primitive": "square",
+ "blend": "add"
+}`
+
+func TestNewEmitterFromConfig(t *testing.T) {
+	cfg, err := ParseEmitterConfig([]byte(testEmitterJson))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	e, err := NewEmitterFromConfig(cfg, sdlkit.TextureClip{})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, 200, e.Pool().Cap())
+	assert.Equal(t, CircleShape{Radius: 8, Edge: true}, e.Shape)
+	assert.Equal(t, 30.0, e.Rate)
+	assert.Equal(t, 50, e.Trigger())
+	assert.False(t, e.IsActive())
+	assert.Equal(t, Range{Min: 0.5, Max: 1.5}, e.Lifetime)
+	assert.Equal(t, Fixed(120), e.Speed)
+	assert.Equal(t, Fixed(4), e.Size, "default")
+	assert.Equal(t, NewCurve(1, 0.5, 0), e.SizeCurve)
+	assert.Equal(t, Curve{{T: 0, V: 1}, {T: 0.8, V: 1}, {T: 1, V: 0}}, e.AlphaCurve)
+	assert.Equal(t, ColorCurve{
+		{T: 0, Color: sdl.Color{R: 0xff, G: 0xcc, A: 0xff}},
+		{T: 1, Color: sdl.Color{R: 0xff, A: 0xff}},
+	}, e.Colors)
+	assert.Equal(t, []Affector{Gravity{Y: 200}, Drag{Factor: 0.5}}, e.Affectors)
+	assert.Equal(t, DrawSquares, e.Primitive)
+	assert.Equal(t, sdl.BLENDMODE_ADD, e.BlendMode)
+}
+
+func TestNewEmitterFromConfig_errors(t *testing.T) {
+	tests := map[string]string{
+		"capacity":  `{}`,
+		"shape":     `{"capacity": 1, "shape": {"type": "star"}}`,
+		"affector":  `{"capacity": 1, "affectors": [{"type": "wind"}]}`,
+		"blend":     `{"capacity": 1, "blend": "multiply"}`,
+		"primitive": `{"capacity": 1, "primitive": "line"}`,
+	}
+
+	for name, data := range tests {
+		cfg, err := ParseEmitterConfig([]byte(data))
+		assert.NoError(t, err, name)
+
+		_, err = NewEmitterFromConfig(cfg, sdlkit.TextureClip{})
+		assert.Error(t, err, name)
+	}
+}
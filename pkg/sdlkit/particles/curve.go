@@ -0,0 +1,119 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package particles
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/math"
+)
+
+// Range is a range of values a random value is picked from.
+type Range struct {
+	Min, Max float64
+}
+
+// Fixed returns a Range which always results in v.
+func Fixed(v float64) Range { return Range{Min: v, Max: v} }
+
+func (r Range) Rand(rnd *rand.Rand) float64 {
+	if r.Max <= r.Min {
+		return r.Min
+	}
+	return r.Min + rnd.Float64()*(r.Max-r.Min)
+}
+
+// Key is a value at time T of a Curve, where T ranges from 0 (start) to 1
+// (end).
+type Key struct {
+	T, V float64
+}
+
+// Curve is a list of Keys ordered by their time. Values in between keys are
+// linearly interpolated.
+type Curve []Key
+
+// NewCurve creates a Curve with the values evenly spread over time.
+func NewCurve(values ...float64) Curve {
+	c := make(Curve, len(values))
+	for i, v := range values {
+		if len(values) > 1 {
+			c[i].T = float64(i) / float64(len(values)-1)
+		}
+		c[i].V = v
+	}
+	return c
+}
+
+// At returns the value of the Curve at time t. An empty Curve returns def.
+func (c Curve) At(t, def float64) float64 {
+	n := len(c)
+	if n == 0 {
+		return def
+	}
+	if t <= c[0].T {
+		return c[0].V
+	}
+	if t >= c[n-1].T {
+		return c[n-1].V
+	}
+
+	i := sort.Search(n, func(i int) bool { return c[i].T > t })
+	a, b := c[i-1], c[i]
+	return math.Lerp(a.V, b.V, (t-a.T)/(b.T-a.T))
+}
+
+type ColorKey struct {
+	T     float64
+	Color sdl.Color
+}
+
+// ColorCurve is a list of ColorKeys ordered by their time. Colors in between
+// keys are linearly interpolated.
+type ColorCurve []ColorKey
+
+// NewColorCurve creates a ColorCurve with the colors evenly spread over time.
+func NewColorCurve(colors ...sdl.Color) ColorCurve {
+	c := make(ColorCurve, len(colors))
+	for i, col := range colors {
+		if len(colors) > 1 {
+			c[i].T = float64(i) / float64(len(colors)-1)
+		}
+		c[i].Color = col
+	}
+	return c
+}
+
+// At returns the color of the ColorCurve at time t. An empty ColorCurve
+// returns def.
+func (c ColorCurve) At(t float64, def sdl.Color) sdl.Color {
+	n := len(c)
+	if n == 0 {
+		return def
+	}
+	if t <= c[0].T {
+		return c[0].Color
+	}
+	if t >= c[n-1].T {
+		return c[n-1].Color
+	}
+
+	i := sort.Search(n, func(i int) bool { return c[i].T > t })
+	a, b := c[i-1], c[i]
+	f := (t - a.T) / (b.T - a.T)
+	return sdl.Color{
+		R: lerpUint8(a.Color.R, b.Color.R, f),
+		G: lerpUint8(a.Color.G, b.Color.G, f),
+		B: lerpUint8(a.Color.B, b.Color.B, f),
+		A: lerpUint8(a.Color.A, b.Color.A, f),
+	}
+}
+
+func lerpUint8(a, b uint8, t float64) uint8 {
+	return uint8(math.Lerp(float64(a), float64(b), t) + 0.5)
+}
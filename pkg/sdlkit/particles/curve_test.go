@@ -0,0 +1,37 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package particles
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func TestCurve_At(t *testing.T) {
+	c := Curve{{T: 0, V: 1}, {T: 0.5, V: 3}, {T: 1, V: 0}}
+	tests := map[float64]float64{
+		-1:   1,
+		0:    1,
+		0.25: 2,
+		0.5:  3,
+		0.75: 1.5,
+		2:    0,
+	}
+
+	for in, want := range tests {
+		assert.InDelta(t, want, c.At(in, -1), 1e-9, "t=%v", in)
+	}
+	assert.Equal(t, 5.0, Curve(nil).At(0.5, 5))
+	assert.Equal(t, Curve{{T: 0, V: 1}, {T: 0.5, V: 2}, {T: 1, V: 3}}, NewCurve(1, 2, 3))
+}
+
+func TestColorCurve_At(t *testing.T) {
+	c := NewColorCurve(sdl.Color{R: 0xff, A: 0xff}, sdl.Color{B: 0xff})
+	assert.Equal(t, sdl.Color{R: 0x80, B: 0x80, A: 0x80}, c.At(0.5, sdl.Color{}))
+	assert.Equal(t, sdl.Color{B: 0xff}, c.At(1, sdl.Color{}))
+	assert.Equal(t, sdl.Color{G: 1}, ColorCurve(nil).At(0, sdl.Color{G: 1}))
+}
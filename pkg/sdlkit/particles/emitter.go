@@ -0,0 +1,253 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package particles
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/colors"
+	math2 "github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/math"
+)
+
+// Primitive is the Canvas primitive which is used to draw particles when an
+// Emitter has no texture.
+type Primitive uint8
+
+const (
+	DrawCircles Primitive = iota
+	DrawSquares
+)
+
+// Emitter emits particles from its Shape, either continuously at Rate
+// particles per second or in bursts. The particles are kept in a Pool with a
+// fixed capacity.
+type Emitter struct {
+	X, Y  float64
+	Shape Shape
+	// Rate is the number of particles emitted per second while the Emitter
+	// is active.
+	Rate float64
+	// BurstSize is the number of particles emitted by Trigger.
+	BurstSize int
+
+	Lifetime  Range // in seconds
+	Speed     Range // in pixels per second
+	Direction Range // in degrees
+	Rotation  Range // start rotation in degrees
+	Spin      Range // in degrees per second
+	Size      Range // start size in pixels
+
+	// SizeCurve and AlphaCurve multiply the start size and the alpha of a
+	// particle's color over its lifetime.
+	SizeCurve  Curve
+	AlphaCurve Curve
+	// RotationCurve is added to the rotation of a particle over its
+	// lifetime, in degrees.
+	RotationCurve Curve
+	// Colors is the color of a particle over its lifetime. Particles are
+	// white when empty.
+	Colors ColorCurve
+
+	Affectors []Affector
+
+	// Texture is drawn for each particle, scaled to its size. When there is
+	// no texture, particles are drawn using Primitive.
+	Texture   sdlkit.TextureClip
+	Primitive Primitive
+	// BlendMode is used to draw the Texture or Primitive of the particles.
+	BlendMode sdl.BlendMode
+
+	Rand *rand.Rand
+
+	pool   *Pool
+	active bool
+	accum  float64
+}
+
+// NewEmitter creates an inactive Emitter which can have up to capacity alive
+// particles.
+func NewEmitter(capacity int) *Emitter {
+	return &Emitter{
+		Shape:     PointShape{},
+		Lifetime:  Fixed(1),
+		Direction: Range{Min: 0, Max: 360},
+		Size:      Fixed(4),
+		BlendMode: sdl.BLENDMODE_BLEND,
+		Rand:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		pool:      NewPool(capacity),
+	}
+}
+
+func (e *Emitter) GetX() float64  { return e.X }
+func (e *Emitter) GetY() float64  { return e.Y }
+func (e *Emitter) SetX(x float64) { e.X = x }
+func (e *Emitter) SetY(y float64) { e.Y = y }
+
+func (e *Emitter) Pool() *Pool { return e.pool }
+
+// Particles returns the alive particles.
+func (e *Emitter) Particles() []Particle { return e.pool.Particles() }
+
+// Start continuously emits particles at Rate.
+func (e *Emitter) Start() { e.active = true }
+
+func (e *Emitter) Stop() {
+	e.active = false
+	e.accum = 0
+}
+
+func (e *Emitter) IsActive() bool { return e.active }
+
+// IsDone indicates if the Emitter is inactive and has no alive particles.
+func (e *Emitter) IsDone() bool { return !e.active && e.pool.Len() == 0 }
+
+// Burst emits n particles at once. It returns the number of particles which
+// are actually emitted, which is less than n when the Pool is full.
+func (e *Emitter) Burst(n int) int {
+	for i := 0; i < n; i++ {
+		p := e.pool.Spawn()
+		if p == nil {
+			return i
+		}
+		e.init(p)
+	}
+	return n
+}
+
+// Trigger emits a burst of BurstSize particles, eg. for an explosion or
+// impact.
+func (e *Emitter) Trigger() int { return e.Burst(e.BurstSize) }
+
+func (e *Emitter) init(p *Particle) {
+	ox, oy := e.Shape.Sample(e.Rand)
+	dir := e.Direction.Rand(e.Rand) * math2.D2R
+	speed := e.Speed.Rand(e.Rand)
+
+	p.X, p.Y = e.X+ox, e.Y+oy
+	p.VX, p.VY = math.Cos(dir)*speed, math.Sin(dir)*speed
+	p.Lifetime = e.Lifetime.Rand(e.Rand)
+	p.Spin = e.Spin.Rand(e.Rand)
+	p.startSize = e.Size.Rand(e.Rand)
+	p.startRotation = e.Rotation.Rand(e.Rand)
+	e.apply(p)
+}
+
+// apply updates the size, rotation and color of the Particle according to
+// the curves of the Emitter.
+func (e *Emitter) apply(p *Particle) {
+	t := p.Progress()
+	p.Size = p.startSize * e.SizeCurve.At(t, 1)
+	p.Rotation = p.startRotation + (p.Spin * p.Age) + e.RotationCurve.At(t, 0)
+	p.Color = e.Colors.At(t, colors.White)
+	p.Color.A = uint8(math2.Clamp(float64(p.Color.A)*e.AlphaCurve.At(t, 1), 0, 0xff))
+}
+
+// Update emits new particles when the Emitter is active, and moves and
+// updates all alive particles. Dead particles are returned to the Pool.
+func (e *Emitter) Update(dt float64) {
+	if e.active && e.Rate > 0 {
+		e.accum += e.Rate * dt
+		n := int(e.accum)
+		e.accum -= float64(n)
+		e.Burst(n)
+	}
+
+	for i := 0; i < e.pool.Len(); {
+		p := &e.pool.particles[i]
+		p.Age += dt
+		if !p.IsAlive() {
+			e.pool.Remove(i)
+			continue
+		}
+
+		p.X += p.VX * dt
+		p.Y += p.VY * dt
+		for _, a := range e.Affectors {
+			a.Affect(p, dt)
+		}
+		if !p.IsAlive() {
+			e.pool.Remove(i)
+			continue
+		}
+
+		e.apply(p)
+		i++
+	}
+}
+
+// Draw draws all alive particles, either using the Emitter's Texture or
+// Primitive.
+func (e *Emitter) Draw(canvas *sdlkit.Canvas) {
+	if e.pool.Len() == 0 {
+		return
+	}
+	if e.Texture.Texture != nil {
+		e.drawTexture(canvas)
+		return
+	}
+
+	mode := canvas.DrawBlendMode()
+	canvas.SetDrawBlendMode(e.BlendMode)
+
+	fill, filling := canvas.GetFill()
+	for _, p := range e.pool.Particles() {
+		canvas.BeginFillAlpha(p.Color, p.Color.A)
+		switch e.Primitive {
+		case DrawSquares:
+			canvas.DrawRectF(p.X-(p.Size/2), p.Y-(p.Size/2), p.Size, p.Size)
+		default:
+			canvas.DrawCircleF(p.X, p.Y, p.Size/2)
+		}
+	}
+
+	if filling {
+		canvas.BeginFill(fill)
+	} else {
+		canvas.EndFill()
+	}
+	canvas.SetDrawBlendMode(mode)
+}
+
+func (e *Emitter) drawTexture(canvas *sdlkit.Canvas) {
+	tx := e.Texture.Texture
+	alpha, err := tx.GetAlphaMod()
+	if err != nil {
+		canvas.CatchErr(err)
+		return
+	}
+	blend, err := tx.GetBlendMode()
+	if err != nil {
+		canvas.CatchErr(err)
+		return
+	}
+
+	canvas.CatchErr(tx.SetBlendMode(e.BlendMode))
+	w, h := e.Texture.Size()
+	for _, p := range e.pool.Particles() {
+		// scale the texture so its largest side matches the particle's size
+		s := p.Size / math.Max(w, h)
+		dw, dh := int32(w*s), int32(h*s)
+
+		canvas.CatchErr(tx.SetColorMod(p.Color.R, p.Color.G, p.Color.B))
+		canvas.CatchErr(tx.SetAlphaMod(p.Color.A))
+		canvas.DrawTextureClipEx(e.Texture,
+			sdl.Rect{X: int32(p.X) - dw/2, Y: int32(p.Y) - dh/2, W: dw, H: dh},
+			p.Rotation,
+			sdl.Point{X: dw / 2, Y: dh / 2},
+			sdl.FLIP_NONE,
+		)
+	}
+
+	canvas.CatchErr(
+		tx.SetColorMod(0xff, 0xff, 0xff),
+		tx.SetAlphaMod(alpha),
+		tx.SetBlendMode(blend),
+	)
+}
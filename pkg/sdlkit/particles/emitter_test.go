@@ -0,0 +1,129 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package particles
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom"
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/physics"
+)
+
+func newTestEmitter(capacity int) *Emitter {
+	e := NewEmitter(capacity)
+	e.Rand = rand.New(rand.NewSource(1))
+	return e
+}
+
+func TestPool(t *testing.T) {
+	pool := NewPool(2)
+	a, b := pool.Spawn(), pool.Spawn()
+	assert.NotNil(t, a)
+	assert.NotNil(t, b)
+	assert.Nil(t, pool.Spawn())
+
+	a.X, b.X = 1, 2
+	pool.Remove(0)
+	assert.Equal(t, 1, pool.Len())
+	assert.Equal(t, 2.0, pool.Particles()[0].X)
+
+	// spawned particles are reset
+	assert.Equal(t, 0.0, pool.Spawn().X)
+}
+
+func TestEmitter_Update(t *testing.T) {
+	e := newTestEmitter(100)
+	e.Rate = 10
+	e.Lifetime = Fixed(1)
+	e.Start()
+
+	e.Update(0.25)
+	assert.Equal(t, 2, e.Pool().Len())
+	e.Update(0.25)
+	assert.Equal(t, 5, e.Pool().Len())
+
+	e.Stop()
+	e.Update(0.6)
+	assert.Equal(t, 3, e.Pool().Len(), "the first particles have died")
+	e.Update(1)
+	assert.True(t, e.IsDone())
+}
+
+func TestEmitter_Burst(t *testing.T) {
+	e := newTestEmitter(5)
+	e.X, e.Y = 10, 20
+	e.Speed = Fixed(100)
+	e.Direction = Fixed(90)
+	e.SizeCurve = NewCurve(1, 0)
+	e.AlphaCurve = NewCurve(1, 0)
+
+	assert.Equal(t, 5, e.Burst(8))
+	assert.Equal(t, 0, e.Burst(1))
+
+	e.Update(0.5)
+	p := e.Particles()[0]
+	assert.InDelta(t, 10, p.X, 1e-9)
+	assert.InDelta(t, 70, p.Y, 1e-9)
+	assert.InDelta(t, 2, p.Size, 1e-9)
+	assert.Equal(t, uint8(0x7f), p.Color.A)
+}
+
+func TestAffectors(t *testing.T) {
+	p := &Particle{X: 0, Y: 0, VX: 10, VY: 0, Lifetime: 1}
+
+	Gravity{Y: 10}.Affect(p, 0.5)
+	assert.Equal(t, [2]float64{10, 5}, [2]float64{p.VX, p.VY})
+
+	Drag{Factor: 1}.Affect(p, 0.5)
+	assert.Equal(t, [2]float64{5, 2.5}, [2]float64{p.VX, p.VY})
+
+	p.VX, p.VY = 0, 0
+	Attractor{X: 10, Strength: 4}.Affect(p, 0.5)
+	assert.Equal(t, [2]float64{2, 0}, [2]float64{p.VX, p.VY})
+
+	Attractor{X: 10, Strength: 4, Radius: 5}.Affect(p, 0.5)
+	assert.Equal(t, [2]float64{2, 0}, [2]float64{p.VX, p.VY}, "out of range")
+}
+
+func TestCollide(t *testing.T) {
+	floor := physics.NewCollider(&geom.Rect{X: 0, Y: 105, W: 100, H: 10})
+	c := &Collide{Colliders: []physics.Collider{floor}, Bounce: 0.5}
+
+	// moved from y=98 into the floor
+	p := &Particle{X: 0, Y: 102, VX: 10, VY: 40, Lifetime: 1}
+	c.Affect(p, 0.1)
+	assert.InDelta(t, 98, p.Y, 1e-9)
+	assert.InDelta(t, 5, p.VX, 1e-9)
+	assert.InDelta(t, -20, p.VY, 1e-9)
+
+	c.Kill = true
+	p.Y = 102
+	c.Affect(p, 0.1)
+	assert.False(t, p.IsAlive())
+}
+
+func TestShapes(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		x, y := CircleShape{Radius: 10}.Sample(rnd)
+		assert.LessOrEqual(t, math.Hypot(x, y), 10.0)
+
+		x, y = CircleShape{Radius: 10, Edge: true}.Sample(rnd)
+		assert.InDelta(t, 10, math.Hypot(x, y), 1e-9)
+
+		x, y = RectShape{W: 20, H: 10}.Sample(rnd)
+		assert.True(t, math.Abs(x) <= 10 && math.Abs(y) <= 5)
+
+		x, y = RectShape{W: 20, H: 10, Edge: true}.Sample(rnd)
+		assert.True(t, math.Abs(x) == 10 || math.Abs(y) == 5, "%v,%v", x, y)
+
+		x, y = PolygonEdgeShape{Points: []geom.Point{{X: 0, Y: 0}, {X: 10, Y: 0}}}.Sample(rnd)
+		assert.True(t, y == 0 && x >= 0 && x <= 10)
+	}
+}
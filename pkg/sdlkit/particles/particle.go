@@ -0,0 +1,89 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package particles provides pooled particles, which are emitted by Emitters
+// and changed over their lifetime by curves and Affectors.
+package particles
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+type Particle struct {
+	X, Y   float64
+	VX, VY float64 // velocity in pixels per second
+
+	Rotation float64 // in degrees
+	Spin     float64 // in degrees per second
+	Size     float64
+	Color    sdl.Color
+
+	// Age and Lifetime are in seconds.
+	Age, Lifetime float64
+
+	startSize     float64
+	startRotation float64
+}
+
+// Progress returns the age of the Particle relative to its lifetime, from 0
+// to 1.
+func (p *Particle) Progress() float64 {
+	if p.Lifetime <= 0 {
+		return 1
+	}
+	return p.Age / p.Lifetime
+}
+
+func (p *Particle) IsAlive() bool { return p.Age < p.Lifetime }
+
+// Kill ends the lifetime of the Particle. It is removed on the next update.
+func (p *Particle) Kill() { p.Age = p.Lifetime }
+
+// Pool is a fixed size list of Particles. Alive particles are kept at the
+// start of the list so no allocations are needed when particles are spawned
+// or removed.
+type Pool struct {
+	particles []Particle
+	alive     int
+}
+
+func NewPool(capacity int) *Pool {
+	return &Pool{particles: make([]Particle, capacity)}
+}
+
+// Cap returns the maximum number of alive Particles.
+func (p *Pool) Cap() int { return len(p.particles) }
+
+// Len returns the number of alive Particles.
+func (p *Pool) Len() int { return p.alive }
+
+// Particles returns the alive Particles. The returned slice is only valid
+// until the next call to Spawn or Remove.
+func (p *Pool) Particles() []Particle { return p.particles[:p.alive] }
+
+// Spawn returns a reset Particle, or nil when the Pool is full.
+func (p *Pool) Spawn() *Particle {
+	if p.alive >= len(p.particles) {
+		return nil
+	}
+
+	pt := &p.particles[p.alive]
+	*pt = Particle{}
+	p.alive++
+	return pt
+}
+
+// Remove removes the alive Particle at index i. The last alive Particle takes
+// its place.
+func (p *Pool) Remove(i int) {
+	if i < 0 || i >= p.alive {
+		return
+	}
+
+	p.alive--
+	p.particles[i] = p.particles[p.alive]
+}
+
+// Clear removes all Particles.
+func (p *Pool) Clear() { p.alive = 0 }
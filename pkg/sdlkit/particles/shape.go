@@ -0,0 +1,95 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package particles
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom"
+)
+
+// Shape is the area particles are emitted from.
+type Shape interface {
+	// Sample returns a random position within the Shape, relative to the
+	// Emitter's position.
+	Sample(rnd *rand.Rand) (x, y float64)
+}
+
+// PointShape emits all particles from the Emitter's position.
+type PointShape struct{}
+
+func (PointShape) Sample(_ *rand.Rand) (float64, float64) { return 0, 0 }
+
+// CircleShape emits particles within a circle, or on its edge when Edge is
+// true.
+type CircleShape struct {
+	Radius float64
+	Edge   bool
+}
+
+func (s CircleShape) Sample(rnd *rand.Rand) (float64, float64) {
+	r := s.Radius
+	if !s.Edge {
+		// sqrt gives a uniform distribution over the circle's area
+		r *= math.Sqrt(rnd.Float64())
+	}
+
+	a := rnd.Float64() * 2 * math.Pi
+	return math.Cos(a) * r, math.Sin(a) * r
+}
+
+// RectShape emits particles within a rectangle which is centered on the
+// Emitter's position, or on its edge when Edge is true.
+type RectShape struct {
+	W, H float64
+	Edge bool
+}
+
+func (s RectShape) Sample(rnd *rand.Rand) (float64, float64) {
+	if !s.Edge {
+		return (rnd.Float64() - 0.5) * s.W, (rnd.Float64() - 0.5) * s.H
+	}
+
+	w, h := s.W/2, s.H/2
+	return PolygonEdgeShape{Points: []geom.Point{
+		{X: -w, Y: -h}, {X: w, Y: -h}, {X: w, Y: h}, {X: -w, Y: h},
+	}}.Sample(rnd)
+}
+
+// PolygonEdgeShape emits particles on the edges of a closed polygon. The
+// particles are evenly spread over the total length of the edges.
+type PolygonEdgeShape struct {
+	Points []geom.Point
+}
+
+func (s PolygonEdgeShape) Sample(rnd *rand.Rand) (float64, float64) {
+	n := len(s.Points)
+	switch n {
+	case 0:
+		return 0, 0
+	case 1:
+		return s.Points[0].X, s.Points[0].Y
+	}
+
+	var total float64
+	for i := 0; i < n; i++ {
+		total += edgeLength(s.Points[i], s.Points[(i+1)%n])
+	}
+
+	d := rnd.Float64() * total
+	for i := 0; i < n; i++ {
+		a, b := s.Points[i], s.Points[(i+1)%n]
+		l := edgeLength(a, b)
+		if d <= l && l > 0 {
+			t := d / l
+			return a.X + (b.X-a.X)*t, a.Y + (b.Y-a.Y)*t
+		}
+		d -= l
+	}
+	return s.Points[0].X, s.Points[0].Y
+}
+
+func edgeLength(a, b geom.Point) float64 { return math.Hypot(b.X-a.X, b.Y-a.Y) }
@@ -37,6 +37,7 @@ type SceneManager struct {
 	list     map[string]Scene
 	active   string
 	schedule string
+	onActive []func(scene Scene) error
 }
 
 func NewSceneManager() *SceneManager {
@@ -63,6 +64,12 @@ func (sm *SceneManager) Add(scene Scene) {
 	sm.list[scene.SceneName()] = scene
 }
 
+// OnActivate adds fn, which is called with the scene after it is successfully
+// activated.
+func (sm *SceneManager) OnActivate(fn func(scene Scene) error) {
+	sm.onActive = append(sm.onActive, fn)
+}
+
 func (sm *SceneManager) Activate(name string) (Scene, error) {
 	scene, exists := sm.list[name]
 	if !exists {
@@ -78,9 +85,15 @@ func (sm *SceneManager) Activate(name string) (Scene, error) {
 
 	sm.active = name
 	if a, ok := scene.(SceneActivater); ok {
-		errors.Append(&err, a.Activate())
+		if activateErr := a.Activate(); activateErr != nil {
+			errors.Append(&err, activateErr)
+			return scene, err
+		}
 	}
 
+	for _, fn := range sm.onActive {
+		errors.Append(&err, fn(scene))
+	}
 	return scene, err
 }
 
@@ -0,0 +1,35 @@
+package sdlkit
+
+import (
+	"testing"
+
+	"github.com/go-pogo/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type testScene struct {
+	Scene
+	name string
+	err  error
+}
+
+func (s *testScene) SceneName() string { return s.name }
+func (s *testScene) Activate() error   { return s.err }
+
+func TestSceneManager_OnActivate(t *testing.T) {
+	sm := NewSceneManager()
+	sm.Add(&testScene{name: "ok"})
+	sm.Add(&testScene{name: "fail", err: errors.New("failed")})
+
+	var activated []string
+	sm.OnActivate(func(scene Scene) error {
+		activated = append(activated, scene.SceneName())
+		return nil
+	})
+
+	_, err := sm.Activate("ok")
+	assert.NoError(t, err)
+	_, err = sm.Activate("fail")
+	assert.Error(t, err)
+	assert.Equal(t, []string{"ok"}, activated)
+}
@@ -64,6 +64,33 @@ func (s *SplashScreen) DisplayImage(data []byte) error {
 	return s.window.UpdateSurface()
 }
 
+// DisplayProgress draws a progress bar with color c and height h at the bottom
+// of the SplashScreen, eg. to show the progress of a PreloadQueue. Progress
+// is a value between 0 and 1.
+func (s *SplashScreen) DisplayProgress(progress float64, h int32, c sdl.Color) error {
+	win, err := s.window.GetSurface()
+	if err != nil {
+		return err
+	}
+
+	if progress < 0 {
+		progress = 0
+	} else if progress > 1 {
+		progress = 1
+	}
+
+	bar := sdl.Rect{
+		Y: win.H - h,
+		W: int32(float64(win.W) * progress),
+		H: h,
+	}
+	if err = win.FillRect(&bar, sdl.MapRGBA(win.Format, c.R, c.G, c.B, c.A)); err != nil {
+		return err
+	}
+
+	return s.window.UpdateSurface()
+}
+
 func (s *SplashScreen) Destroy() error {
 	return s.window.Destroy()
 }
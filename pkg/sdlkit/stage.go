@@ -7,6 +7,7 @@ package sdlkit
 import (
 	"context"
 	"image/color"
+	"math"
 
 	"github.com/go-pogo/errors"
 	sdlimg "github.com/veandco/go-sdl2/img"
@@ -68,11 +69,12 @@ type Stage struct {
 	ctx context.Context
 	cfn context.CancelFunc
 
-	initSize [2]int32
-	prevSize [2]int32
-	sizeRect sdl.Rect
-	size     [2]float64
-	fsMode   uint32
+	initSize   [2]int32
+	prevSize   [2]int32
+	windowSize [2]int32
+	sizeRect   sdl.Rect
+	size       [2]float64
+	fsMode     uint32
 
 	windowTitleFps *windowTitleFps
 }
@@ -193,6 +195,50 @@ func (s *Stage) Center() (float64, float64) {
 	return s.size[0] / 2, s.size[1] / 2
 }
 
+// logicalScale returns the scale and letterbox offset the renderer uses to
+// fit the logical size of the Stage within the window.
+func (s *Stage) logicalScale() (scale, offsetX, offsetY float64) {
+	return logicalScale(s.windowSize, s.size)
+}
+
+func logicalScale(window [2]int32, logical [2]float64) (scale, offsetX, offsetY float64) {
+	if logical[0] <= 0 || logical[1] <= 0 {
+		return 1, 0, 0
+	}
+
+	w, h := float64(window[0]), float64(window[1])
+	scale = math.Min(w/logical[0], h/logical[1])
+	return scale, (w - logical[0]*scale) / 2, (h - logical[1]*scale) / 2
+}
+
+// WindowToLogical converts window position x, y to a logical position within
+// the Stage. SDL already reports mouse events in logical positions, this is
+// needed for positions which come directly from the window, eg.
+// sdl.GetMouseState.
+func (s *Stage) WindowToLogical(x, y float64) (float64, float64) {
+	scale, ox, oy := s.logicalScale()
+	return (x - ox) / scale, (y - oy) / scale
+}
+
+// LogicalToWindow converts logical position x, y to a position within the
+// window.
+func (s *Stage) LogicalToWindow(x, y float64) (float64, float64) {
+	scale, ox, oy := s.logicalScale()
+	return x*scale + ox, y*scale + oy
+}
+
+// WindowToWorld converts window position x, y to a position in the world, as
+// seen by cam.
+func (s *Stage) WindowToWorld(cam *Camera, x, y float64) (float64, float64) {
+	return cam.LogicalToWorld(s.WindowToLogical(x, y))
+}
+
+// WorldToWindow converts world position x, y, as seen by cam, to a position
+// within the window.
+func (s *Stage) WorldToWindow(cam *Camera, x, y float64) (float64, float64) {
+	return s.LogicalToWindow(cam.WorldToLogical(x, y))
+}
+
 // Window returns the sdl.Window in which the Stage is set.
 func (s *Stage) Window() *sdl.Window { return s.window }
 
@@ -252,6 +298,7 @@ func (s *Stage) HandleWindowSizeChangedEvent(e *sdl.WindowEvent) error {
 }
 
 func (s *Stage) updateSize(w, h int32) error {
+	s.windowSize = [2]int32{w, h}
 	if w == s.initSize[0] && h == s.initSize[1] {
 		s.sizeRect.W = s.initSize[0]
 		s.sizeRect.H = s.initSize[1]
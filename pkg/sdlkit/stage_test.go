@@ -0,0 +1,25 @@
+package sdlkit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogicalScale(t *testing.T) {
+	tests := map[string]struct {
+		window  [2]int32
+		logical [2]float64
+		want    [3]float64
+	}{
+		"same":      {[2]int32{800, 600}, [2]float64{800, 600}, [3]float64{1, 0, 0}},
+		"scaled":    {[2]int32{1600, 1200}, [2]float64{800, 600}, [3]float64{2, 0, 0}},
+		"letterbox": {[2]int32{1000, 600}, [2]float64{800, 600}, [3]float64{1, 100, 0}},
+		"invalid":   {[2]int32{1000, 600}, [2]float64{0, 0}, [3]float64{1, 0, 0}},
+	}
+
+	for name, tc := range tests {
+		scale, x, y := logicalScale(tc.window, tc.logical)
+		assert.Equal(t, tc.want, [3]float64{scale, x, y}, name)
+	}
+}
@@ -6,27 +6,149 @@ package sdlkit
 
 import (
 	stderrors "errors"
+	"math"
+	"sort"
 
 	"github.com/go-pogo/errors"
 	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom"
 )
 
 //goland:noinspection GoErrorStringFormat
 var ErrInvalidTexture = stderrors.New("Invalid texture")
 
 type TextureClip struct {
-	Texture  *sdl.Texture
+	Texture *sdl.Texture
+	// Location is the area within Texture which contains the frame. When the
+	// frame is rotated, Location is rotated as well.
 	Location sdl.Rect
+
+	// Trim is the position of the trimmed frame within the original frame,
+	// and the size of the original frame. It is empty when the frame is not
+	// trimmed.
+	Trim sdl.Rect
+	// Rotation is the amount of degrees the frame is rotated clockwise within
+	// Texture. Texture packers rotate frames by either 90 or -90 degrees.
+	Rotation float64
+	// Pivot is the pivot point relative to the top left of the original
+	// frame, when HasPivot is true.
+	Pivot    geom.Point
+	HasPivot bool
+	// Insets are the nine-slice insets of the frame, when HasInsets is true.
+	Insets    Insets
+	HasInsets bool
+
+	// props are the custom properties of the location within its
+	// TextureAtlas. They are never modified once shared with a clip, which
+	// keeps TextureClip comparable.
+	props *clipProperties
 }
 
+// clipProperties are the custom properties of a location within a
+// TextureAtlas.
+type clipProperties map[string]string
+
+// Property returns the custom property with key of the location within the
+// TextureAtlas the clip is taken from.
+func (tc TextureClip) Property(key string) (string, bool) {
+	if tc.props == nil {
+		return "", false
+	}
+
+	v, ok := (*tc.props)[key]
+	return v, ok
+}
+
+// Size returns the size of the original frame.
 func (tc TextureClip) Size() (float64, float64) {
-	return float64(tc.Location.W), float64(tc.Location.H)
+	if tc.IsTrimmed() {
+		return float64(tc.Trim.W), float64(tc.Trim.H)
+	}
+
+	w, h := tc.frameSize()
+	return float64(w), float64(h)
+}
+
+// IsTrimmed indicates if the transparent edges of the frame are removed.
+func (tc TextureClip) IsTrimmed() bool { return tc.Trim.W != 0 && tc.Trim.H != 0 }
+
+// IsRotated indicates if the frame is rotated within Texture.
+func (tc TextureClip) IsRotated() bool { return tc.Rotation != 0 }
+
+// frameSize returns the size of the, possibly trimmed, frame without its
+// rotation.
+func (tc TextureClip) frameSize() (int32, int32) {
+	if tc.IsRotated() {
+		return tc.Location.H, tc.Location.W
+	}
+	return tc.Location.W, tc.Location.H
+}
+
+// transform returns the destination rect, rotation, origin and flip to draw
+// Location so it appears as the original frame drawn within dest, rotated by
+// deg around origin. The trimmed frame is placed at its offset within dest,
+// which is mirrored when the frame is flipped, and its own rotation within
+// Texture is undone.
+func (tc TextureClip) transform(dest sdl.Rect, deg float64, origin sdl.Point, flip sdl.RendererFlip) (sdl.Rect, float64, sdl.Point, sdl.RendererFlip) {
+	if !tc.IsTrimmed() && !tc.IsRotated() {
+		return dest, deg, origin, flip
+	}
+
+	fw, fh := tc.frameSize()
+	sw, sh := tc.Size()
+	sx, sy := float64(dest.W)/sw, float64(dest.H)/sh
+
+	ox, oy := float64(tc.Trim.X), float64(tc.Trim.Y)
+	if flip&sdl.FLIP_HORIZONTAL != 0 {
+		ox = sw - ox - float64(fw)
+	}
+	if flip&sdl.FLIP_VERTICAL != 0 {
+		oy = sh - oy - float64(fh)
+	}
+
+	w, h := float64(fw)*sx, float64(fh)*sy
+	// center of the trimmed frame, rotated around origin
+	cx := float64(dest.X) + ox*sx + w/2
+	cy := float64(dest.Y) + oy*sy + h/2
+	if deg != 0 {
+		px, py := float64(dest.X+origin.X), float64(dest.Y+origin.Y)
+		sin, cos := math.Sincos(deg * math.Pi / 180)
+		cx, cy = px+(cx-px)*cos-(cy-py)*sin, py+(cx-px)*sin+(cy-py)*cos
+	}
+
+	if tc.IsRotated() {
+		w, h = h, w
+		deg -= tc.Rotation
+		// flipping the frame horizontally flips its rotated texture
+		// vertically, and vice versa
+		flip = flip&^(sdl.FLIP_HORIZONTAL|sdl.FLIP_VERTICAL) |
+			(flip&sdl.FLIP_HORIZONTAL)<<1 | (flip&sdl.FLIP_VERTICAL)>>1
+	}
+
+	res := sdl.Rect{
+		X: int32(math.Round(cx - w/2)),
+		Y: int32(math.Round(cy - h/2)),
+		W: int32(math.Round(w)),
+		H: int32(math.Round(h)),
+	}
+	return res, deg, sdl.Point{X: res.W / 2, Y: res.H / 2}, flip
+}
+
+// Insets are the distances from each edge of a rectangle towards its center.
+type Insets struct {
+	Left, Top, Right, Bottom int32
 }
 
 type TextureAtlas struct {
 	texture   *sdl.Texture
 	locations []sdl.Rect
 	names     map[string]int
+	insets    map[int]Insets
+	frames    map[int]TextureClip
+	props     map[int]*clipProperties
+	ranges    []AnimationTag
+	grid      AtlasGrid
 	uniform   bool
 }
 
@@ -44,6 +166,43 @@ func NewTextureAtlas(tx *sdl.Texture, locations map[string]sdl.Rect) *TextureAtl
 	return ta
 }
 
+// setLocations replaces the locations of the named regions, while keeping their
+// indexes and the insets, frames, properties and ranges which are added to
+// them. New names are added, names which no longer exist are removed but
+// keep their index so the indexes of other regions do not shift.
+func (ta *TextureAtlas) setLocations(locations map[string]sdl.Rect) {
+	for name := range ta.names {
+		if _, ok := locations[name]; !ok {
+			delete(ta.names, name)
+		}
+	}
+
+	names := make([]string, 0, len(locations))
+	for name := range locations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		loc := locations[name]
+		i, ok := ta.names[name]
+		if !ok {
+			ta.add(name, loc)
+			continue
+		}
+
+		ta.locations[i] = loc
+		if clip, ok := ta.frames[i]; ok {
+			clip.Location = loc
+			ta.frames[i] = clip
+		}
+	}
+}
+
+// NewUniformTextureAtlas creates a TextureAtlas with total cells of
+// cellW*cellH, without margin or spacing. Cells which are cut off by the
+// right or bottom edge of the texture are included. Use NewGridTextureAtlas
+// for larger grids.
 func NewUniformTextureAtlas(tx *sdl.Texture, cellW, cellH int32, total uint8) (*TextureAtlas, error) {
 	if total < 1 {
 		return nil, errors.Newf("sdlkit: a TextureAtlas needs at least 1 cell")
@@ -54,27 +213,122 @@ func NewUniformTextureAtlas(tx *sdl.Texture, cellW, cellH int32, total uint8) (*
 		return nil, err
 	}
 
-	var x, y int32
-	ta := &TextureAtlas{
+	return NewGridTextureAtlas(tx, uniformGrid(cellW, cellH, total, txW, txH))
+}
+
+// uniformGrid returns the AtlasGrid of a uniform TextureAtlas within a texture
+// of txW*txH. Its Columns and Rows include the partial cells at the edges.
+func uniformGrid(cellW, cellH int32, total uint8, txW, txH int32) AtlasGrid {
+	grid := AtlasGrid{CellW: cellW, CellH: cellH, Total: int(total)}
+	if cellW > 0 && cellH > 0 {
+		grid.Columns = int((txW + cellW - 1) / cellW)
+		grid.Rows = int((txH + cellH - 1) / cellH)
+	}
+	return grid
+}
+
+// AtlasGrid describes a grid of uniform cells within a texture, eg. a sprite
+// sheet or tileset.
+type AtlasGrid struct {
+	CellW, CellH int32
+	// Margin is the space between the edges of the texture and the cells.
+	Margin int32
+	// Spacing is the space between the cells.
+	Spacing int32
+	// Columns and Rows are the size of the grid. When 0, they are calculated
+	// from the size of the texture, excluding the cells which are cut off by
+	// its right or bottom edge.
+	Columns, Rows int
+	// Total is the amount of cells, counted row by row. When 0, all cells of
+	// the grid are used.
+	Total int
+}
+
+// locations returns the locations of the cells of the grid within a texture
+// of txW*txH, and the grid with its Columns, Rows and Total resolved.
+func (g AtlasGrid) locations(txW, txH int32) ([]sdl.Rect, AtlasGrid, error) {
+	if g.CellW <= 0 || g.CellH <= 0 {
+		return nil, g, errors.Newf("sdlkit: invalid cell size %dx%d", g.CellW, g.CellH)
+	}
+	if g.Margin < 0 || g.Spacing < 0 || g.Columns < 0 || g.Rows < 0 || g.Total < 0 {
+		return nil, g, errors.Newf("sdlkit: AtlasGrid values cannot be negative")
+	}
+
+	if g.Columns == 0 {
+		g.Columns = int((txW - 2*g.Margin + g.Spacing) / (g.CellW + g.Spacing))
+	}
+	if g.Rows == 0 {
+		g.Rows = int((txH - 2*g.Margin + g.Spacing) / (g.CellH + g.Spacing))
+	}
+	if g.Columns < 1 || g.Rows < 1 {
+		return nil, g, errors.Newf("sdlkit: texture of %dx%d is smaller than a single cell", txW, txH)
+	}
+
+	if n := g.Columns * g.Rows; g.Total == 0 || g.Total > n {
+		g.Total = n
+	}
+
+	res := make([]sdl.Rect, g.Total)
+	for i := range res {
+		res[i] = sdl.Rect{
+			X: g.Margin + int32(i%g.Columns)*(g.CellW+g.Spacing),
+			Y: g.Margin + int32(i/g.Columns)*(g.CellH+g.Spacing),
+			W: g.CellW,
+			H: g.CellH,
+		}
+	}
+	return res, g, nil
+}
+
+// NewGridTextureAtlas creates a TextureAtlas with the cells of grid, which
+// are indexed row by row.
+func NewGridTextureAtlas(tx *sdl.Texture, grid AtlasGrid) (*TextureAtlas, error) {
+	_, _, txW, txH, err := tx.Query()
+	if err != nil {
+		return nil, err
+	}
+
+	locs, grid, err := grid.locations(txW, txH)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TextureAtlas{
 		texture:   tx,
-		locations: make([]sdl.Rect, 0, total),
+		locations: locs,
 		names:     make(map[string]int),
+		grid:      grid,
 		uniform:   true,
-	}
+	}, nil
+}
 
-Loop:
-	for y = 0; y < txH; y += cellH {
-		for x = 0; x < txW; x += cellW {
-			ta.locations = append(ta.locations, sdl.Rect{X: x, Y: y, W: cellW, H: cellH})
+// Add adds a new location to the TextureAtlas and returns its index. The
+// location is also registered under name when it is not empty.
+func (ta *TextureAtlas) Add(name string, loc sdl.Rect) int { return ta.add(name, loc) }
 
-			total--
-			if total <= 0 {
-				break Loop
-			}
-		}
+func (ta *TextureAtlas) add(name string, loc sdl.Rect) int {
+	i := len(ta.locations)
+	ta.locations = append(ta.locations, loc)
+	if name != "" {
+		ta.names[name] = i
 	}
+	return i
+}
+
+// AddClip adds the location of the TextureClip to the TextureAtlas, together
+// with its trim, rotation and pivot, and returns its index. The location is
+// also registered under name when it is not empty.
+func (ta *TextureAtlas) AddClip(name string, clip TextureClip) int {
+	i := ta.add(name, clip.Location)
+	if clip.IsTrimmed() || clip.IsRotated() || clip.HasPivot {
+		if ta.frames == nil {
+			ta.frames = make(map[int]TextureClip)
+		}
 
-	return ta, nil
+		clip.Texture = nil
+		ta.frames[i] = clip
+	}
+	return i
 }
 
 func (ta *TextureAtlas) Texture() *sdl.Texture { return ta.texture }
@@ -83,6 +337,31 @@ func (ta *TextureAtlas) Len() int { return len(ta.locations) }
 
 func (ta *TextureAtlas) IsUniform() bool { return ta.uniform }
 
+// Grid returns the resolved AtlasGrid of a uniform TextureAtlas.
+func (ta *TextureAtlas) Grid() (AtlasGrid, bool) { return ta.grid, ta.uniform }
+
+// CellIndex returns the index of the cell at col and row of a uniform
+// TextureAtlas.
+func (ta *TextureAtlas) CellIndex(col, row int) (int, bool) {
+	if !ta.uniform || col < 0 || row < 0 || col >= ta.grid.Columns || row >= ta.grid.Rows {
+		return 0, false
+	}
+
+	i := row*ta.grid.Columns + col
+	return i, ta.HasIndex(i)
+}
+
+// GetFromCell returns the TextureClip of the cell at col and row of a uniform
+// TextureAtlas.
+func (ta *TextureAtlas) GetFromCell(col, row int) (TextureClip, error) {
+	i, ok := ta.CellIndex(col, row)
+	if !ok {
+		return TextureClip{}, errors.Newf("sdlkit: unknown cell `%d,%d` in TextureAtlas", col, row)
+	}
+
+	return ta.GetFromIndex(i)
+}
+
 func (ta *TextureAtlas) Names() []string {
 	res := make([]string, 0, len(ta.names))
 	for n := range ta.names {
@@ -95,17 +374,29 @@ func (ta *TextureAtlas) HasIndex(i int) bool {
 	return i >= 0 && i < len(ta.locations)
 }
 
-func (ta *TextureAtlas) GetFomIndex(i int) (TextureClip, error) {
+// GetFromIndex returns the TextureClip of the location with index i,
+// including its metadata.
+func (ta *TextureAtlas) GetFromIndex(i int) (TextureClip, error) {
 	if !ta.HasIndex(i) {
 		return TextureClip{}, errors.Newf("sdlkit: unknown index `%d` in TextureAtlas", i)
 	}
 
-	return TextureClip{
-		Texture:  ta.texture,
-		Location: ta.locations[i],
-	}, nil
+	clip, ok := ta.frames[i]
+	if !ok {
+		clip.Location = ta.locations[i]
+	}
+
+	clip.Texture = ta.texture
+	clip.Insets, clip.HasInsets = ta.insets[i]
+	clip.props = ta.props[i]
+	return clip, nil
 }
 
+// GetFomIndex returns the TextureClip of the location with index i.
+//
+// Deprecated: use GetFromIndex instead.
+func (ta *TextureAtlas) GetFomIndex(i int) (TextureClip, error) { return ta.GetFromIndex(i) }
+
 func (ta *TextureAtlas) HasName(name string) bool {
 	_, ok := ta.names[name]
 	return ok
@@ -117,10 +408,178 @@ func (ta *TextureAtlas) GetFromName(name string) (TextureClip, error) {
 		return TextureClip{}, errors.Newf("sdlkit: unknown name `%s` in TextureAtlas", name)
 	}
 
-	return ta.GetFomIndex(i)
+	return ta.GetFromIndex(i)
+}
+
+// SetName registers the location with index i under name, eg. to name a cell
+// of a uniform TextureAtlas.
+func (ta *TextureAtlas) SetName(i int, name string) error {
+	if !ta.HasIndex(i) {
+		return errors.Newf("sdlkit: unknown index `%d` in TextureAtlas", i)
+	}
+
+	ta.names[name] = i
+	return nil
+}
+
+// SetPivot sets the pivot point of the location with name, relative to the
+// top left of its original frame.
+func (ta *TextureAtlas) SetPivot(name string, pivot geom.Point) error {
+	i, ok := ta.names[name]
+	if !ok {
+		return errors.Newf("sdlkit: unknown name `%s` in TextureAtlas", name)
+	}
+
+	clip, ok := ta.frames[i]
+	if !ok {
+		clip.Location = ta.locations[i]
+	}
+
+	if ta.frames == nil {
+		ta.frames = make(map[int]TextureClip)
+	}
+
+	clip.Pivot = pivot
+	clip.HasPivot = true
+	ta.frames[i] = clip
+	return nil
+}
+
+// SetInsets sets the Insets of the location with name, eg. to describe the
+// fixed corners and edges of a nine-slice.
+func (ta *TextureAtlas) SetInsets(name string, in Insets) error {
+	i, ok := ta.names[name]
+	if !ok {
+		return errors.Newf("sdlkit: unknown name `%s` in TextureAtlas", name)
+	}
+	if ta.insets == nil {
+		ta.insets = make(map[int]Insets)
+	}
+
+	ta.insets[i] = in
+	return nil
+}
+
+// GetInsets returns the Insets of the location with name, if any.
+func (ta *TextureAtlas) GetInsets(name string) (Insets, bool) {
+	i, ok := ta.names[name]
+	if !ok {
+		return Insets{}, false
+	}
+
+	in, ok := ta.insets[i]
+	return in, ok
+}
+
+// SetProperty sets a custom property of the location with name.
+func (ta *TextureAtlas) SetProperty(name, key, value string) error {
+	i, ok := ta.names[name]
+	if !ok {
+		return errors.Newf("sdlkit: unknown name `%s` in TextureAtlas", name)
+	}
+	if ta.props == nil {
+		ta.props = make(map[int]*clipProperties)
+	}
+
+	// copy the properties, so clips which are already returned keep theirs
+	props := make(clipProperties, 1)
+	if prev := ta.props[i]; prev != nil {
+		for k, v := range *prev {
+			props[k] = v
+		}
+	}
+
+	props[key] = value
+	ta.props[i] = &props
+	return nil
+}
+
+// GetProperty returns the custom property with key of the location with
+// name.
+func (ta *TextureAtlas) GetProperty(name, key string) (string, bool) {
+	i, ok := ta.names[name]
+	if !ok {
+		return "", false
+	}
+
+	return ta.GetPropertyFromIndex(i, key)
+}
+
+// GetPropertyFromIndex returns the custom property with key of the location
+// with index i.
+func (ta *TextureAtlas) GetPropertyFromIndex(i int, key string) (string, bool) {
+	props := ta.props[i]
+	if props == nil {
+		return "", false
+	}
+
+	v, ok := (*props)[key]
+	return v, ok
+}
+
+// AddRange adds a named range of locations, eg. the frames of an animation
+// within a uniform TextureAtlas.
+func (ta *TextureAtlas) AddRange(tag AnimationTag) error {
+	if !ta.HasIndex(tag.From) || !ta.HasIndex(tag.To) {
+		return errors.Newf("sdlkit: range `%s` from %d to %d is out of bounds", tag.Name, tag.From, tag.To)
+	}
+
+	for i, r := range ta.ranges {
+		if r.Name == tag.Name {
+			ta.ranges[i] = tag
+			return nil
+		}
+	}
+
+	ta.ranges = append(ta.ranges, tag)
+	return nil
+}
+
+// Range returns the range with name.
+func (ta *TextureAtlas) Range(name string) (AnimationTag, bool) {
+	for _, r := range ta.ranges {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return AnimationTag{}, false
+}
+
+// Ranges returns all ranges in the order they are added.
+func (ta *TextureAtlas) Ranges() []AnimationTag { return ta.ranges }
+
+// GetRange returns the TextureClips of the range with name, from its first
+// to its last location.
+func (ta *TextureAtlas) GetRange(name string) ([]TextureClip, error) {
+	r, ok := ta.Range(name)
+	if !ok {
+		return nil, errors.Newf("sdlkit: unknown range `%s` in TextureAtlas", name)
+	}
+
+	step := 1
+	if r.To < r.From {
+		step = -1
+	}
+
+	res := make([]TextureClip, 0, (r.To-r.From)*step+1)
+	for i := r.From; ; i += step {
+		clip, err := ta.GetFromIndex(i)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, clip)
+		if i == r.To {
+			break
+		}
+	}
+	return res, nil
 }
 
 func (ta *TextureAtlas) Destroy() error {
+	unwatchAsset(ta)
+	unwatchAsset(ta.texture)
+
 	err := ta.texture.Destroy()
 	if errors.Is(err, ErrInvalidTexture) {
 		err = nil // texture is probably already destroyed
@@ -137,6 +596,7 @@ func (t TexturesMap) Destroy(name string) error {
 	}
 
 	t[name] = nil
+	unwatchAsset(tx)
 	return errors.Trace(tx.Destroy())
 }
 
@@ -148,6 +608,7 @@ func (t TexturesMap) DestroyAll() error {
 			continue
 		}
 
+		unwatchAsset(tx)
 		errors.Append(&err, tx.Destroy())
 		t[n] = nil
 	}
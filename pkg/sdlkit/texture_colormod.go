@@ -0,0 +1,36 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sdlkit
+
+//#cgo windows LDFLAGS: -lSDL2
+//#cgo linux freebsd darwin openbsd pkg-config: sdl2
+//#if defined(_WIN32)
+//	#include <SDL2/SDL.h>
+//#else
+//	#include <SDL.h>
+//#endif
+import "C"
+import (
+	"unsafe"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// TextureColorMod returns the additional color value which is multiplied into
+// render copy operations of tx. It fills the gap of go-sdl2, which can set but
+// not get the color mod of a texture.
+// (https://wiki.libsdl.org/SDL_GetTextureColorMod)
+func TextureColorMod(tx *sdl.Texture) (r, g, b uint8, err error) {
+	ret := C.SDL_GetTextureColorMod(
+		(*C.SDL_Texture)(unsafe.Pointer(tx)),
+		(*C.Uint8)(unsafe.Pointer(&r)),
+		(*C.Uint8)(unsafe.Pointer(&g)),
+		(*C.Uint8)(unsafe.Pointer(&b)),
+	)
+	if ret != 0 {
+		return r, g, b, sdl.GetError()
+	}
+	return r, g, b, nil
+}
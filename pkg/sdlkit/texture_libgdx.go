@@ -0,0 +1,225 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sdlkit
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/go-pogo/errors"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// libGdxPage is a single page of a libGDX .atlas file.
+type libGdxPage struct {
+	File    string
+	Regions []libGdxRegion
+}
+
+type libGdxRegion struct {
+	Name string
+	// Index is the index of the region within an animation, or -1.
+	Index  int
+	Clip   TextureClip
+	Insets *Insets
+}
+
+// parseLibGdxAtlas parses both the legacy and the current format of a libGDX
+// .atlas file. Pages are separated by empty lines and start with the file name
+// of their image. A page's regions start with their name, followed by
+// key: value lines.
+func parseLibGdxAtlas(data []byte) ([]libGdxPage, error) {
+	var pages []libGdxPage
+	var page *libGdxPage
+	var region *libGdxRegion
+	var err error
+
+	finish := func(ln int) {
+		if region == nil {
+			return
+		}
+		if e := region.finish(); e != nil {
+			errors.Append(&err, errors.Wrapf(e, "sdlkit: invalid region `%s` before line %d", region.Name, ln))
+		}
+
+		page.Regions = append(page.Regions, *region)
+		region = nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	ln := 1
+	for ; scanner.Scan(); ln++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			finish(ln)
+			if page != nil {
+				pages = append(pages, *page)
+				page = nil
+			}
+			continue
+		}
+
+		key, val, isField := libGdxField(line)
+		switch {
+		case page == nil:
+			page = &libGdxPage{File: line}
+		case !isField:
+			finish(ln)
+			region = &libGdxRegion{Name: line, Index: -1}
+		case region != nil:
+			if e := region.set(key, val); e != nil {
+				errors.Append(&err, errors.Wrapf(e, "sdlkit: invalid value `%s` for %s on line %d", val, key, ln))
+			}
+		}
+		// page fields, eg. size, format and filter, are not used
+	}
+
+	finish(ln)
+	if page != nil {
+		pages = append(pages, *page)
+	}
+
+	errors.Append(&err, scanner.Err())
+	return pages, err
+}
+
+// libGdxField splits a key: value line.
+func libGdxField(line string) (string, string, bool) {
+	i := strings.IndexByte(line, ':')
+	if i < 0 {
+		return "", "", false
+	}
+	return strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:]), true
+}
+
+// libGdxInts parses a comma separated list of n integers.
+func libGdxInts(val string, n int) ([]int32, error) {
+	parts := strings.Split(val, ",")
+	if len(parts) != n {
+		return nil, errors.Newf("expected %d values", n)
+	}
+
+	res := make([]int32, n)
+	for i, p := range parts {
+		v, err := strconv.ParseInt(strings.TrimSpace(p), 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = int32(v)
+	}
+	return res, nil
+}
+
+// set sets the value of a region field. During parsing, Clip.Location
+// contains the unrotated size of the region, Clip.Trim the original size and
+// the offset from the bottom left, as used by libGDX.
+func (r *libGdxRegion) set(key, val string) error {
+	var n int
+	switch key {
+	case "xy", "size", "orig", "offset":
+		n = 2
+	case "bounds", "offsets", "split", "pad":
+		n = 4
+	case "rotate":
+		switch val {
+		case "true":
+			r.Clip.Rotation = -90
+		case "false":
+			r.Clip.Rotation = 0
+		default:
+			deg, err := strconv.Atoi(val)
+			if err != nil {
+				return err
+			}
+			if deg != 0 && deg != 90 {
+				return errors.Newf("only 0 and 90 degrees are supported")
+			}
+			r.Clip.Rotation = float64(-deg)
+		}
+		return nil
+	case "index":
+		i, err := strconv.Atoi(val)
+		r.Index = i
+		return err
+	default:
+		return nil // eg. custom values
+	}
+
+	v, err := libGdxInts(val, n)
+	if err != nil {
+		return err
+	}
+
+	loc, trim := &r.Clip.Location, &r.Clip.Trim
+	switch key {
+	case "xy":
+		loc.X, loc.Y = v[0], v[1]
+	case "size":
+		loc.W, loc.H = v[0], v[1]
+	case "bounds":
+		*loc = sdl.Rect{X: v[0], Y: v[1], W: v[2], H: v[3]}
+	case "orig":
+		trim.W, trim.H = v[0], v[1]
+	case "offset":
+		trim.X, trim.Y = v[0], v[1]
+	case "offsets":
+		*trim = sdl.Rect{X: v[0], Y: v[1], W: v[2], H: v[3]}
+	case "split":
+		r.Insets = &Insets{Left: v[0], Right: v[1], Top: v[2], Bottom: v[3]}
+	}
+	return nil
+}
+
+// finish converts the parsed values to a TextureClip. libGDX rotates regions
+// counter clockwise and measures offsets from the bottom of the original
+// size.
+func (r *libGdxRegion) finish() error {
+	loc, trim := &r.Clip.Location, &r.Clip.Trim
+	if loc.W <= 0 || loc.H <= 0 {
+		return errors.Newf("missing size")
+	}
+
+	if trim.W == 0 && trim.H == 0 || *trim == (sdl.Rect{W: loc.W, H: loc.H}) {
+		*trim = sdl.Rect{}
+	} else {
+		trim.Y = trim.H - trim.Y - loc.H
+	}
+	if r.Clip.IsRotated() {
+		loc.W, loc.H = loc.H, loc.W
+	}
+	return nil
+}
+
+// parseLibGdxAtlasPage parses the libGDX .atlas file and returns its only
+// page. Atlases with multiple pages are not supported.
+func parseLibGdxAtlasPage(file string, data []byte) (libGdxPage, error) {
+	pages, err := parseLibGdxAtlas(data)
+	if err != nil {
+		return libGdxPage{}, err
+	}
+	if len(pages) != 1 {
+		return libGdxPage{}, errors.Newf("sdlkit: expected a single page in `%s`, found %d", file, len(pages))
+	}
+	return pages[0], nil
+}
+
+// newLibGdxAtlas adds all regions of the page to the TextureAtlas. Regions
+// which are part of an animation are named after their index, eg. `walk_2`.
+func newLibGdxAtlas(page libGdxPage, atlas *TextureAtlas) *TextureAtlas {
+	for _, r := range page.Regions {
+		name := r.Name
+		if r.Index >= 0 {
+			name += "_" + strconv.Itoa(r.Index)
+		}
+
+		atlas.AddClip(name, r.Clip)
+		if r.Insets != nil {
+			_ = atlas.SetInsets(name, *r.Insets)
+		}
+	}
+	return atlas
+}
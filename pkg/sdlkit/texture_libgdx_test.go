@@ -0,0 +1,96 @@
+package sdlkit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+const libGdxLegacy = `
+sheet.png
+size: 64, 64
+format: RGBA8888
+filter: Nearest, Nearest
+repeat: none
+button
+  rotate: false
+  xy: 0, 0
+  size: 20, 10
+  split: 4, 4, 3, 3
+  orig: 20, 10
+  offset: 0, 0
+  index: -1
+walk
+  rotate: true
+  xy: 20, 0
+  size: 16, 24
+  orig: 20, 30
+  offset: 1, 2
+  index: 1
+`
+
+const libGdxCurrent = `sheet.png
+size:64,64
+filter:Nearest,Nearest
+button
+bounds:0,0,20,10
+split:4,4,3,3
+walk
+index:1
+bounds:20,0,16,24
+offsets:1,2,20,30
+rotate:90
+`
+
+func TestParseLibGdxAtlas(t *testing.T) {
+	for name, data := range map[string]string{"legacy": libGdxLegacy, "current": libGdxCurrent} {
+		t.Run(name, func(t *testing.T) {
+			pages, err := parseLibGdxAtlas([]byte(data))
+			assert.NoError(t, err)
+			assert.Len(t, pages, 1)
+			assert.Equal(t, "sheet.png", pages[0].File)
+
+			atlas := newLibGdxAtlas(pages[0], NewTextureAtlas(nil, nil))
+			assert.Equal(t, 2, atlas.Len())
+
+			clip, err := atlas.GetFromName("button")
+			assert.NoError(t, err)
+			assert.Equal(t, TextureClip{
+				Location:  sdl.Rect{W: 20, H: 10},
+				Insets:    Insets{Left: 4, Top: 3, Right: 4, Bottom: 3},
+				HasInsets: true,
+			}, clip)
+
+			in, ok := atlas.GetInsets("button")
+			assert.True(t, ok)
+			assert.Equal(t, Insets{Left: 4, Top: 3, Right: 4, Bottom: 3}, in)
+
+			clip, err = atlas.GetFromName("walk_1")
+			assert.NoError(t, err)
+			assert.Equal(t, TextureClip{
+				Location: sdl.Rect{X: 20, W: 24, H: 16},
+				// offset is measured from the bottom
+				Trim:     sdl.Rect{X: 1, Y: 4, W: 20, H: 30},
+				Rotation: -90,
+			}, clip)
+		})
+	}
+}
+
+func TestParseLibGdxAtlas_pages(t *testing.T) {
+	pages, err := parseLibGdxAtlas([]byte(libGdxCurrent + "\n" + libGdxCurrent))
+	assert.NoError(t, err)
+	assert.Len(t, pages, 2)
+
+	_, err = parseLibGdxAtlasPage("sheet.atlas", []byte(libGdxCurrent+"\n"+libGdxCurrent))
+	assert.Error(t, err)
+}
+
+func TestParseLibGdxAtlas_InvalidValue(t *testing.T) {
+	_, err := parseLibGdxAtlas([]byte("sheet.png\nfoo\nbounds:0,0,a,10\n"))
+	assert.Error(t, err)
+
+	_, err = parseLibGdxAtlas([]byte("sheet.png\nfoo\nrotate:45\nbounds:0,0,10,10\n"))
+	assert.Error(t, err)
+}
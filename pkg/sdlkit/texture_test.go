@@ -0,0 +1,250 @@
+package sdlkit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom"
+)
+
+func TestTextureAtlas_AddClip(t *testing.T) {
+	atlas := NewTextureAtlas(nil, nil)
+	atlas.AddClip("plain", TextureClip{Location: sdl.Rect{W: 10, H: 10}})
+	atlas.AddClip("trimmed", TextureClip{
+		Location: sdl.Rect{X: 10, W: 30, H: 20},
+		Trim:     sdl.Rect{X: 5, Y: 2, W: 40, H: 40},
+		Rotation: 90,
+		Pivot:    geom.Point{X: 20, Y: 40},
+		HasPivot: true,
+	})
+
+	clip, err := atlas.GetFromName("plain")
+	assert.NoError(t, err)
+	assert.False(t, clip.IsTrimmed())
+	assert.False(t, clip.IsRotated())
+
+	clip, err = atlas.GetFromName("trimmed")
+	assert.NoError(t, err)
+	assert.True(t, clip.IsTrimmed())
+	assert.True(t, clip.HasPivot)
+	assert.Equal(t, 90.0, clip.Rotation)
+
+	w, h := clip.Size()
+	assert.Equal(t, [2]float64{40, 40}, [2]float64{w, h})
+}
+
+func TestTextureClip_transform(t *testing.T) {
+	dest := sdl.Rect{W: 80, H: 80}
+	center := sdl.Point{X: 40, Y: 40}
+
+	type result struct {
+		dest   sdl.Rect
+		deg    float64
+		origin sdl.Point
+		flip   sdl.RendererFlip
+	}
+
+	trimmed := TextureClip{
+		Location: sdl.Rect{X: 10, Y: 10, W: 20, H: 30},
+		Trim:     sdl.Rect{X: 5, Y: 2, W: 40, H: 40},
+	}
+	rotated := trimmed
+	rotated.Location.W, rotated.Location.H = 30, 20
+	rotated.Rotation = 90
+
+	tests := map[string]struct {
+		clip TextureClip
+		deg  float64
+		flip sdl.RendererFlip
+		want result
+	}{
+		"plain": {
+			clip: TextureClip{Location: sdl.Rect{W: 40, H: 40}},
+			want: result{dest, 0, center, sdl.FLIP_NONE},
+		},
+		"trimmed": {
+			clip: trimmed,
+			want: result{sdl.Rect{X: 10, Y: 4, W: 40, H: 60}, 0, sdl.Point{X: 20, Y: 30}, sdl.FLIP_NONE},
+		},
+		"trimmed flipped": {
+			clip: trimmed,
+			flip: sdl.FLIP_HORIZONTAL,
+			want: result{sdl.Rect{X: 30, Y: 4, W: 40, H: 60}, 0, sdl.Point{X: 20, Y: 30}, sdl.FLIP_HORIZONTAL},
+		},
+		"trimmed rotated": {
+			clip: trimmed,
+			deg:  90,
+			want: result{sdl.Rect{X: 26, Y: 0, W: 40, H: 60}, 90, sdl.Point{X: 20, Y: 30}, sdl.FLIP_NONE},
+		},
+		"packed rotated": {
+			clip: rotated,
+			flip: sdl.FLIP_HORIZONTAL,
+			want: result{sdl.Rect{X: 20, Y: 14, W: 60, H: 40}, -90, sdl.Point{X: 30, Y: 20}, sdl.FLIP_VERTICAL},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var have result
+			have.dest, have.deg, have.origin, have.flip = tc.clip.transform(dest, tc.deg, center, tc.flip)
+			assert.Equal(t, tc.want, have)
+		})
+	}
+}
+
+func TestAtlasGrid_locations(t *testing.T) {
+	grid := AtlasGrid{CellW: 16, CellH: 16, Margin: 1, Spacing: 2}
+	locs, res, err := grid.locations(70, 36)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, res.Columns, "(70 - 2 + 2) / 18")
+	assert.Equal(t, 2, res.Rows)
+	assert.Equal(t, 6, res.Total)
+	assert.Len(t, locs, 6)
+	assert.Equal(t, sdl.Rect{X: 1, Y: 1, W: 16, H: 16}, locs[0])
+	assert.Equal(t, sdl.Rect{X: 37, Y: 19, W: 16, H: 16}, locs[5])
+
+	t.Run("more than 255 cells", func(t *testing.T) {
+		locs, res, err := AtlasGrid{CellW: 8, CellH: 8}.locations(256, 256)
+		assert.NoError(t, err)
+		assert.Equal(t, 1024, res.Total)
+		assert.Equal(t, sdl.Rect{X: 248, Y: 248, W: 8, H: 8}, locs[1023])
+	})
+	t.Run("total", func(t *testing.T) {
+		locs, _, err := AtlasGrid{CellW: 8, CellH: 8, Total: 3}.locations(64, 64)
+		assert.NoError(t, err)
+		assert.Len(t, locs, 3)
+	})
+	t.Run("too small", func(t *testing.T) {
+		_, _, err := AtlasGrid{CellW: 32, CellH: 32}.locations(16, 64)
+		assert.Error(t, err)
+	})
+	t.Run("uniform", func(t *testing.T) {
+		locs, res, err := uniformGrid(16, 16, 255, 40, 16).locations(40, 16)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, res.Columns, "includes the partial cell at the edge")
+		assert.Len(t, locs, 3)
+		assert.Equal(t, sdl.Rect{X: 32, W: 16, H: 16}, locs[2])
+	})
+}
+
+func newTestGridAtlas(t *testing.T, grid AtlasGrid, w, h int32) *TextureAtlas {
+	locs, grid, err := grid.locations(w, h)
+	assert.NoError(t, err)
+
+	return &TextureAtlas{
+		locations: locs,
+		names:     make(map[string]int),
+		grid:      grid,
+		uniform:   true,
+	}
+}
+
+func TestTextureAtlas_GetFromCell(t *testing.T) {
+	atlas := newTestGridAtlas(t, AtlasGrid{CellW: 10, CellH: 10, Total: 7}, 40, 20)
+
+	i, ok := atlas.CellIndex(2, 1)
+	assert.True(t, ok)
+	assert.Equal(t, 6, i)
+
+	_, ok = atlas.CellIndex(3, 1)
+	assert.False(t, ok, "beyond total")
+	_, ok = atlas.CellIndex(4, 0)
+	assert.False(t, ok)
+
+	clip, err := atlas.GetFromCell(1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, sdl.Rect{X: 10, Y: 10, W: 10, H: 10}, clip.Location)
+}
+
+func TestTextureAtlas_AddRange(t *testing.T) {
+	atlas := newTestGridAtlas(t, AtlasGrid{CellW: 10, CellH: 10}, 40, 20)
+	assert.NoError(t, atlas.AddRange(AnimationTag{Name: "walk", From: 4, To: 7}))
+	assert.NoError(t, atlas.AddRange(AnimationTag{Name: "back", From: 3, To: 1}))
+	assert.Error(t, atlas.AddRange(AnimationTag{Name: "jump", From: 6, To: 8}))
+
+	clips, err := atlas.GetRange("walk")
+	assert.NoError(t, err)
+	assert.Len(t, clips, 4)
+	assert.Equal(t, sdl.Rect{X: 0, Y: 10, W: 10, H: 10}, clips[0].Location)
+
+	clips, err = atlas.GetRange("back")
+	assert.NoError(t, err)
+	assert.Len(t, clips, 3)
+	assert.Equal(t, int32(30), clips[0].Location.X)
+
+	_, err = atlas.GetRange("jump")
+	assert.Error(t, err)
+	assert.Len(t, atlas.Ranges(), 2)
+}
+
+func TestTextureAtlas_metadata(t *testing.T) {
+	atlas := newTestGridAtlas(t, AtlasGrid{CellW: 10, CellH: 10}, 20, 10)
+	assert.NoError(t, atlas.SetName(1, "button"))
+	assert.Error(t, atlas.SetName(2, "missing"))
+
+	assert.NoError(t, atlas.SetPivot("button", geom.Point{X: 5, Y: 10}))
+	assert.NoError(t, atlas.SetInsets("button", Insets{Left: 2, Top: 2, Right: 2, Bottom: 2}))
+	assert.NoError(t, atlas.SetProperty("button", "sound", "click.wav"))
+
+	clip, err := atlas.GetFromName("button")
+	assert.NoError(t, err)
+	assert.Equal(t, sdl.Rect{X: 10, W: 10, H: 10}, clip.Location)
+	assert.True(t, clip.HasPivot)
+	assert.Equal(t, geom.Point{X: 5, Y: 10}, clip.Pivot)
+	assert.True(t, clip.HasInsets)
+	assert.Equal(t, int32(2), clip.Insets.Left)
+	// TextureClips are comparable, eg. to be used as map key
+	assert.True(t, map[TextureClip]bool{clip: true}[clip])
+
+	v, ok := atlas.GetProperty("button", "sound")
+	assert.True(t, ok)
+	assert.Equal(t, "click.wav", v)
+	v, ok = clip.Property("sound")
+	assert.True(t, ok)
+	assert.Equal(t, "click.wav", v)
+
+	// properties set later do not change clips which are already returned
+	assert.NoError(t, atlas.SetProperty("button", "sound", "tap.wav"))
+	v, _ = clip.Property("sound")
+	assert.Equal(t, "click.wav", v)
+
+	clip, err = atlas.GetFromIndex(0)
+	assert.NoError(t, err)
+	assert.False(t, clip.HasPivot)
+	assert.False(t, clip.HasInsets)
+
+	_, ok = atlas.GetPropertyFromIndex(0, "sound")
+	assert.False(t, ok)
+	_, ok = clip.Property("sound")
+	assert.False(t, ok)
+}
+
+func TestTextureAtlas_setLocations(t *testing.T) {
+	atlas := NewTextureAtlas(nil, map[string]sdl.Rect{
+		"keep":   {W: 10, H: 10},
+		"remove": {X: 10, W: 10, H: 10},
+	})
+	assert.NoError(t, atlas.SetPivot("keep", geom.Point{X: 5, Y: 5}))
+	assert.NoError(t, atlas.SetInsets("keep", Insets{Left: 2}))
+	index := atlas.names["keep"]
+
+	atlas.setLocations(map[string]sdl.Rect{
+		"keep":  {Y: 10, W: 20, H: 20},
+		"added": {X: 20, W: 10, H: 10},
+	})
+	assert.False(t, atlas.HasName("remove"))
+	assert.Equal(t, 3, atlas.Len())
+	assert.Equal(t, index, atlas.names["keep"])
+
+	clip, err := atlas.GetFromName("keep")
+	assert.NoError(t, err)
+	assert.Equal(t, sdl.Rect{Y: 10, W: 20, H: 20}, clip.Location)
+	assert.True(t, clip.HasPivot)
+	assert.True(t, clip.HasInsets)
+
+	clip, err = atlas.GetFromName("added")
+	assert.NoError(t, err)
+	assert.Equal(t, sdl.Rect{X: 20, W: 10, H: 10}, clip.Location)
+}
@@ -29,3 +29,17 @@ func ShrinkRect(rect sdl.Rect, amount int32) sdl.Rect {
 		H: rect.H - amount - amount,
 	}
 }
+
+func minInt32(a, b int32) int32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt32(a, b int32) int32 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sdlkit
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom/align"
+)
+
+// SplitLayout determines how SplitScreen divides an area into viewports.
+type SplitLayout uint8
+
+const (
+	// SplitNone results in a single viewport which fills the whole area.
+	SplitNone SplitLayout = iota
+	// SplitHorizontal divides the area with a horizontal line, into a top and
+	// bottom viewport.
+	SplitHorizontal
+	// SplitVertical divides the area with a vertical line, into a left and
+	// right viewport.
+	SplitVertical
+	// SplitQuad divides the area into four viewports, ordered top left, top
+	// right, bottom left and bottom right.
+	SplitQuad
+)
+
+// SplitScreen divides area into viewports according to layout, with gap
+// pixels between them.
+func SplitScreen(layout SplitLayout, area sdl.Rect, gap int32) []sdl.Rect {
+	var cols, rows int32 = 1, 1
+	switch layout {
+	case SplitHorizontal:
+		rows = 2
+	case SplitVertical:
+		cols = 2
+	case SplitQuad:
+		cols, rows = 2, 2
+	}
+
+	res := make([]sdl.Rect, 0, cols*rows)
+	for row := int32(0); row < rows; row++ {
+		// calculate both edges so the last viewport ends exactly at the
+		// edge of area
+		y0 := area.Y + row*(area.H+gap)/rows
+		y1 := area.Y + (row+1)*(area.H+gap)/rows - gap
+		for col := int32(0); col < cols; col++ {
+			x0 := area.X + col*(area.W+gap)/cols
+			x1 := area.X + (col+1)*(area.W+gap)/cols - gap
+			res = append(res, sdl.Rect{X: x0, Y: y0, W: x1 - x0, H: y1 - y0})
+		}
+	}
+	return res
+}
+
+// PictureInPicture returns a viewport of w x h pixels, which is aligned
+// within area with margin pixels from its edges. It is typically used for a
+// minimap Camera.
+func PictureInPicture(area sdl.Rect, to align.Alignment, w, h, margin int32) sdl.Rect {
+	var x, y float64
+	align.Values(to, &x, &y,
+		float64(area.X+margin),
+		float64(area.Y+margin),
+		float64(area.W-w-margin-margin),
+		float64(area.H-h-margin-margin),
+	)
+
+	return sdl.Rect{X: int32(x), Y: int32(y), W: w, H: h}
+}
@@ -0,0 +1,61 @@
+package sdlkit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/veandco/go-sdl2/sdl"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom/align"
+)
+
+func TestSplitScreen(t *testing.T) {
+	area := sdl.Rect{W: 100, H: 50}
+	tests := map[SplitLayout][]sdl.Rect{
+		SplitNone: {area},
+		SplitHorizontal: {
+			{X: 0, Y: 0, W: 100, H: 23},
+			{X: 0, Y: 27, W: 100, H: 23},
+		},
+		SplitVertical: {
+			{X: 0, Y: 0, W: 48, H: 50},
+			{X: 52, Y: 0, W: 48, H: 50},
+		},
+		SplitQuad: {
+			{X: 0, Y: 0, W: 48, H: 23},
+			{X: 52, Y: 0, W: 48, H: 23},
+			{X: 0, Y: 27, W: 48, H: 23},
+			{X: 52, Y: 27, W: 48, H: 23},
+		},
+	}
+
+	for layout, want := range tests {
+		assert.Equal(t, want, SplitScreen(layout, area, 4), "layout %d", layout)
+	}
+
+	// viewports without gap fill the area completely
+	assert.Equal(t,
+		[]sdl.Rect{{X: 10, Y: 0, W: 50, H: 50}, {X: 60, Y: 0, W: 51, H: 50}},
+		SplitScreen(SplitVertical, sdl.Rect{X: 10, W: 101, H: 50}, 0),
+	)
+}
+
+func TestPictureInPicture(t *testing.T) {
+	area := sdl.Rect{W: 100, H: 50}
+	assert.Equal(t, sdl.Rect{X: 75, Y: 35, W: 20, H: 10}, PictureInPicture(area, align.ToBottomRight, 20, 10, 5))
+	assert.Equal(t, sdl.Rect{X: 5, Y: 5, W: 20, H: 10}, PictureInPicture(area, align.ToTopLeft, 20, 10, 5))
+}
+
+func TestCamera_SetViewport(t *testing.T) {
+	cam := NewCamera(0, 0, 200, 100)
+	cam.SetViewport(sdl.Rect{X: 100, Y: 0, W: 100, H: 100})
+
+	assert.Equal(t, sdl.Rect{X: 100, Y: 0, W: 100, H: 100}, cam.Viewport())
+	assert.Equal(t, int32(100), cam.Width())
+
+	// the center of the view is kept, screen positions are relative to the
+	// viewport
+	assert.Equal(t, 50.0, cam.GetX())
+	x, y := cam.WorldToViewport(100, 50)
+	assert.Equal(t, [2]float64{50, 50}, [2]float64{x, y})
+}
@@ -5,13 +5,20 @@
 package main
 
 import (
+	"embed"
+	"log"
+
 	"github.com/veandco/go-sdl2/sdl"
 
 	"github.com/roeldev/go-sdl2-experiments/internal"
 	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/audio"
 	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/colors"
 )
 
+//go:embed "assets"
+var assets embed.FS
+
 const Debug = false
 
 var rng = sdlkit.RNG()
@@ -20,12 +27,36 @@ func main() {
 	sdlkit.FailOnErr(sdl.Init(sdl.INIT_VIDEO))
 	defer sdl.Quit()
 
+	// audio is optional, the game is played without sound when there is no
+	// audio device
+	mixer, bounce, err := openAudio()
+	if err != nil {
+		log.Printf("pong: playing without sound: %+v", err)
+	} else {
+		defer mixer.Close()
+		defer bounce.Destroy()
+	}
+
 	sdlkit.DefaultOptions.WindowFlags += sdl.WINDOW_RESIZABLE
 	sdlkit.DefaultOptions.BgColor = colors.RgbaColor(colors.DarkSlateGray)
 
 	stage := sdlkit.MustNewStage(internal.ExampleName(), 1024, 576, sdlkit.DefaultOptions)
 	defer stage.Destroy()
 
-	sdlkit.FailOnErr(stage.AddScene(newGame(stage)))
+	sdlkit.FailOnErr(stage.AddScene(newGame(stage, mixer, bounce)))
 	sdlkit.FailOnErr(sdlkit.RunLoop(stage))
 }
+
+func openAudio() (*audio.Mixer, *audio.Sound, error) {
+	mixer, err := audio.Open(0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bounce, err := audio.LoadSound(sdlkit.NewAssetsLoader(assets, nil), "assets/bounce.wav")
+	if err != nil {
+		mixer.Close()
+		return nil, nil, err
+	}
+	return mixer, bounce, nil
+}
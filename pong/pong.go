@@ -8,6 +8,7 @@ import (
 	"github.com/veandco/go-sdl2/sdl"
 
 	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/audio"
 	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/display"
 	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/event"
 	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom"
@@ -16,6 +17,8 @@ import (
 type pongGame struct {
 	stage  *sdlkit.Stage
 	events event.Manager
+	mixer  *audio.Mixer
+	bounce *audio.Sound
 
 	bgLayer      sdlkit.Layer
 	paddlesLayer sdlkit.Layer
@@ -29,9 +32,11 @@ type pongGame struct {
 	paddleRight *paddle
 }
 
-func newGame(stage *sdlkit.Stage) *pongGame {
+func newGame(stage *sdlkit.Stage, mixer *audio.Mixer, bounce *audio.Sound) *pongGame {
 	game := &pongGame{
 		stage:      stage,
+		mixer:      mixer,
+		bounce:     bounce,
 		ball:       newBall(stage, DefaultBallRadius),
 		paddleLeft: newPaddle(paddleLeft, 0, 0), // computer player
 		// paddleLeft:  newPaddle(paddleLeft, sdl.SCANCODE_Q, sdl.SCANCODE_A),
@@ -110,15 +115,28 @@ func (game *pongGame) Update(dt float64) {
 	if geom.InRect(bl, game.ball.Y, bounds.X, bounds.Y, bounds.W, bounds.H) {
 		game.ball.X += bounds.X + bounds.W - bl
 		game.ball.Vel.X *= -1
+		game.playBounce()
 	} else {
 		bounds = game.paddleRight.Bounds()
 		if geom.InRect(br, game.ball.Y, bounds.X, bounds.Y, bounds.W, bounds.H) {
 			game.ball.X += bounds.X - br
 			game.ball.Vel.X *= -1
+			game.playBounce()
 		}
 	}
 }
 
+// playBounce plays the bounce sound from the side of the screen the ball is
+// on. Nothing is played when there is no audio.
+func (game *pongGame) playBounce() {
+	if game.mixer == nil {
+		return
+	}
+
+	pan := game.ball.X/game.stage.FWidth()*2 - 1
+	_, _ = game.mixer.PlayEx(game.bounce, game.bounce.Volume, pan, 0)
+}
+
 func (game *pongGame) Render(r *sdl.Renderer) error {
 	return sdlkit.Render(r,
 		game.bgLayer,
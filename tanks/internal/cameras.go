@@ -0,0 +1,71 @@
+// Copyright (c) 2021, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"math"
+
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom/align"
+	"github.com/roeldev/go-sdl2-experiments/tanks/internal/tank"
+)
+
+const (
+	minZoom = 0.5
+	maxZoom = 2
+
+	viewportGap = 4
+)
+
+// worldBounds returns the area of the map.
+func (game *tanksGame) worldBounds() (x, y, w, h float64) {
+	mapW, mapH := game.world.Size()
+	tileW, tileH := game.world.TileSize()
+	return game.world.X, game.world.Y, float64(mapW * tileW), float64(mapH * tileH)
+}
+
+// addCamera adds a camera which follows player.
+func (game *tanksGame) addCamera(player *tank.Tank) {
+	cam := sdlkit.NewCamera(0, 0, game.screen.W, game.screen.H)
+	cam.Smoothing = sdlkit.SmoothDamped
+	cam.SmoothTime = 0.3
+	cam.LookAhead = 0.6
+	cam.MaxLookAhead = 120
+	cam.SetDeadzone(80, 60)
+	cam.SetBounds(game.worldBounds())
+	cam.Follow(player)
+
+	game.cameras = append(game.cameras, cam)
+}
+
+// layoutViewports splits the screen between the players' cameras and places
+// the minimap in the bottom right corner.
+func (game *tanksGame) layoutViewports() {
+	layout := sdlkit.SplitNone
+	switch n := len(game.cameras); {
+	case n == 2:
+		layout = sdlkit.SplitVertical
+	case n > 2:
+		layout = sdlkit.SplitQuad
+	}
+
+	viewports := sdlkit.SplitScreen(layout, game.screen, viewportGap)
+	for i, cam := range game.cameras {
+		if i < len(viewports) {
+			cam.SetViewport(viewports[i])
+		}
+	}
+
+	game.minimap.SetViewport(sdlkit.PictureInPicture(game.screen, align.ToBottomRight,
+		game.screen.W/5,
+		game.screen.H/5,
+		10,
+	))
+
+	// zoom out until the whole map fits in the minimap
+	_, _, w, h := game.worldBounds()
+	vp := game.minimap.Viewport()
+	game.minimap.SetZoom(math.Min(float64(vp.W)/w, float64(vp.H)/h))
+}
@@ -6,14 +6,18 @@ package internal
 
 import (
 	"io/fs"
+	"math"
 
 	"github.com/veandco/go-sdl2/sdl"
 
 	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/colors"
 	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/display"
 	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/ecs"
 	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/event"
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom"
 	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/input"
+	math2 "github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/math"
 	"github.com/roeldev/go-sdl2-experiments/tanks/internal/tank"
 )
 
@@ -25,13 +29,17 @@ const (
 type tanksGame struct {
 	event.Manager
 
-	stage  *sdlkit.Stage
-	camera *sdlkit.Camera
-	mouse  *input.MouseState
+	stage   *sdlkit.Stage
+	screen  sdl.Rect
+	cameras []*sdlkit.Camera // one for each player
+	minimap *sdlkit.Camera
+	mouse   *input.MouseState
 
 	assets  fs.ReadFileFS
+	load    *sdlkit.AssetsLoader
 	ground  *display.Tile
 	world   *display.TileMap
+	spawns  []*display.MapObject
 	objects *sdlkit.TextureAtlas
 	players []*tank.Tank
 	ecs     *ecs.Manager
@@ -39,27 +47,40 @@ type tanksGame struct {
 
 func NewGame(stage *sdlkit.Stage, assets fs.ReadFileFS) (sdlkit.Scene, error) {
 	load := sdlkit.NewAssetsLoader(assets, stage.Renderer())
-	objects, err := load.TextureAtlasXml("assets/onlyObjects_default.xml")
+	manifest, err := load.Manifest("assets/manifest.yaml")
 	if err != nil {
 		return nil, err
 	}
 
-	terrain, err := load.UniformTextureAtlas("assets/terrainTiles_retina.png", 128, 128, 40)
+	bundle, err := load.LoadBundle("game")
 	if err != nil {
 		return nil, err
 	}
 
-	world, err := display.NewTileMap(terrain)
+	objects, err := bundle.TextureAtlas("objects")
+	if err != nil {
+		return nil, err
+	}
+
+	terrain, err := bundle.TextureAtlas("terrain")
+	if err != nil {
+		return nil, err
+	}
+
+	// the tileset's texture is the same texture as the terrain atlas uses
+	world, err := display.LoadTiledMapBundle(load, bundle, manifest.Files["map"])
 	if err != nil {
 		return nil, err
 	}
 
 	game := &tanksGame{
 		stage:   stage,
-		camera:  sdlkit.NewCamera(0, 0, stage.Width(), stage.Height()),
+		screen:  stage.Size(),
+		minimap: sdlkit.NewCamera(0, 0, stage.Width(), stage.Height()),
 		mouse:   input.NewMouseState(input.TrackMouseBtnLeft),
 		assets:  assets,
-		ground:  display.MustNewTile(terrain.GetFomIndex(20)),
+		load:    load,
+		ground:  display.MustNewTile(terrain.GetFromIndex(20)),
 		world:   world,
 		objects: objects,
 		ecs:     ecs.NewManager(),
@@ -70,7 +91,14 @@ func NewGame(stage *sdlkit.Stage, assets fs.ReadFileFS) (sdlkit.Scene, error) {
 	game.ground.X = 5005
 	game.ground.Y = 1505
 
-	stage.Canvas().SetCamera(game.camera)
+	if objects := world.ObjectLayer("objects"); objects != nil {
+		game.spawns = objects.ObjectsOfType("spawn")
+		for _, tree := range objects.ObjectsOfType("tree") {
+			game.addTree(tree.X+(tree.W/2), tree.Y+(tree.H/2))
+		}
+	}
+
+	game.minimap.SetBounds(game.worldBounds())
 	game.MustRegisterHandler(
 		stage,
 		game,
@@ -95,11 +123,42 @@ func (game *tanksGame) Activate() (err error) {
 		// demo.players[1].SetHeading(math.Pi)
 	}
 
+	game.layoutViewports()
+	return nil
+}
+
+// HandleKeyDownEvent rotates the camera with Q and E, shakes it with X and
+// resets its zoom and rotation with R.
+func (game *tanksGame) HandleKeyDownEvent(e *sdl.KeyboardEvent) error {
+	for _, cam := range game.cameras {
+		switch e.Keysym.Sym {
+		case sdl.K_q:
+			cam.SetRotation(cam.Rotation() - 5)
+		case sdl.K_e:
+			cam.SetRotation(cam.Rotation() + 5)
+		case sdl.K_x:
+			cam.AddTrauma(0.5)
+		case sdl.K_r:
+			cam.SetRotation(0)
+			cam.SetZoom(1)
+		}
+	}
+	return nil
+}
+
+// HandleMouseWheelEvent zooms the players' cameras in and out.
+func (game *tanksGame) HandleMouseWheelEvent(e *sdl.MouseWheelEvent) error {
+	for _, cam := range game.cameras {
+		zoom := cam.Zoom() * math.Pow(1.1, float64(e.Y))
+		cam.SetZoom(math2.Clamp(zoom, minZoom, maxZoom))
+	}
 	return nil
 }
 
-func (game *tanksGame) HandleWindowSizeChangedEvent(e *sdl.WindowEvent) error {
-	game.camera.Resize(e.Data1, e.Data2)
+func (game *tanksGame) HandleWindowSizeChangedEvent(_ *sdl.WindowEvent) error {
+	// the stage handles the event first and updates its logical size
+	game.screen = game.stage.Size()
+	game.layoutViewports()
 	return nil
 }
 
@@ -109,26 +168,44 @@ func (game *tanksGame) HandleRenderEvent(_ *sdl.RenderEvent) error {
 }
 
 func (game *tanksGame) Update(dt float64) {
-	// demo.player1.SetTurretRotation(demo.player1.TurretPosition().SubXY(demo.mouse).Angle()+math.Pi)
+	if len(game.cameras) != 0 {
+		// aim the first player's turret at the mouse
+		var mouse geom.Point
+		mouse.X, mouse.Y = game.mouse.WorldPos(game.cameras[0])
+		game.players[0].SetTurretRotation(game.players[0].TurretPosition().SubXY(mouse).Angle() + math.Pi)
+	}
 
 	for _, tc := range game.ecs.Components(tankComponent) {
 		tc.(*tank.Tank).Update(dt)
 	}
+
+	for _, cam := range game.cameras {
+		cam.Update(dt)
+	}
 }
 
 func (game *tanksGame) Render(_ *sdl.Renderer) error {
 	canvas := game.stage.Canvas()
-	game.camera.Follow(game.players[0])
+	canvas.DrawCameras(sdlkit.DrawableFunc(game.draw), game.cameras...)
+	canvas.DrawCameras(sdlkit.DrawableFunc(game.draw), game.minimap)
+
+	canvas.BeginLineStyle(2, colors.Black)
+	canvas.DrawSdlRect(game.minimap.Viewport())
+	canvas.EndLineStyle()
+
+	return canvas.Done()
+}
+
+// draw draws the world, it is called once for each camera.
+func (game *tanksGame) draw(canvas *sdlkit.Canvas) {
 	game.ground.Draw(canvas)
-	// errors.Append(&err, game.world.Draw(ren))
+	game.world.Draw(canvas)
 
 	for _, tc := range game.ecs.Components(tankComponent) {
 		tc.(*tank.Tank).Draw(canvas)
 	}
-
-	return canvas.Done()
 }
 
 func (game *tanksGame) Destroy() error {
-	return game.objects.Destroy()
+	return game.load.UnloadBundle("game")
 }
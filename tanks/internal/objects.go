@@ -9,10 +9,16 @@ import (
 
 func (game *tanksGame) addPlayer(color tank.Color, control tank.Control) *tank.Tank {
 	player := tank.NewPlayer(game.objects, color, control)
-	align.XYInSdlRect(align.ToCenter, player, game.stage.Size())
+	if i := len(game.players); i < len(game.spawns) {
+		player.SetX(game.spawns[i].X)
+		player.SetY(game.spawns[i].Y)
+	} else {
+		align.XYInSdlRect(align.ToCenter, player, game.stage.Size())
+	}
 
 	game.RegisterHandler(player, game.stage.Size())
 	game.players = append(game.players, player)
+	game.addCamera(player)
 
 	entity := game.ecs.Create(nil)
 	entity.AddComponent(tankComponent, player)
@@ -9,6 +9,7 @@ import (
 
 	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
 	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/colors"
+	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/display"
 	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/display/draw"
 	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/event"
 	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom"
@@ -45,6 +46,7 @@ type Tank struct {
 	halfBase  float64    // half of the "wheelbase" (front to back of tank body)
 	frontAxis geom.Point // absolute position of tank's front axis
 	backAxis  geom.Point // absolute position of tank's back axis
+	node      *display.Container
 
 	// friction float64
 	speed float64 // current total speed
@@ -64,9 +66,11 @@ func newTank(atlas *sdlkit.TextureAtlas, color Color) *Tank {
 	tnk := &Tank{
 		body:     body,
 		halfBase: (body.height / 2) * 0.7,
+		node:     display.NewContainer(),
 	}
 
 	tnk.Turret = NewTurretSmall(atlas, tnk)
+	tnk.node.AddChild(tnk.Turret.node)
 	tnk.SetHeading(0)
 	return tnk
 }
@@ -83,12 +87,14 @@ func (tnk *Tank) GetY() float64 { return tnk.body.shape.Y }
 
 func (tnk *Tank) SetX(x float64) {
 	tnk.body.shape.X = x
+	tnk.node.SetX(x)
 	tnk.frontAxis.X = x + (tnk.halfBase * tnk.heading[1])
 	tnk.backAxis.X = x - (tnk.halfBase * tnk.heading[1])
 }
 
 func (tnk *Tank) SetY(y float64) {
 	tnk.body.shape.Y = y
+	tnk.node.SetY(y)
 	tnk.frontAxis.Y = y + (tnk.halfBase * tnk.heading[2])
 	tnk.backAxis.Y = y - (tnk.halfBase * tnk.heading[2])
 }
@@ -103,6 +109,7 @@ func (tnk *Tank) SetHeading(heading float64) {
 	tnk.heading[0] = heading
 	tnk.heading[1] = math.Cos(heading)
 	tnk.heading[2] = math.Sin(heading)
+	tnk.node.SetRotation(heading)
 }
 
 func (tnk *Tank) SetTurretRotation(radians float64) {
@@ -112,9 +119,8 @@ func (tnk *Tank) SetTurretRotation(radians float64) {
 // TurretPosition is the absolute center point of the turret's dome on top of
 // the Tank.
 func (tnk *Tank) TurretPosition() *geom.Vector {
-	return tnk.Turret.offset.Clone().
-		Rotate(-tnk.heading[0]).
-		Add(tnk.GetX(), tnk.GetY())
+	pt := tnk.Turret.node.LocalToWorld(geom.Point{})
+	return &geom.Vector{X: pt.X, Y: pt.Y}
 }
 
 func (tnk *Tank) Update(dt float64) {
@@ -146,6 +152,7 @@ func (tnk *Tank) Update(dt float64) {
 	// update tank center point which is in the middle of the front and back axis
 	tnk.body.shape.X = (tnk.frontAxis.X + tnk.backAxis.X) / 2
 	tnk.body.shape.Y = (tnk.frontAxis.Y + tnk.backAxis.Y) / 2
+	tnk.node.SetPosition(tnk.body.shape.X, tnk.body.shape.Y)
 
 	if steer != 0 {
 		tnk.SetHeading(math.Atan2(tnk.frontAxis.Y-tnk.backAxis.Y, tnk.frontAxis.X-tnk.backAxis.X))
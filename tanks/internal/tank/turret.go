@@ -9,7 +9,6 @@ import (
 
 	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit"
 	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/display"
-	"github.com/roeldev/go-sdl2-experiments/pkg/sdlkit/geom"
 )
 
 var (
@@ -22,7 +21,7 @@ var (
 )
 
 type Turret struct {
-	offset       geom.Vector
+	node         *display.Container // position of the dome relative to the tank
 	color        Color
 	domeRadius   [2]int32
 	domeSprite   *display.Sprite
@@ -37,8 +36,11 @@ func NewTurretSmall(atlas *sdlkit.TextureAtlas, tank *Tank) Turret {
 	// barrelSprite.TranslateX = 13
 	// barrelSprite.Origin().Y = 2
 
+	node := display.NewContainer()
+	node.SetX(4)
+
 	return Turret{
-		offset:       geom.Vector{X: 4},
+		node:         node,
 		color:        tank.body.color,
 		domeRadius:   [2]int32{9, 7},
 		barrelSprite: barrelSprite,
@@ -16,7 +16,7 @@ import (
 	"github.com/roeldev/go-sdl2-experiments/tanks/internal"
 )
 
-//go:embed "assets"
+//go:embed assets tanks.tmx terrain.tsx
 var assets embed.FS
 
 func main() {